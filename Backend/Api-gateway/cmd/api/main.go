@@ -12,7 +12,8 @@ func main() {
 	
 	internal.InitRedis()
     consul.InitConsul()
-    app := fiber.New()
+    // Evidence uploads to escrow-service can be up to 10 MB.
+    app := fiber.New(fiber.Config{BodyLimit: 12 * 1024 * 1024})
     app.Use(middleware.CORSMiddleware())
     internal.SetupRoutes(app)
     app.Listen(":8080")
@@ -21,7 +21,7 @@ func CORSMiddleware() fiber.Handler {
 
         c.Set("Vary", "Origin")
         c.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-        c.Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-User-ID, ngrok-skip-browser-warning")
+        c.Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-User-ID, Idempotency-Key, ngrok-skip-browser-warning")
         c.Set("Access-Control-Allow-Credentials", "true")
 
         // For preflight requests, return immediately *after* setting headers
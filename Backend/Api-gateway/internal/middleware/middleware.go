@@ -75,6 +75,12 @@ func AuthMiddleware() fiber.Handler {
 		// Forward user/session info downstream
 		c.Request().Header.Set("X-User-ID", fmt.Sprintf("%d", resp.UserId))
 		c.Request().Header.Set("X-Session-ID", resp.SessionId)
+		// Always overwrite the role so a client cannot supply its own.
+		role := resp.Role
+		if role == "" {
+			role = "user"
+		}
+		c.Request().Header.Set("X-User-Role", role)
 
 		return c.Next()
 	}
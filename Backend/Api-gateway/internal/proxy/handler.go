@@ -99,7 +99,7 @@ func ProxyHandler(serviceName string) fiber.Handler {
 			c.Set("Access-Control-Allow-Origin", origin)
 			c.Set("Access-Control-Allow-Credentials", "true")
 			c.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-			c.Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-User-ID, ngrok-skip-browser-warning")
+			c.Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-User-ID, Idempotency-Key, ngrok-skip-browser-warning")
 			c.Set("Vary", "Origin")
 			log.Printf("Set CORS headers for origin: %s", origin)
 		} else {
@@ -51,6 +51,9 @@ func WebSocketProxy(serviceName string) func(*websocket.Conn) {
 		headers := http.Header{}
 		headers.Set("X-User-ID", userID)
 		headers.Set("X-Session-ID", sessionID)
+		if role, ok := clientConn.Locals("user_role").(string); ok {
+			headers.Set("X-User-Role", role)
+		}
 
 		log.Printf("WebSocketProxy: dialing backend ws://%s%s\n", addr, targetPath)
 
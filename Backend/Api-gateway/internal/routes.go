@@ -33,6 +33,7 @@ func SetupRoutes(app *fiber.App) {
 	    // Store values in locals for the WebSocket handler
 	         c.Locals("user_id", c.Get("X-User-ID"))
 	         c.Locals("session_id", c.Get("X-Session-ID"))
+	         c.Locals("user_role", c.Get("X-User-Role"))
 	         c.Locals("target_path", "/ws/"+c.Params("id"))
            return websocket.New(proxy.WebSocketProxy("chat-service"))(c)
           })
@@ -58,15 +59,45 @@ func SetupRoutes(app *fiber.App) {
 		authenticated.Use("/escrows", proxy.ProxyHandler("escrow-service"))
 		authenticated.Use("/escrows/:id",proxy.ProxyHandler("escrow-service"))
 		authenticated.Use("/escrows/my",proxy.ProxyHandler("escrow-service"))
+		authenticated.Use("/escrows/:id/history", proxy.ProxyHandler("escrow-service"))
 		authenticated.Use("/escrows/:id/accept", proxy.ProxyHandler("escrow-service"))
+		authenticated.Use("/escrows/:id/decline", proxy.ProxyHandler("escrow-service"))
 		authenticated.Use("/escrows/:id/confirm-receipt", proxy.ProxyHandler("escrow-service"))
+		authenticated.Use("/escrows/:id/deliverables/:deliverableId", proxy.ProxyHandler("escrow-service"))
+		authenticated.Use("/escrows/:id/deliveries", proxy.ProxyHandler("escrow-service"))
+		authenticated.Use("/escrows/:id/milestones/:milestoneId/confirm", proxy.ProxyHandler("escrow-service"))
 		authenticated.Use("/escrows/:id/refund", proxy.ProxyHandler("escrow-service"))
+		authenticated.Use("/escrows/:id/settlements", proxy.ProxyHandler("escrow-service"))
 		authenticated.Use("/escrows/:id/cancel", proxy.ProxyHandler("escrow-service"))
+		authenticated.Use("/escrows/:id/amendments", proxy.ProxyHandler("escrow-service"))
 		authenticated.Use("/escrows/dispute/:id",proxy.ProxyHandler("escrow-service"))
+		authenticated.Use("/escrows/:id/disputes", proxy.ProxyHandler("escrow-service"))
 		authenticated.Use("/escrows/contacts",proxy.ProxyHandler("escrow-service"))
+		authenticated.Use("/escrows/templates", proxy.ProxyHandler("escrow-service"))
+		authenticated.Use("/escrows/recurring", proxy.ProxyHandler("escrow-service"))
+		authenticated.Use("/escrows/invitations", proxy.ProxyHandler("escrow-service"))
+		authenticated.Use("/escrows/links", proxy.ProxyHandler("escrow-service"))
+
+		// Listing routes
+		authenticated.Use("/listings", proxy.ProxyHandler("listing-service"))
 
 		// Payment routes
 		authenticated.Use("/payments/initiate", proxy.ProxyHandler("payment-service"))
 		authenticated.Use("/payments/transactions",proxy.ProxyHandler("payment-service"))
+
+		// Arbitration routes (the services check the role). The AI
+		// recommendation is produced by chat-service, which holds the chat log.
+		authenticated.Post("/arbitration/:id/recommendation", func(c *fiber.Ctx) error {
+			c.Path("/decision/" + c.Params("id"))
+			return proxy.ProxyHandler("chat-service")(c)
+		})
+		authenticated.Use("/arbitration", proxy.ProxyHandler("escrow-service"))
+
+		// Admin routes (the services check the role)
+		authenticated.Use("/admin/users", proxy.ProxyHandler("user-service"))
+		authenticated.Use("/admin/escrows", proxy.ProxyHandler("escrow-service"))
+		authenticated.Use("/admin/fees", proxy.ProxyHandler("escrow-service"))
+		authenticated.Use("/admin/fx-rates", proxy.ProxyHandler("escrow-service"))
+		authenticated.Use("/admin/payments", proxy.ProxyHandler("payment-service"))
 	}
 }
@@ -15,6 +15,7 @@ func main() {
     db.DB.AutoMigrate(&model.Message{})
     consul.RegisterService("chat-service", "chat-service", 8085)
 	http.HandleFunc("/ws/", handlers.HandleWebSocket)
+	http.HandleFunc("/decision/", handlers.HandleDecision)
 
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
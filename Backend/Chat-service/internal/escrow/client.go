@@ -22,4 +22,22 @@ func NewEscrowServiceClient(addr string) (*EscrowServiceClient, error) {
 func (c *EscrowServiceClient) GetEscrow(id uint32) (*v1.EscrowResponse, error) {
     client := v1.NewEscrowServiceClient(c.conn)
     return client.GetEscrow(context.Background(), &v1.GetEscrowRequest{EscrowId: id})
+}
+
+func (c *EscrowServiceClient) ListEvidence(escrowID uint32) (*v1.ListEvidenceResponse, error) {
+    client := v1.NewEscrowServiceClient(c.conn)
+    return client.ListEvidence(context.Background(), &v1.ListEvidenceRequest{EscrowId: escrowID})
+}
+
+func (c *EscrowServiceClient) RecordRecommendation(escrowID uint32, decision, justification string) (*v1.RecordRecommendationResponse, error) {
+    client := v1.NewEscrowServiceClient(c.conn)
+    return client.RecordRecommendation(context.Background(), &v1.RecordRecommendationRequest{
+        EscrowId:      escrowID,
+        Decision:      decision,
+        Justification: justification,
+    })
+}
+
+func (c *EscrowServiceClient) Close() error {
+    return c.conn.Close()
 }
\ No newline at end of file
@@ -72,7 +72,7 @@ func HandleWebSocket(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    escrowDetails, err := getEscrowDetails(escrowID, uint(userID))
+    escrowDetails, err := getEscrowDetails(escrowID, uint(userID), r.Header.Get("X-User-Role"))
     if err != nil {
         http.Error(w, err.Error(), http.StatusForbidden)
         return
@@ -124,6 +124,8 @@ func HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 }
 
 // HandleDecision handles the request to get an AI decision on an escrow dispute.
+// The decision is stored with escrow-service as a recommendation that an
+// arbitrator then approves or overrides.
 func HandleDecision(w http.ResponseWriter, r *http.Request) {
     log.Printf("HandleDecision: incoming request reached")
 
@@ -132,6 +134,11 @@ func HandleDecision(w http.ResponseWriter, r *http.Request) {
         return
     }
 
+    if role := r.Header.Get("X-User-Role"); role != roleArbitrator && role != roleAdmin {
+        http.Error(w, "Only arbitrators can request a decision", http.StatusForbidden)
+        return
+    }
+
     // Extract escrowID from path: /decision/123
     escrowIDStr := strings.TrimPrefix(r.URL.Path, "/decision/")
     escrowID, err := strconv.ParseUint(escrowIDStr, 10, 64)
@@ -154,7 +161,8 @@ func HandleDecision(w http.ResponseWriter, r *http.Request) {
         })
     }
     
-    escrowDetails, err := getEscrowDetails(escrowID, 0) // UserID is not needed for this call
+    // The decision is made on the arbitrator's behalf, so it gets the same view.
+    escrowDetails, err := getEscrowDetails(escrowID, 0, roleArbitrator)
     if err != nil {
         log.Printf("Failed to get escrow details: %v", err)
         http.Error(w, "Failed to get escrow details", http.StatusInternalServerError)
@@ -170,16 +178,19 @@ func HandleDecision(w http.ResponseWriter, r *http.Request) {
     }
     defer client.Close()
 
+    conditionsJSON := disputeConditionsJSON(escrowID, escrowDetails)
+
     // Create the decision request
     req := &v1_ai.DecisionRequest{
         EscrowId:         strconv.FormatUint(escrowID, 10),
         Description:      escrowDetails.Conditions,
         Status:           escrowDetails.Status,
-        Amount:           escrowDetails.Amount,
+        AmountMinor:      escrowDetails.AmountMinor,
+        Currency:         escrowDetails.Currency,
         BuyerId:          strconv.FormatUint(uint64(escrowDetails.BuyerId), 10),
         SellerId:         strconv.FormatUint(uint64(escrowDetails.SellerId), 10),
         Chat:             chatLog,
-        DisputeConditionsJson: "{}", // Add empty JSON object as placeholder
+        DisputeConditionsJson: conditionsJSON,
     }
 
     ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -193,16 +204,108 @@ func HandleDecision(w http.ResponseWriter, r *http.Request) {
         return
     }
 
+    recorded := recordRecommendation(escrowID, resp)
+
     // Respond with the AI's decision
     w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(map[string]string{
+    json.NewEncoder(w).Encode(map[string]any{
         "decision":     resp.Decision,
         "justification": resp.Justification,
+        "recorded":     recorded,
     })
 }
 
-// getEscrowDetails checks access and returns escrow details.
-func getEscrowDetails(escrowID uint64, userID uint) (*v1_escrow.EscrowResponse, error) {
+// recordRecommendation hands the AI decision to escrow-service, where it is
+// shown in the arbitration queue. It reports whether it was stored.
+func recordRecommendation(escrowID uint64, decision *v1_ai.DecisionResponse) bool {
+    escrowClient, err := escrow.NewEscrowServiceClient("escrow-service:50052")
+    if err != nil {
+        log.Printf("Failed to connect to escrow-service: %v", err)
+        return false
+    }
+    defer escrowClient.Close()
+
+    resp, err := escrowClient.RecordRecommendation(uint32(escrowID), decision.Decision, decision.Justification)
+    if err != nil {
+        log.Printf("Failed to record recommendation for escrow %d: %v", escrowID, err)
+        return false
+    }
+    if !resp.Success {
+        log.Printf("Recommendation for escrow %d not recorded: %s", escrowID, resp.Error)
+        return false
+    }
+    return true
+}
+
+// disputeConditionsJSON builds DecisionRequest.dispute_conditions_json: the
+// escrow's structured conditions document with the metadata of any dispute
+// evidence added under "evidence". It is "{}" when neither exists.
+func disputeConditionsJSON(escrowID uint64, escrowDetails *v1_escrow.EscrowResponse) string {
+    doc := map[string]any{}
+    if escrowDetails.ConditionsJson != "" {
+        if err := json.Unmarshal([]byte(escrowDetails.ConditionsJson), &doc); err != nil {
+            log.Printf("Invalid conditions document for escrow %d: %v", escrowID, err)
+            doc = map[string]any{}
+        }
+    }
+
+    escrowClient, err := escrow.NewEscrowServiceClient("escrow-service:50052")
+    if err != nil {
+        log.Printf("Failed to connect to escrow-service: %v", err)
+    } else if resp, err := escrowClient.ListEvidence(uint32(escrowID)); err != nil {
+        log.Printf("Failed to list evidence for escrow %d: %v", escrowID, err)
+    } else if len(resp.Evidence) > 0 {
+        evidence := make([]map[string]any, 0, len(resp.Evidence))
+        for _, e := range resp.Evidence {
+            evidence = append(evidence, map[string]any{
+                "id":           e.Id,
+                "dispute_id":   e.DisputeId,
+                "uploaded_by":  e.UploadedBy,
+                "role":         e.UploaderRole,
+                "file_name":    e.FileName,
+                "content_type": e.ContentType,
+                "size":         e.Size,
+                "sha256":       e.Sha256,
+                "description":  e.Description,
+                "uploaded_at":  time.Unix(e.UploadedAt, 0).UTC().Format(time.RFC3339),
+            })
+        }
+        doc["evidence"] = evidence
+    }
+
+    b, err := json.Marshal(doc)
+    if err != nil {
+        return "{}"
+    }
+    return string(b)
+}
+
+// Platform roles forwarded by the API gateway in X-User-Role.
+const (
+    roleSupport    = "support"
+    roleArbitrator = "arbitrator"
+    roleAdmin      = "admin"
+)
+
+// canJoin reports whether a user may enter the chat room of an escrow. The
+// buyer and seller always can; support staff and arbitrators only once it is
+// disputed; admins always.
+func canJoin(escrow *v1_escrow.EscrowResponse, userID uint, role string) bool {
+    if escrow.BuyerId == uint32(userID) || escrow.SellerId == uint32(userID) {
+        return true
+    }
+    switch role {
+    case roleAdmin:
+        return true
+    case roleSupport, roleArbitrator:
+        return strings.EqualFold(escrow.Status, "disputed")
+    }
+    return false
+}
+
+// getEscrowDetails checks access and returns escrow details. Rooms of
+// inactive escrows stay open to admins only.
+func getEscrowDetails(escrowID uint64, userID uint, role string) (*v1_escrow.EscrowResponse, error) {
     escrowClient, err := escrow.NewEscrowServiceClient("escrow-service:50052")
     if err != nil {
         log.Printf("Failed to connect to escrow-service: %v", err)
@@ -215,7 +318,7 @@ func getEscrowDetails(escrowID uint64, userID uint) (*v1_escrow.EscrowResponse,
         return nil, http.ErrAbortHandler
     }
 
-    if !resp.Active || (resp.Status != "disputed" && resp.BuyerId != uint32(userID) && resp.SellerId != uint32(userID)) {
+    if !canJoin(resp, userID, role) || (!resp.Active && role != roleAdmin) {
         return nil, http.ErrAbortHandler
     }
 
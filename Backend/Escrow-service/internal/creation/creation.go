@@ -0,0 +1,302 @@
+// Package creation creates escrows. It validates the terms and both
+// parties, applies the active fee schedule and stores the escrow together
+// with its escrow.create event. Escrows sent to CreateEscrow, made from a
+// template or spawned by a recurring schedule all go through Create, so
+// they are checked the same way.
+package creation
+
+import (
+	"errors"
+	"escrow_service/internal/auth"
+	"escrow_service/internal/deadline"
+	"escrow_service/internal/fee"
+	"escrow_service/internal/model"
+	"escrow_service/internal/rabbitmq"
+	"escrow_service/internal/statemachine"
+	"fmt"
+	"message_broker/rabbitmq/events"
+	"net/http"
+	"shared/money"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/gorm"
+)
+
+// Error is an escrow that could not be created, with the HTTP status and
+// message to answer the request with. Err is the underlying failure of an
+// internal error.
+type Error struct {
+	Status  int
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Rejected reports whether the escrow itself was refused, as opposed to
+// failing for a reason that may go away on retry.
+func (e *Error) Rejected() bool {
+	return e.Status < http.StatusInternalServerError
+}
+
+func invalid(message string) error {
+	return &Error{Status: http.StatusBadRequest, Message: message}
+}
+
+func failed(message string, err error) error {
+	return &Error{Status: http.StatusInternalServerError, Message: message, Err: err}
+}
+
+// Request is a new escrow and where it comes from.
+type Request struct {
+	Escrow *model.Escrow
+	// CallerID is the user creating the escrow; they take the side named
+	// by Escrow.InitiatedBy.
+	CallerID uint
+	Source   model.EventSource
+	// Template is the template the escrow is made from, if any. Its usage
+	// is counted with the escrow.
+	Template *model.EscrowTemplate
+	// Claim, if set, runs in the transaction that stores the escrow. An
+	// error undoes the escrow.
+	Claim func(tx *gorm.DB, escrow *model.Escrow) error
+}
+
+// Create validates and stores the escrow and queues escrow.create, which
+// creates it on chain. It returns the fees the escrow was quoted.
+func Create(db *gorm.DB, r Request) (fee.Quote, error) {
+	escrow := r.Escrow
+
+	// The caller takes the initiator's side of the deal; a seller-initiated
+	// escrow names its buyer, who has to accept it before funding.
+	switch escrow.InitiatedBy {
+	case "", string(statemachine.Buyer):
+		escrow.InitiatedBy = string(statemachine.Buyer)
+		escrow.BuyerID = r.CallerID
+	case string(statemachine.Seller):
+		if escrow.BuyerID == 0 {
+			return fee.Quote{}, invalid("Buyer ID is required")
+		}
+		escrow.SellerID = r.CallerID
+	default:
+		return fee.Quote{}, invalid("initiated_by must be buyer or seller")
+	}
+	escrow.AwaitingBuyer = escrow.InitiatedBy == string(statemachine.Seller)
+
+	if escrow.SellerID == 0 {
+		return fee.Quote{}, invalid("Seller ID is required")
+	}
+	if err := ValidateTerms(escrow, time.Now()); err != nil {
+		return fee.Quote{}, invalid(err.Error())
+	}
+	if uint32(escrow.BuyerID) == uint32(escrow.SellerID) {
+		return fee.Quote{}, invalid("Buyer and seller cannot be the same user")
+	}
+
+	buyerAddr, sellerAddr, err := checkParties(escrow)
+	if err != nil {
+		return fee.Quote{}, err
+	}
+
+	// ✅ Set escrow fields
+	escrow.Status = model.Pending
+
+	quote, err := fee.QuoteFor(db, escrow.Amount, escrow.Currency)
+	if err != nil {
+		return fee.Quote{}, failed("Failed to compute platform fee", err)
+	}
+	fee.Apply(escrow, quote)
+
+	// ✅ Save in DB
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(escrow).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&model.EscrowEvent{
+			EscrowID:  escrow.ID,
+			Kind:      model.EventCreated,
+			ActorID:   r.CallerID,
+			ActorRole: escrow.InitiatedBy,
+			ToStatus:  escrow.Status,
+			Source:    r.Source,
+		}).Error; err != nil {
+			return err
+		}
+		if r.Template != nil {
+			if err := tx.Model(r.Template).Updates(map[string]any{
+				"usage_count":  gorm.Expr("usage_count + 1"),
+				"last_used_at": time.Now(),
+			}).Error; err != nil {
+				return err
+			}
+		}
+		if r.Claim != nil {
+			if err := r.Claim(tx, escrow); err != nil {
+				return err
+			}
+		}
+		// ✅ Publish event with the escrow: it is what creates it on chain
+		event := &events.CreateEscrowEvent{
+			ID:          uint64(escrow.ID),
+			BuyerID:     uint32(escrow.BuyerID),
+			SellerID:    uint32(escrow.SellerID),
+			Amount:      escrow.Amount,
+			Currency:    string(escrow.Currency),
+			BuyerAddr:   buyerAddr.Hex(),
+			SellerAddr:  sellerAddr.Hex(),
+			InitiatedBy: escrow.InitiatedBy,
+		}
+		if escrow.RecurringEscrowID != nil {
+			event.RecurringID = uint64(*escrow.RecurringEscrowID)
+			event.Cycle = escrow.RecurringCycle
+		}
+		return rabbitmq.NewProducer(tx).PublishCreateEscrow(event)
+	})
+	if err != nil {
+		return fee.Quote{}, failed("Failed to create escrow", err)
+	}
+	return quote, nil
+}
+
+// checkParties makes sure both parties can take part in an escrow: their
+// accounts are activated and they added bank details and a wallet. It
+// returns their wallet addresses.
+func checkParties(escrow *model.Escrow) (buyerAddr, sellerAddr common.Address, err error) {
+	userServiceClient, err := auth.NewUserServiceClient("user-service:50051")
+	if err != nil {
+		return buyerAddr, sellerAddr, failed("Failed to connect to user service", err)
+	}
+	defer userServiceClient.Close()
+
+	if buyerAddr, err = checkParty(userServiceClient, escrow.BuyerID, "Buyer"); err != nil {
+		return buyerAddr, sellerAddr, err
+	}
+	sellerAddr, err = checkParty(userServiceClient, escrow.SellerID, "Seller")
+	return buyerAddr, sellerAddr, err
+}
+
+// CheckBuyer makes sure the user can fund escrows, before they invite a
+// seller who is not registered yet.
+func CheckBuyer(buyerID uint) error {
+	return checkUser(buyerID, "Buyer")
+}
+
+// CheckSeller makes sure the user can be paid out, before they offer
+// escrows to buyers they do not know yet.
+func CheckSeller(sellerID uint) error {
+	return checkUser(sellerID, "Seller")
+}
+
+func checkUser(userID uint, party string) error {
+	userServiceClient, err := auth.NewUserServiceClient("user-service:50051")
+	if err != nil {
+		return failed("Failed to connect to user service", err)
+	}
+	defer userServiceClient.Close()
+
+	_, err = checkParty(userServiceClient, userID, party)
+	return err
+}
+
+// checkParty checks one party of an escrow, named party in the error
+// messages, and returns their wallet address.
+func checkParty(client *auth.UserServiceClient, userID uint, party string) (common.Address, error) {
+	user, err := client.GetUser(uint32(userID))
+	if err != nil || user == nil || !user.Activated {
+		return common.Address{}, &Error{Status: http.StatusForbidden, Message: party + " account is not activated"}
+	}
+
+	// ✅ Validate bank details
+	if user.AccountName == nil || user.AccountName.Value == "" ||
+		user.AccountNumber == nil || user.AccountNumber.Value == "" ||
+		user.BankCode == nil || user.BankCode.Value == 0 {
+		return common.Address{}, invalid(party + " has not added bank account details")
+	}
+
+	// ✅ Validate wallet
+	if user.WalletAddress == nil || user.WalletAddress.Value == "" {
+		return common.Address{}, invalid(party + " has not created a wallet")
+	}
+	return common.HexToAddress(user.WalletAddress.Value), nil
+}
+
+// ValidateTerms checks the terms of a new escrow and fills in the default
+// deadlines. Templates are checked with it as well.
+func ValidateTerms(escrow *model.Escrow, now time.Time) error {
+	if len(escrow.Milestones) > 0 {
+		if err := prepareMilestones(escrow, now); err != nil {
+			return err
+		}
+	}
+	currency, err := model.ParseCurrency(string(escrow.Currency))
+	if err != nil {
+		return err
+	}
+	escrow.Currency = currency
+	if escrow.Amount <= 0 {
+		return errors.New("Amount must be greater than zero")
+	}
+	if escrow.FundingDeadline == nil {
+		fundBy := now.Add(deadline.FundingWindow())
+		escrow.FundingDeadline = &fundBy
+	} else if !escrow.FundingDeadline.After(now) {
+		return errors.New("Funding deadline must be in the future")
+	}
+	if escrow.ConditionsDoc != nil {
+		if err := escrow.ConditionsDoc.Prepare(now); err != nil {
+			return err
+		}
+	}
+	if escrow.InspectionPeriodHours < 0 {
+		return errors.New("Inspection period must be greater than zero")
+	}
+	if escrow.InspectionPeriodHours == 0 {
+		escrow.InspectionPeriodHours = int(deadline.InspectionWindow() / time.Hour)
+	}
+	escrow.InspectionDeadline = nil
+	return nil
+}
+
+// prepareMilestones validates the milestones submitted with a new escrow,
+// resets any client-supplied bookkeeping fields and makes sure the escrow
+// amount matches their sum. An escrow amount of zero is filled in.
+func prepareMilestones(escrow *model.Escrow, now time.Time) error {
+	var total money.Amount
+	for i := range escrow.Milestones {
+		m := &escrow.Milestones[i]
+		if strings.TrimSpace(m.Description) == "" {
+			return fmt.Errorf("milestone %d: description is required", i+1)
+		}
+		if m.Amount <= 0 {
+			return fmt.Errorf("milestone %d: amount must be greater than zero", i+1)
+		}
+		if m.DueDate != nil && !m.DueDate.After(now) {
+			return fmt.Errorf("milestone %d: due date must be in the future", i+1)
+		}
+
+		m.Model = gorm.Model{}
+		m.EscrowID = 0
+		m.Sequence = i + 1
+		m.Status = model.MilestonePending
+		m.TransferRef = nil
+		total += m.Amount
+	}
+
+	if escrow.Amount == 0 {
+		escrow.Amount = total
+	} else if escrow.Amount != total {
+		return fmt.Errorf("milestone amounts must add up to the escrow amount")
+	}
+	return nil
+}
@@ -45,3 +45,61 @@ func ConnectDB() {
     fmt.Println("Connected to Escrow DB")
 }
 
+// moneyColumns held amounts as double precision before amounts were made
+// exact.
+var moneyColumns = []struct{ table, column string }{
+    {"escrows", "amount"},
+    {"escrows", "buyer_fee"},
+    {"escrows", "seller_fee"},
+    {"milestones", "amount"},
+    {"settlements", "seller_amount"},
+    {"settlements", "seller_fee"},
+    {"settlements", "buyer_amount"},
+    {"amendments", "amount"},
+    {"amendments", "previous_amount"},
+    {"fee_schedules", "fixed_amount"},
+    {"fee_schedules", "min_fee"},
+    {"fee_schedules", "max_fee"},
+    {"fee_ledger_entries", "amount"},
+}
+
+// MigrateMoneyColumns converts the amount columns that are still floating
+// point to numeric(16,2), rounding existing rows to the cent. It runs before
+// AutoMigrate, which would change the type without rounding.
+func MigrateMoneyColumns(db *gorm.DB) error {
+    return db.Transaction(func(tx *gorm.DB) error {
+        for _, c := range moneyColumns {
+            var dataType string
+            err := tx.Raw(`SELECT data_type FROM information_schema.columns
+                WHERE table_schema = current_schema() AND table_name = ? AND column_name = ?`,
+                c.table, c.column).Scan(&dataType).Error
+            if err != nil {
+                return fmt.Errorf("failed to inspect %s.%s: %v", c.table, c.column, err)
+            }
+            if dataType != "double precision" && dataType != "real" {
+                continue
+            }
+            stmt := fmt.Sprintf(`ALTER TABLE %s ALTER COLUMN %s TYPE numeric(16,2) USING round(%s::numeric, 2)`,
+                c.table, c.column, c.column)
+            if err := tx.Exec(stmt).Error; err != nil {
+                return fmt.Errorf("failed to migrate %s.%s: %v", c.table, c.column, err)
+            }
+        }
+        return nil
+    })
+}
+
+// CreateListingIndexes adds the composite indexes escrow listings page
+// through. AutoMigrate cannot declare them because created_at comes from
+// gorm.Model.
+func CreateListingIndexes(db *gorm.DB) error {
+    for _, stmt := range []string{
+        `CREATE INDEX IF NOT EXISTS idx_escrows_buyer_created ON escrows (buyer_id, created_at DESC, id DESC)`,
+        `CREATE INDEX IF NOT EXISTS idx_escrows_seller_created ON escrows (seller_id, created_at DESC, id DESC)`,
+    } {
+        if err := db.Exec(stmt).Error; err != nil {
+            return fmt.Errorf("failed to create listing index: %v", err)
+        }
+    }
+    return nil
+}
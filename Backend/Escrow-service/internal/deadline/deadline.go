@@ -0,0 +1,66 @@
+package deadline
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+const (
+	defaultFundingWindow    = 72 * time.Hour
+	defaultInspectionWindow = 7 * 24 * time.Hour
+	defaultCheckInterval    = time.Minute
+	defaultInvitationWindow = 7 * 24 * time.Hour
+	defaultSettlementRetry  = 15 * time.Minute
+	defaultTransitWindow    = 14 * 24 * time.Hour
+)
+
+// FundingWindow is how long a new escrow may stay unfunded when the buyer
+// did not set a funding deadline (ESCROW_FUNDING_WINDOW).
+func FundingWindow() time.Duration {
+	return fromEnv("ESCROW_FUNDING_WINDOW", defaultFundingWindow)
+}
+
+// InspectionWindow is the inspection period used when the escrow does not
+// set one (ESCROW_INSPECTION_WINDOW).
+func InspectionWindow() time.Duration {
+	return fromEnv("ESCROW_INSPECTION_WINDOW", defaultInspectionWindow)
+}
+
+// TransitWindow is how long shipped goods may stay in transit before the
+// escrow is released anyway, unless the conditions set a later delivery
+// deadline (ESCROW_TRANSIT_WINDOW).
+func TransitWindow() time.Duration {
+	return fromEnv("ESCROW_TRANSIT_WINDOW", defaultTransitWindow)
+}
+
+// CheckInterval is how often the scheduler looks for expired deadlines
+// (ESCROW_DEADLINE_CHECK_INTERVAL).
+func CheckInterval() time.Duration {
+	return fromEnv("ESCROW_DEADLINE_CHECK_INTERVAL", defaultCheckInterval)
+}
+
+// InvitationWindow is how long an invited seller has to sign up and claim
+// the escrow (ESCROW_INVITATION_WINDOW).
+func InvitationWindow() time.Duration {
+	return fromEnv("ESCROW_INVITATION_WINDOW", defaultInvitationWindow)
+}
+
+// SettlementRetryInterval is how long the scheduler waits before retrying
+// the transfers of a failed settlement (ESCROW_SETTLEMENT_RETRY_INTERVAL).
+func SettlementRetryInterval() time.Duration {
+	return fromEnv("ESCROW_SETTLEMENT_RETRY_INTERVAL", defaultSettlementRetry)
+}
+
+func fromEnv(key string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid %s %q, using %s", key, raw, fallback)
+		return fallback
+	}
+	return d
+}
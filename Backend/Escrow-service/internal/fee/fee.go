@@ -0,0 +1,182 @@
+package fee
+
+import (
+	"errors"
+	"escrow_service/internal/model"
+	"fmt"
+	"shared/money"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Quote is the platform fee on an escrow amount, split by who pays it.
+type Quote struct {
+	ScheduleID *uint        `json:"schedule_id,omitempty"`
+	Amount     money.Amount `json:"amount"`
+	Fee        money.Amount `json:"fee"`
+	BuyerFee   money.Amount `json:"buyer_fee"`
+	SellerFee  money.Amount `json:"seller_fee"`
+	// BuyerPays is what the Chapa checkout charges the buyer.
+	BuyerPays money.Amount `json:"buyer_pays"`
+	// SellerReceives is what the seller is paid once the escrow is released.
+	SellerReceives money.Amount `json:"seller_receives"`
+}
+
+// Compute applies schedule s to amount. A nil schedule charges nothing.
+func Compute(s *model.FeeSchedule, amount money.Amount) Quote {
+	q := Quote{Amount: amount, BuyerPays: amount, SellerReceives: amount}
+	if s == nil {
+		return q
+	}
+	id := s.ID
+	q.ScheduleID = &id
+
+	fee := amount.Percent(s.Percent) + s.FixedAmount
+	if fee < s.MinFee {
+		fee = s.MinFee
+	}
+	if s.MaxFee > 0 && fee > s.MaxFee {
+		fee = s.MaxFee
+	}
+
+	switch s.Payer {
+	case model.FeePayerBuyer:
+		q.BuyerFee = fee
+	case model.FeePayerSeller:
+		q.SellerFee = fee
+	case model.FeePayerSplit:
+		q.BuyerFee = fee.MulDiv(1, 2)
+		q.SellerFee = fee - q.BuyerFee
+	}
+	// The seller's fee can never exceed what they are paid.
+	if q.SellerFee > amount {
+		q.SellerFee = amount
+	}
+
+	q.Fee = q.BuyerFee + q.SellerFee
+	q.BuyerPays = amount + q.BuyerFee
+	q.SellerReceives = amount - q.SellerFee
+	return q
+}
+
+// Validate checks a schedule before it is stored and normalizes its
+// currency.
+func Validate(s *model.FeeSchedule) error {
+	if s.Name == "" {
+		return errors.New("name is required")
+	}
+	currency, err := model.ParseCurrency(string(s.Currency))
+	if err != nil {
+		return err
+	}
+	s.Currency = currency
+	if s.Percent < 0 || s.Percent >= 100 {
+		return errors.New("percent must be between 0 and 100")
+	}
+	if s.FixedAmount < 0 || s.MinFee < 0 || s.MaxFee < 0 {
+		return errors.New("fee amounts must not be negative")
+	}
+	if s.MaxFee > 0 && s.MinFee > s.MaxFee {
+		return errors.New("min_fee must not exceed max_fee")
+	}
+	switch s.Payer {
+	case model.FeePayerBuyer, model.FeePayerSeller, model.FeePayerSplit:
+	default:
+		return errors.New("payer must be buyer, seller or split")
+	}
+	return nil
+}
+
+// ActiveSchedule returns the schedule new escrows in currency are charged
+// with, or nil when the platform currently charges no fee on them.
+func ActiveSchedule(db *gorm.DB, currency model.Currency) (*model.FeeSchedule, error) {
+	var s model.FeeSchedule
+	err := db.Where("active = ? AND currency = ?", true, currency).Order("updated_at DESC").First(&s).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// QuoteFor computes the fees of a new escrow of amount in currency.
+func QuoteFor(db *gorm.DB, amount money.Amount, currency model.Currency) (Quote, error) {
+	s, err := ActiveSchedule(db, currency)
+	if err != nil {
+		return Quote{}, fmt.Errorf("failed to load fee schedule: %v", err)
+	}
+	return Compute(s, amount), nil
+}
+
+// Requote recomputes the fees of an existing escrow for a new amount with
+// the schedule the escrow was created under, even if it was deleted since.
+func Requote(db *gorm.DB, escrow *model.Escrow, amount money.Amount) (Quote, error) {
+	if escrow.FeeScheduleID == nil {
+		return Compute(nil, amount), nil
+	}
+	var s model.FeeSchedule
+	if err := db.Unscoped().First(&s, *escrow.FeeScheduleID).Error; err != nil {
+		return Quote{}, fmt.Errorf("failed to load fee schedule: %v", err)
+	}
+	return Compute(&s, amount), nil
+}
+
+// Apply stores the quote's fees on the escrow.
+func Apply(escrow *model.Escrow, q Quote) {
+	escrow.FeeScheduleID = q.ScheduleID
+	escrow.BuyerFee = q.BuyerFee
+	escrow.SellerFee = q.SellerFee
+}
+
+// Charged sums the ledger entries of one party's fee on an escrow.
+func Charged(db *gorm.DB, escrowID uint, payer model.FeePayer) (money.Amount, error) {
+	var total money.Amount
+	err := db.Model(&model.FeeLedgerEntry{}).
+		Where("escrow_id = ? AND payer = ?", escrowID, payer).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// SellerShare returns the part of the seller's fee to deduct from a payout
+// of gross to the seller. Partial payouts (milestones, settlements) are
+// charged pro rata; the final payout takes whatever is still owed.
+func SellerShare(db *gorm.DB, escrow *model.Escrow, gross money.Amount, final bool) (money.Amount, error) {
+	if escrow.SellerFee <= 0 || escrow.Amount <= 0 || gross <= 0 {
+		return 0, nil
+	}
+	charged, err := Charged(db, escrow.ID, model.FeePayerSeller)
+	if err != nil {
+		return 0, err
+	}
+	remaining := escrow.SellerFee - charged
+	if remaining <= 0 {
+		return 0, nil
+	}
+
+	share := remaining
+	if !final {
+		share = min(escrow.SellerFee.MulDiv(gross.Minor(), escrow.Amount.Minor()), remaining)
+	}
+	return min(share, gross), nil
+}
+
+// Record adds a ledger entry. Recording the same reference twice is a
+// no-op, so redelivered events do not charge a fee again.
+func Record(db *gorm.DB, escrow *model.Escrow, payer model.FeePayer, kind model.FeeEntryKind, amount money.Amount, reference string) error {
+	if amount == 0 {
+		return nil
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.FeeLedgerEntry{
+		EscrowID:   escrow.ID,
+		ScheduleID: escrow.FeeScheduleID,
+		Payer:      payer,
+		Kind:       kind,
+		Amount:     amount,
+		Currency:   escrow.Currency,
+		Reference:  reference,
+	}).Error
+}
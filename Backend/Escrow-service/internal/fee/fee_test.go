@@ -0,0 +1,201 @@
+package fee
+
+import (
+	"escrow_service/internal/model"
+	"fmt"
+	"shared/money"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name      string
+		schedule  *model.FeeSchedule
+		amount    money.Amount
+		buyerFee  money.Amount
+		sellerFee money.Amount
+	}{
+		{"no schedule", nil, 100000, 0, 0},
+		{
+			"percentage paid by the seller",
+			&model.FeeSchedule{Percent: 2.5, Payer: model.FeePayerSeller},
+			100000, 0, 2500,
+		},
+		{
+			"percentage paid by the buyer",
+			&model.FeeSchedule{Percent: 2.5, Payer: model.FeePayerBuyer},
+			100000, 2500, 0,
+		},
+		{
+			"percentage plus fixed amount",
+			&model.FeeSchedule{Percent: 1, FixedAmount: 500, Payer: model.FeePayerSeller},
+			100000, 0, 1500,
+		},
+		{
+			// 2.5% of 12.34 is 0.3085: rounded to the nearest minor unit.
+			"rounds down below half a minor unit",
+			&model.FeeSchedule{Percent: 2.5, Payer: model.FeePayerSeller},
+			1234, 0, 31,
+		},
+		{
+			// 2.5% of 0.30 is exactly 0.0075: rounded away from zero.
+			"rounds half a minor unit up",
+			&model.FeeSchedule{Percent: 2.5, Payer: model.FeePayerSeller},
+			30, 0, 1,
+		},
+		{
+			"clamped to the minimum",
+			&model.FeeSchedule{Percent: 1, MinFee: 1000, Payer: model.FeePayerSeller},
+			50000, 0, 1000,
+		},
+		{
+			"clamped to the maximum",
+			&model.FeeSchedule{Percent: 5, MaxFee: 20000, Payer: model.FeePayerSeller},
+			1000000, 0, 20000,
+		},
+		{
+			"zero maximum is uncapped",
+			&model.FeeSchedule{Percent: 5, Payer: model.FeePayerSeller},
+			1000000, 0, 50000,
+		},
+		{
+			"split evenly",
+			&model.FeeSchedule{Percent: 2, Payer: model.FeePayerSplit},
+			100000, 1000, 1000,
+		},
+		{
+			// A fee of 0.03 cannot be halved: the buyer's half is rounded
+			// away from zero and the seller pays the rest.
+			"split of an odd minor unit",
+			&model.FeeSchedule{Percent: 3, Payer: model.FeePayerSplit},
+			100, 2, 1,
+		},
+		{
+			"split of a single minor unit",
+			&model.FeeSchedule{FixedAmount: 1, Payer: model.FeePayerSplit},
+			100000, 1, 0,
+		},
+		{
+			"seller fee capped at the amount",
+			&model.FeeSchedule{MinFee: 5000, Payer: model.FeePayerSeller},
+			3000, 0, 3000,
+		},
+		{
+			"buyer fee is not capped at the amount",
+			&model.FeeSchedule{MinFee: 5000, Payer: model.FeePayerBuyer},
+			3000, 5000, 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := Compute(tt.schedule, tt.amount)
+			if q.BuyerFee != tt.buyerFee || q.SellerFee != tt.sellerFee {
+				t.Fatalf("fees = buyer %s, seller %s; want buyer %s, seller %s", q.BuyerFee, q.SellerFee, tt.buyerFee, tt.sellerFee)
+			}
+			if q.Fee != q.BuyerFee+q.SellerFee {
+				t.Errorf("Fee = %s, want %s", q.Fee, q.BuyerFee+q.SellerFee)
+			}
+			if q.BuyerPays != tt.amount+tt.buyerFee {
+				t.Errorf("BuyerPays = %s, want %s", q.BuyerPays, tt.amount+tt.buyerFee)
+			}
+			if q.SellerReceives != tt.amount-tt.sellerFee {
+				t.Errorf("SellerReceives = %s, want %s", q.SellerReceives, tt.amount-tt.sellerFee)
+			}
+			if (q.ScheduleID != nil) != (tt.schedule != nil) {
+				t.Errorf("ScheduleID = %v, want it set only with a schedule", q.ScheduleID)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() model.FeeSchedule {
+		return model.FeeSchedule{Name: "standard", Currency: "etb", Percent: 2.5, MinFee: 100, MaxFee: 10000, Payer: model.FeePayerSplit}
+	}
+	tests := []struct {
+		name    string
+		change  func(s *model.FeeSchedule)
+		wantErr bool
+	}{
+		{"valid", func(s *model.FeeSchedule) {}, false},
+		{"missing name", func(s *model.FeeSchedule) { s.Name = "" }, true},
+		{"unknown currency", func(s *model.FeeSchedule) { s.Currency = "XYZ" }, true},
+		{"negative percent", func(s *model.FeeSchedule) { s.Percent = -1 }, true},
+		{"percent of 100", func(s *model.FeeSchedule) { s.Percent = 100 }, true},
+		{"negative fixed amount", func(s *model.FeeSchedule) { s.FixedAmount = -1 }, true},
+		{"minimum above maximum", func(s *model.FeeSchedule) { s.MinFee = 20000 }, true},
+		{"minimum without maximum", func(s *model.FeeSchedule) { s.MinFee, s.MaxFee = 20000, 0 }, false},
+		{"unknown payer", func(s *model.FeeSchedule) { s.Payer = "platform" }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid()
+			tt.change(&s)
+			err := Validate(&s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && s.Currency != "ETB" {
+				t.Errorf("Currency = %q, want it normalized to ETB", s.Currency)
+			}
+		})
+	}
+}
+
+func TestSellerShare(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&model.FeeLedgerEntry{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	// A fee of 1.00 on 3.00, paid out in three milestones of 1.00.
+	escrow := &model.Escrow{Model: gorm.Model{ID: 1}, Amount: 300, SellerFee: 100, Currency: "ETB"}
+	payouts := []struct {
+		gross money.Amount
+		final bool
+		want  money.Amount
+	}{
+		// A third of 1.00 is 0.333...: each partial payout is rounded.
+		{100, false, 33},
+		{100, false, 33},
+		// The last payout takes the remainder, so nothing is lost to
+		// rounding.
+		{100, true, 34},
+		// Once the fee is fully charged nothing more is deducted.
+		{100, true, 0},
+	}
+	for i, p := range payouts {
+		got, err := SellerShare(db, escrow, p.gross, p.final)
+		if err != nil {
+			t.Fatalf("payout %d: SellerShare() = %v", i+1, err)
+		}
+		if got != p.want {
+			t.Fatalf("payout %d: SellerShare() = %s, want %s", i+1, got, p.want)
+		}
+		if err := Record(db, escrow, model.FeePayerSeller, model.FeeCharged, got, fmt.Sprintf("payout-%d", i+1)); err != nil {
+			t.Fatalf("payout %d: Record() = %v", i+1, err)
+		}
+	}
+
+	// A redelivered event records the same reference again.
+	if err := Record(db, escrow, model.FeePayerSeller, model.FeeCharged, 34, "payout-3"); err != nil {
+		t.Fatalf("Record() again = %v", err)
+	}
+
+	charged, err := Charged(db, escrow.ID, model.FeePayerSeller)
+	if err != nil {
+		t.Fatalf("Charged() = %v", err)
+	}
+	if charged != escrow.SellerFee {
+		t.Errorf("Charged() = %s, want %s", charged, escrow.SellerFee)
+	}
+}
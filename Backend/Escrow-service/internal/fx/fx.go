@@ -0,0 +1,96 @@
+package fx
+
+import (
+	"errors"
+	"escrow_service/internal/model"
+	"fmt"
+	"shared/money"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrNoRate is returned when neither a pair nor its inverse is in the
+// table.
+var ErrNoRate = errors.New("no exchange rate")
+
+// ErrInvalidRate is returned by Set for a pair or rate that cannot be stored.
+var ErrInvalidRate = errors.New("invalid exchange rate")
+
+type pair struct {
+	base, quote model.Currency
+}
+
+// Table is a snapshot of the FX rate table.
+type Table map[pair]float64
+
+// Load reads every rate.
+func Load(db *gorm.DB) (Table, error) {
+	var rates []model.FxRate
+	if err := db.Find(&rates).Error; err != nil {
+		return nil, err
+	}
+	t := make(Table, len(rates))
+	for _, r := range rates {
+		t[pair{r.Base, r.Quote}] = r.Rate
+	}
+	return t, nil
+}
+
+// Rate returns how many units of to one unit of from is worth, using the
+// inverse pair when only that one is maintained.
+func (t Table) Rate(from, to model.Currency) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	if r, ok := t[pair{from, to}]; ok {
+		return r, nil
+	}
+	if r, ok := t[pair{to, from}]; ok && r != 0 {
+		return 1 / r, nil
+	}
+	return 0, fmt.Errorf("%w from %s to %s", ErrNoRate, from, to)
+}
+
+// Convert converts amount from one currency to another, rounded to the
+// minor unit.
+func (t Table) Convert(amount money.Amount, from, to model.Currency) (money.Amount, error) {
+	r, err := t.Rate(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return amount.Convert(r), nil
+}
+
+// Set stores the rate of a pair, replacing the previous rate of the pair
+// and of its inverse so the table never holds two conflicting rates for
+// the same conversion.
+func Set(db *gorm.DB, base, quote model.Currency, rate float64, updatedBy uint) (*model.FxRate, error) {
+	if base == quote {
+		return nil, fmt.Errorf("%w: base and quote must differ", ErrInvalidRate)
+	}
+	if rate <= 0 {
+		return nil, fmt.Errorf("%w: rate must be greater than zero", ErrInvalidRate)
+	}
+	r := &model.FxRate{
+		Base:      base,
+		Quote:     quote,
+		Rate:      rate,
+		UpdatedBy: updatedBy,
+		UpdatedAt: time.Now(),
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("base = ? AND quote = ?", quote, base).Delete(&model.FxRate{}).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "base"}, {Name: "quote"}},
+			DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_by", "updated_at"}),
+		}).Create(r).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"escrow_service/internal/model"
 	"escrow_service/internal/rabbitmq"
+	"escrow_service/internal/statemachine"
 	"log"
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/gorm"
@@ -33,25 +36,109 @@ func AcceptEscrow(c fiber.Ctx) error {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Escrow not found"})
 	}
 
+	if uint(userID) == escrow.BuyerID && escrow.InitiatedBy == string(statemachine.Seller) {
+		return acceptAsBuyer(c, db, &escrow)
+	}
+
 	if uint(userID) != escrow.SellerID {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Only seller can accept"})
 	}
+	if escrow.InitiatedBy == string(statemachine.Seller) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Seller-initiated escrows are accepted by the buyer"})
+	}
 
-	if escrow.Status != "Funded" {
+	if escrow.Status != model.Funded {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Escrow not funded"})
 	}
 
-	escrow.Active = true
-	db.Save(&escrow)
+	inspectUntil := time.Now().Add(time.Duration(escrow.InspectionPeriodHours) * time.Hour)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Only touch the acceptance columns, and only while the escrow is
+		// still Funded, so a concurrent release or dispute is not undone.
+		res := tx.Model(&model.Escrow{}).
+			Where("id = ? AND status = ?", escrow.ID, model.Funded).
+			Updates(map[string]any{"active": true, "inspection_deadline": inspectUntil})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return statemachine.ErrStaleStatus
+		}
+		escrow.Active = true
+		escrow.InspectionDeadline = &inspectUntil
+		if err := tx.Create(&model.EscrowEvent{
+			EscrowID:   escrow.ID,
+			Kind:       model.EventAccepted,
+			ActorID:    uint(userID),
+			ActorRole:  string(statemachine.Seller),
+			FromStatus: escrow.Status,
+			ToStatus:   escrow.Status,
+			Source:     model.SourceHTTP,
+		}).Error; err != nil {
+			return err
+		}
+		return rabbitmq.NewProducer(tx).PublishEscrowAccepted(uint64(escrow.ID), uint32(userID))
+	})
+	if errors.Is(err, statemachine.ErrStaleStatus) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Escrow changed, please retry"})
+	}
+	if err != nil {
+		log.Printf("Failed to accept escrow %d: %v", escrow.ID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to accept escrow"})
+	}
 
-	producer := rabbitmq.NewProducer()
-	err = producer.PublishEscrowAccepted(uint64(escrow.ID), uint32(userID))
+	return c.JSON(fiber.Map{
+		"message":             "Escrow accepted. Chat is now enabled.",
+		"active":              true,
+		"inspection_deadline": escrow.InspectionDeadline,
+	})
+}
+
+// acceptAsBuyer lets the buyer agree to an escrow a seller created. It
+// mirrors the seller's acceptance: the escrow becomes active (chat is
+// enabled) and the buyer may fund it. The inspection period starts once the
+// payment arrives.
+func acceptAsBuyer(c fiber.Ctx, db *gorm.DB, escrow *model.Escrow) error {
+	if escrow.Status != model.Pending {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Escrow is no longer pending"})
+	}
+	if !escrow.AwaitingBuyer {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Escrow already accepted"})
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.Escrow{}).
+			Where("id = ? AND status = ? AND awaiting_buyer = ?", escrow.ID, model.Pending, true).
+			Updates(map[string]any{"awaiting_buyer": false, "active": true})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return statemachine.ErrStaleStatus
+		}
+		if err := tx.Create(&model.EscrowEvent{
+			EscrowID:   escrow.ID,
+			Kind:       model.EventBuyerAccepted,
+			ActorID:    escrow.BuyerID,
+			ActorRole:  string(statemachine.Buyer),
+			FromStatus: escrow.Status,
+			ToStatus:   escrow.Status,
+			Source:     model.SourceHTTP,
+		}).Error; err != nil {
+			return err
+		}
+		return rabbitmq.NewProducer(tx).PublishBuyerAccepted(uint64(escrow.ID), uint32(escrow.BuyerID))
+	})
+	if errors.Is(err, statemachine.ErrStaleStatus) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Escrow changed, please retry"})
+	}
 	if err != nil {
-		log.Printf("Failed to publish escrow.accepted: %v", err)
+		log.Printf("Failed to accept escrow %d: %v", escrow.ID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to accept escrow"})
 	}
 
 	return c.JSON(fiber.Map{
-		"message": "Escrow accepted. Chat is now enabled.",
+		"message": "Escrow accepted. You can now fund it.",
 		"active":  true,
 	})
-}
\ No newline at end of file
+}
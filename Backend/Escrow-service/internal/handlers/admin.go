@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"escrow_service/internal/model"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// ListAllEscrows is the admin view of every escrow on the platform, newest
+// first. It can be narrowed with ?status= and ?user_id= (either party).
+func ListAllEscrows(c fiber.Ctx) error {
+	page := 1
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p >= 1 {
+		page = p
+	}
+	pageSize := 20
+	if ps, err := strconv.Atoi(c.Query("page_size")); err == nil && ps >= 1 && ps <= 100 {
+		pageSize = ps
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	query := db.Model(&model.Escrow{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		userID, err := strconv.ParseUint(userIDStr, 10, 32)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid user ID",
+			})
+		}
+		query = query.Where("buyer_id = ? OR seller_id = ?", userID, userID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to count escrows",
+		})
+	}
+	var escrows []model.Escrow
+	if err := query.Order("created_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&escrows).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch escrows",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"escrows":   escrows,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
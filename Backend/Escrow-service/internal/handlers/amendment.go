@@ -0,0 +1,416 @@
+package handlers
+
+import (
+	"errors"
+	"escrow_service/internal/fee"
+	"escrow_service/internal/model"
+	"escrow_service/internal/rabbitmq"
+	"escrow_service/internal/statemachine"
+	"fmt"
+	"log"
+	"message_broker/rabbitmq/events"
+	"shared/money"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// errTermsChanged is returned when the escrow's terms no longer match the
+// ones an amendment was proposed against, or the buyer started paying.
+var errTermsChanged = errors.New("escrow terms changed since the amendment was proposed")
+
+// errPaymentInitiated is returned for amendments of an escrow whose
+// checkout was already opened for the current terms.
+var errPaymentInitiated = errors.New("payment for this escrow was already initiated, its terms can no longer be amended")
+
+// ProposeAmendment lets either party of an unfunded escrow propose a new
+// amount and/or conditions. A new proposal supersedes any open one.
+func ProposeAmendment(c fiber.Ctx) error {
+	type Request struct {
+		Amount     *money.Amount `json:"amount"`
+		Conditions *string       `json:"conditions"`
+	}
+
+	escrowID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid escrow ID",
+		})
+	}
+
+	userIDStr := c.Get("X-User-ID")
+	if userIDStr == "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Missing X-User-ID",
+		})
+	}
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	var req Request
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if req.Amount == nil && req.Conditions == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Provide a new amount or new conditions",
+		})
+	}
+	if req.Amount != nil && *req.Amount <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Amount must be greater than zero",
+		})
+	}
+	if req.Conditions != nil {
+		trimmed := strings.TrimSpace(*req.Conditions)
+		req.Conditions = &trimmed
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var escrow model.Escrow
+	if err := db.First(&escrow, escrowID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Escrow not found",
+		})
+	}
+
+	actor, ok := statemachine.ActorOf(&escrow, uint(userID))
+	if !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access denied to this escrow",
+		})
+	}
+	if escrow.Status != model.Pending {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Escrow can only be amended before it is funded",
+		})
+	}
+	if escrow.PaymentInitiatedAt != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": errPaymentInitiated.Error(),
+		})
+	}
+	if req.Amount != nil {
+		var milestones int64
+		db.Model(&model.Milestone{}).Where("escrow_id = ?", escrow.ID).Count(&milestones)
+		if milestones > 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Escrow has milestones, its amount is their sum",
+			})
+		}
+	}
+	if (req.Amount == nil || *req.Amount == escrow.Amount) &&
+		(req.Conditions == nil || *req.Conditions == escrow.Conditions) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Amendment does not change the escrow",
+		})
+	}
+
+	amendment := model.Amendment{
+		EscrowID:           escrow.ID,
+		ProposedBy:         uint(userID),
+		Amount:             req.Amount,
+		Conditions:         req.Conditions,
+		PreviousAmount:     escrow.Amount,
+		PreviousConditions: escrow.Conditions,
+		Status:             model.AmendmentProposed,
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Amendment{}).
+			Where("escrow_id = ? AND status = ?", escrow.ID, model.AmendmentProposed).
+			Update("status", model.AmendmentSuperseded).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&amendment).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&model.EscrowEvent{
+			EscrowID:   escrow.ID,
+			Kind:       model.EventAmendmentProposed,
+			ActorID:    uint(userID),
+			ActorRole:  string(actor),
+			FromStatus: escrow.Status,
+			ToStatus:   escrow.Status,
+			Source:     model.SourceHTTP,
+			Note:       fmt.Sprintf("amendment %d", amendment.ID),
+		}).Error; err != nil {
+			return err
+		}
+		recipient := escrow.SellerID
+		if actor == statemachine.Seller {
+			recipient = escrow.BuyerID
+		}
+		return rabbitmq.NewProducer(tx).PublishAmendmentProposed(events.NewEscrowAmendmentProposedEvent(
+			uint64(escrow.ID),
+			uint64(amendment.ID),
+			uint32(userID),
+			uint32(recipient),
+			amendment.Amount,
+			string(escrow.Currency),
+			amendment.Conditions,
+		))
+	})
+	if err != nil {
+		log.Printf("Failed to propose amendment on escrow %d: %v", escrow.ID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to propose amendment",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(amendment)
+}
+
+// GetAmendments lists every amendment proposed on an escrow, newest first.
+func GetAmendments(c fiber.Ctx) error {
+	escrowID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid escrow ID",
+		})
+	}
+
+	userIDStr := c.Get("X-User-ID")
+	if userIDStr == "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Missing X-User-ID",
+		})
+	}
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var escrow model.Escrow
+	if err := db.First(&escrow, escrowID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Escrow not found",
+		})
+	}
+	if _, ok := statemachine.ActorOf(&escrow, uint(userID)); !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access denied to this escrow",
+		})
+	}
+
+	var amendments []model.Amendment
+	if err := db.Where("escrow_id = ?", escrow.ID).
+		Order("created_at DESC").
+		Find(&amendments).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch amendments",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"amendments": amendments,
+		"total":      len(amendments),
+	})
+}
+
+// AcceptAmendment applies an open amendment to the escrow. Only the
+// counterparty of the proposer may accept.
+func AcceptAmendment(c fiber.Ctx) error {
+	return decideAmendment(c, true)
+}
+
+// RejectAmendment declines an open amendment made by the counterparty.
+func RejectAmendment(c fiber.Ctx) error {
+	return decideAmendment(c, false)
+}
+
+func decideAmendment(c fiber.Ctx, accept bool) error {
+	escrowID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid escrow ID",
+		})
+	}
+	amendmentID, err := strconv.ParseUint(c.Params("amendmentId"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid amendment ID",
+		})
+	}
+
+	userIDStr := c.Get("X-User-ID")
+	if userIDStr == "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Missing X-User-ID",
+		})
+	}
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var escrow model.Escrow
+	if err := db.First(&escrow, escrowID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Escrow not found",
+		})
+	}
+	actor, ok := statemachine.ActorOf(&escrow, uint(userID))
+	if !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access denied to this escrow",
+		})
+	}
+
+	var amendment model.Amendment
+	if err := db.Where("id = ? AND escrow_id = ?", amendmentID, escrow.ID).First(&amendment).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Amendment not found",
+		})
+	}
+	if amendment.Status != model.AmendmentProposed {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Amendment is no longer open",
+		})
+	}
+	if amendment.ProposedBy == uint(userID) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Only the other party can decide on this amendment",
+		})
+	}
+
+	decidedBy := uint(userID)
+	if !accept {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&amendment).Updates(model.Amendment{
+				Status:    model.AmendmentRejected,
+				DecidedBy: &decidedBy,
+			}).Error; err != nil {
+				return err
+			}
+			return tx.Create(&model.EscrowEvent{
+				EscrowID:   escrow.ID,
+				Kind:       model.EventAmendmentRejected,
+				ActorID:    uint(userID),
+				ActorRole:  string(actor),
+				FromStatus: escrow.Status,
+				ToStatus:   escrow.Status,
+				Source:     model.SourceHTTP,
+				Note:       fmt.Sprintf("amendment %d", amendment.ID),
+			}).Error
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to reject amendment",
+			})
+		}
+		return c.JSON(fiber.Map{
+			"message":   "Amendment rejected",
+			"amendment": amendment,
+		})
+	}
+
+	if escrow.Status != model.Pending {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Escrow can only be amended before it is funded",
+		})
+	}
+	if escrow.PaymentInitiatedAt != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": errPaymentInitiated.Error(),
+		})
+	}
+
+	updates := map[string]any{}
+	var quote fee.Quote
+	if amendment.Amount != nil {
+		quote, err = fee.Requote(db, &escrow, *amendment.Amount)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to compute platform fee",
+			})
+		}
+		updates["amount"] = *amendment.Amount
+		updates["buyer_fee"] = quote.BuyerFee
+		updates["seller_fee"] = quote.SellerFee
+	}
+	if amendment.Conditions != nil {
+		updates["conditions"] = *amendment.Conditions
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Apply the terms only if the escrow is still unfunded, the buyer
+		// has not started paying and nothing changed them since the
+		// proposal was made.
+		res := tx.Model(&model.Escrow{}).
+			Where("id = ? AND status = ? AND payment_initiated_at IS NULL AND amount = ? AND COALESCE(conditions, '') = ?",
+				escrow.ID, model.Pending, amendment.PreviousAmount, amendment.PreviousConditions).
+			Updates(updates)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errTermsChanged
+		}
+		if err := tx.Model(&amendment).Updates(model.Amendment{
+			Status:    model.AmendmentAccepted,
+			DecidedBy: &decidedBy,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&model.EscrowEvent{
+			EscrowID:   escrow.ID,
+			Kind:       model.EventAmendmentAccepted,
+			ActorID:    uint(userID),
+			ActorRole:  string(actor),
+			FromStatus: escrow.Status,
+			ToStatus:   escrow.Status,
+			Source:     model.SourceHTTP,
+			Note:       fmt.Sprintf("amendment %d", amendment.ID),
+		}).Error; err != nil {
+			return err
+		}
+		amount := escrow.Amount
+		if amendment.Amount != nil {
+			amount = *amendment.Amount
+		}
+		return rabbitmq.NewProducer(tx).PublishEscrowAmended(events.NewEscrowAmendedEvent(
+			uint64(escrow.ID),
+			uint64(amendment.ID),
+			uint32(amendment.ProposedBy),
+			uint32(userID),
+			amount,
+			string(escrow.Currency),
+			amount != amendment.PreviousAmount,
+		))
+	})
+	if errors.Is(err, errTermsChanged) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		log.Printf("Failed to accept amendment %d: %v", amendment.ID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to accept amendment",
+		})
+	}
+	if amendment.Amount != nil {
+		escrow.Amount = *amendment.Amount
+		fee.Apply(&escrow, quote)
+	}
+	if amendment.Conditions != nil {
+		escrow.Conditions = *amendment.Conditions
+	}
+
+	return c.JSON(fiber.Map{
+		"message":   "Amendment accepted",
+		"amendment": amendment,
+		"escrow":    escrow,
+	})
+}
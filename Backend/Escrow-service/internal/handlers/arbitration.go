@@ -0,0 +1,423 @@
+package handlers
+
+import (
+	"errors"
+	"escrow_service/internal/model"
+	"escrow_service/internal/payout"
+	"escrow_service/internal/rabbitmq"
+	"escrow_service/internal/statemachine"
+	"fmt"
+	"log"
+	"message_broker/rabbitmq/events"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+const maxArbitrationNoteLength = 2000
+
+type arbitrationRequest struct {
+	// Approve takes the AI recommendation as the outcome.
+	Approve       bool                     `json:"approve"`
+	Outcome       model.ArbitrationOutcome `json:"outcome"`
+	SellerPercent *float64                 `json:"seller_percent"`
+	Note          string                   `json:"note"`
+}
+
+// GetArbitrationQueue lists the open disputes waiting for an arbitrator,
+// oldest first, with the AI recommendation where one has been requested and
+// the seller's shipping and delivery records, so "not delivered" claims can
+// be checked against them.
+func GetArbitrationQueue(c fiber.Ctx) error {
+	page := 1
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p >= 1 {
+		page = p
+	}
+	pageSize := 20
+	if ps, err := strconv.Atoi(c.Query("page_size")); err == nil && ps >= 1 && ps <= 100 {
+		pageSize = ps
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	query := db.Model(&model.Dispute{}).
+		Joins("JOIN escrows ON escrows.id = disputes.escrow_id AND escrows.deleted_at IS NULL").
+		Where("disputes.status = ? AND escrows.status = ?", model.DisputeOpen, model.Disputed)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to count disputes",
+		})
+	}
+	var disputes []model.Dispute
+	if err := query.Order("disputes.created_at ASC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&disputes).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch disputes",
+		})
+	}
+
+	escrowIDs := make([]uint, 0, len(disputes))
+	disputeIDs := make([]uint, 0, len(disputes))
+	for _, d := range disputes {
+		escrowIDs = append(escrowIDs, d.EscrowID)
+		disputeIDs = append(disputeIDs, d.ID)
+	}
+	var escrows []model.Escrow
+	var arbitrations []model.Arbitration
+	var deliveries []model.Delivery
+	if len(disputes) > 0 {
+		if err := db.Where("id IN ?", escrowIDs).Find(&escrows).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to fetch escrows",
+			})
+		}
+		if err := db.Where("dispute_id IN ?", disputeIDs).Find(&arbitrations).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to fetch recommendations",
+			})
+		}
+		if err := db.Where("escrow_id IN ?", escrowIDs).Order("created_at").Find(&deliveries).Error; err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to fetch deliveries",
+			})
+		}
+	}
+	escrowByID := make(map[uint]model.Escrow, len(escrows))
+	for _, e := range escrows {
+		escrowByID[e.ID] = e
+	}
+	arbitrationByDispute := make(map[uint]*model.Arbitration, len(arbitrations))
+	for i := range arbitrations {
+		arbitrationByDispute[arbitrations[i].DisputeID] = &arbitrations[i]
+	}
+	deliveriesByEscrow := make(map[uint][]model.Delivery, len(escrows))
+	for _, d := range deliveries {
+		deliveriesByEscrow[d.EscrowID] = append(deliveriesByEscrow[d.EscrowID], d)
+	}
+
+	results := make([]fiber.Map, 0, len(disputes))
+	for _, d := range disputes {
+		e := escrowByID[d.EscrowID]
+		results = append(results, fiber.Map{
+			"escrow": fiber.Map{
+				"id":             e.ID,
+				"buyer_id":       e.BuyerID,
+				"seller_id":      e.SellerID,
+				"amount":         e.Amount,
+				"status":         e.Status,
+				"conditions":     e.Conditions,
+				"conditions_doc": e.ConditionsDoc,
+				"created_at":     e.CreatedAt,
+				"shipped_at":     e.ShippedAt,
+				"delivered_at":   e.DeliveredAt,
+			},
+			"dispute":     d,
+			"arbitration": arbitrationByDispute[d.ID],
+			"deliveries":  deliveriesByEscrow[d.EscrowID],
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"queue":     results,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// DecideArbitration lets an arbitrator decide the open dispute of an escrow,
+// either approving the AI recommendation or choosing an outcome of their
+// own. The outcome is paid out through the same paths the parties use:
+// release like ConfirmReceipt, refund like RefundEscrow and split like an
+// accepted settlement.
+func DecideArbitration(c fiber.Ctx) error {
+	escrowID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid escrow ID",
+		})
+	}
+
+	userIDStr := c.Get("X-User-ID")
+	if userIDStr == "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Missing X-User-ID",
+		})
+	}
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	var req arbitrationRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	req.Note = strings.TrimSpace(req.Note)
+	if len(req.Note) > maxArbitrationNoteLength {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("Note must be at most %d characters", maxArbitrationNoteLength),
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var escrow model.Escrow
+	if err := db.First(&escrow, escrowID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Escrow not found",
+		})
+	}
+	if uint(userID) == escrow.BuyerID || uint(userID) == escrow.SellerID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You cannot arbitrate an escrow you are a party to",
+		})
+	}
+
+	var dispute model.Dispute
+	if err := db.Where("escrow_id = ? AND status = ?", escrow.ID, model.DisputeOpen).First(&dispute).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Escrow has no open dispute",
+		})
+	}
+	arbitration := model.Arbitration{
+		EscrowID:  escrow.ID,
+		DisputeID: dispute.ID,
+		Status:    model.ArbitrationPending,
+	}
+	if err := db.Where("dispute_id = ?", dispute.ID).FirstOrCreate(&arbitration).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load arbitration",
+		})
+	}
+
+	outcome := req.Outcome
+	if req.Approve {
+		if arbitration.AIOutcome == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "There is no AI recommendation to approve",
+			})
+		}
+		if outcome != "" && outcome != arbitration.AIOutcome {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Approving the recommendation conflicts with the given outcome",
+			})
+		}
+		outcome = arbitration.AIOutcome
+	}
+
+	var sellerPercent float64
+	switch outcome {
+	case model.OutcomeRelease:
+		sellerPercent = 100
+	case model.OutcomeRefund:
+		sellerPercent = 0
+	case model.OutcomeSplit:
+		if req.SellerPercent == nil || *req.SellerPercent < 0 || *req.SellerPercent > 100 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "seller_percent must be between 0 and 100",
+			})
+		}
+		sellerPercent = *req.SellerPercent
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "outcome must be release, refund or split",
+		})
+	}
+
+	if outcome == model.OutcomeRefund {
+		paidOut, err := paidOutMilestoneTotal(db, escrow.ID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to compute refund amount",
+			})
+		}
+		if escrow.Amount-paidOut <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Nothing left to refund",
+			})
+		}
+	}
+
+	target := map[model.ArbitrationOutcome]model.EscrowStatus{
+		model.OutcomeRelease: model.TransferPending,
+		model.OutcomeRefund:  model.Refunded,
+		model.OutcomeSplit:   model.Settling,
+	}[outcome]
+	if err := statemachine.Check(escrow.Status, target, statemachine.Arbitrator); err != nil {
+		return transitionFailed(c, err)
+	}
+
+	// Claim the arbitration so two arbitrators cannot pay out the same
+	// dispute twice.
+	now := time.Now()
+	followedAI := arbitration.AIOutcome != "" && outcome == arbitration.AIOutcome
+	res := db.Model(&model.Arbitration{}).
+		Where("id = ? AND status = ?", arbitration.ID, model.ArbitrationPending).
+		Updates(map[string]any{
+			"status":         model.ArbitrationExecuting,
+			"outcome":        outcome,
+			"seller_percent": sellerPercent,
+			"followed_ai":    followedAI,
+			"arbitrator_id":  uint(userID),
+			"note":           req.Note,
+			"decided_at":     now,
+			"failure_reason": "",
+		})
+	if res.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to record decision",
+		})
+	}
+	if res.RowsAffected == 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Dispute is already being decided",
+		})
+	}
+	arbiterID := uint(userID)
+	arbitration.Status = model.ArbitrationExecuting
+	arbitration.Outcome = outcome
+	arbitration.SellerPercent = &sellerPercent
+	arbitration.FollowedAI = followedAI
+	arbitration.ArbitratorID = &arbiterID
+	arbitration.Note = req.Note
+	arbitration.DecidedAt = &now
+
+	t := statemachine.Trigger{
+		Actor:  statemachine.Arbitrator,
+		UserID: uint(userID),
+		Source: model.SourceHTTP,
+		Note:   fmt.Sprintf("arbitration %d: %s", arbitration.ID, outcome),
+	}
+	execErr := executeArbitration(db, &escrow, &arbitration, t)
+	finishArbitration(db, &escrow, &arbitration, execErr)
+	if execErr != nil {
+		return payoutFailed(c, execErr)
+	}
+
+	return c.JSON(fiber.Map{
+		"message":     "Decision recorded. Funds are being paid out.",
+		"status":      escrow.Status,
+		"arbitration": arbitration,
+	})
+}
+
+// executeArbitration starts the payout for the arbitration's outcome. An
+// escrow with milestones is always paid out as a split, because part of it
+// may already have been released.
+func executeArbitration(db *gorm.DB, escrow *model.Escrow, arbitration *model.Arbitration, t statemachine.Trigger) error {
+	var milestones int64
+	if err := db.Model(&model.Milestone{}).Where("escrow_id = ?", escrow.ID).Count(&milestones).Error; err != nil {
+		return err
+	}
+
+	switch {
+	case arbitration.Outcome == model.OutcomeRelease && milestones == 0:
+		return payout.ReleaseEscrow(db, escrow, t)
+	case arbitration.Outcome == model.OutcomeRefund:
+		paidOut, err := paidOutMilestoneTotal(db, escrow.ID)
+		if err != nil {
+			return err
+		}
+		refundAmount := escrow.Amount - paidOut
+		if refundAmount <= 0 {
+			return errors.New("nothing left to refund")
+		}
+		return payout.RefundBuyer(db, escrow, refundAmount, model.Refunded,
+			fmt.Sprintf("refund-escrow-%d", escrow.ID), t, nil)
+	}
+
+	settlement := model.Settlement{
+		EscrowID:      escrow.ID,
+		ProposedBy:    t.UserID,
+		SellerPercent: *arbitration.SellerPercent,
+		Status:        model.SettlementProposed,
+	}
+	if err := db.Create(&settlement).Error; err != nil {
+		return err
+	}
+	return executeSettlement(db, escrow, &settlement, t)
+}
+
+// finishArbitration records how the payout went. If the escrow never left
+// Disputed the arbitration goes back to Pending so it can be decided again;
+// otherwise the decision took effect and is written to the escrow and its
+// history.
+func finishArbitration(db *gorm.DB, escrow *model.Escrow, arbitration *model.Arbitration, execErr error) {
+	if execErr != nil && escrow.Status == model.Disputed {
+		arbitration.Status = model.ArbitrationPending
+		arbitration.FailureReason = execErr.Error()
+		if err := db.Model(arbitration).Updates(map[string]any{
+			"status":         arbitration.Status,
+			"failure_reason": arbitration.FailureReason,
+		}).Error; err != nil {
+			log.Printf("Failed to release arbitration %d: %v", arbitration.ID, err)
+		}
+		return
+	}
+
+	arbitration.Status = model.ArbitrationExecuted
+	if execErr != nil {
+		arbitration.Status = model.ArbitrationFailed
+		arbitration.FailureReason = execErr.Error()
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(arbitration).Updates(map[string]any{
+			"status":         arbitration.Status,
+			"failure_reason": arbitration.FailureReason,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(escrow).Updates(map[string]any{
+			"arbitrator_id":       arbitration.ArbitratorID,
+			"arbitration_outcome": string(arbitration.Outcome),
+		}).Error; err != nil {
+			return err
+		}
+
+		note := fmt.Sprintf("dispute %d: %s", arbitration.DisputeID, arbitration.Outcome)
+		if arbitration.Outcome == model.OutcomeSplit {
+			note += fmt.Sprintf(" (%.2f%% to seller)", *arbitration.SellerPercent)
+		}
+		if arbitration.FollowedAI {
+			note += ", following the AI recommendation"
+		} else if arbitration.AIOutcome != "" {
+			note += fmt.Sprintf(", overriding the AI recommendation (%s)", arbitration.AIOutcome)
+		}
+		if err := tx.Create(&model.EscrowEvent{
+			EscrowID:   escrow.ID,
+			Kind:       model.EventArbitrated,
+			ActorID:    *arbitration.ArbitratorID,
+			ActorRole:  string(statemachine.Arbitrator),
+			FromStatus: model.Disputed,
+			ToStatus:   escrow.Status,
+			Source:     model.SourceHTTP,
+			Note:       note,
+		}).Error; err != nil {
+			return err
+		}
+		return rabbitmq.NewProducer(tx).PublishEscrowArbitrated(events.NewEscrowArbitratedEvent(
+			uint64(escrow.ID),
+			uint64(arbitration.DisputeID),
+			uint32(escrow.BuyerID),
+			uint32(escrow.SellerID),
+			uint32(*arbitration.ArbitratorID),
+			string(arbitration.Outcome),
+			*arbitration.SellerPercent,
+			arbitration.FollowedAI,
+			arbitration.Note,
+		))
+	})
+	if err != nil {
+		log.Printf("Failed to record arbitration %d on escrow %d: %v", arbitration.ID, escrow.ID, err)
+	}
+	escrow.ArbitratorID = arbitration.ArbitratorID
+	escrow.ArbitrationOutcome = string(arbitration.Outcome)
+}
@@ -3,12 +3,10 @@
 package handlers
 
 import (
-	"escrow_service/internal/auth"
 	"escrow_service/internal/model"
-	"escrow_service/internal/payment"
+	"escrow_service/internal/payout"
+	"escrow_service/internal/statemachine"
 	"fmt"
-	"log"
-	"shared/chapa"
 	"strconv"
 
 	"github.com/gofiber/fiber/v3"
@@ -56,10 +54,8 @@ func CancelEscrow(c fiber.Ctx) error {
 	}
 
 	// Business rule: Can only cancel if seller has NOT accepted (not active) AND status is Funded
-	if escrow.Status != "Funded" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Escrow must be funded to be cancelled",
-		})
+	if err := statemachine.Check(escrow.Status, model.Cancelled, statemachine.Buyer); err != nil {
+		return transitionFailed(c, err)
 	}
 	if escrow.Active {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -67,77 +63,16 @@ func CancelEscrow(c fiber.Ctx) error {
 		})
 	}
 
-	// Get buyer's bank details (to refund to them)
-	userServiceClient, err := auth.NewUserServiceClient("user-service:50051")
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to connect to user service" + err.Error(),
-		})
-	}
-	defer userServiceClient.Close()
-
-	buyerRes, err := userServiceClient.GetUser(uint32(escrow.BuyerID))
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get buyer info",
-		})
-	}
-
-	if !buyerRes.Activated {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Buyer account is not activated",
-		})
-	}
-
-	if buyerRes.AccountName == nil || buyerRes.AccountName.Value == "" ||
-		buyerRes.AccountNumber == nil || buyerRes.AccountNumber.Value == "" ||
-		buyerRes.BankCode == nil || buyerRes.BankCode.Value == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Buyer has not added bank account details",
-		})
-	}
-
-	// Validate bank account using Chapa
-	err = chapa.ValidateAccount(int(buyerRes.BankCode.GetValue()), buyerRes.AccountNumber.GetValue())
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid bank details: " + err.Error(),
-		})
-	}
-
-	// Initiate transfer to buyer
-	chapaClient := chapa.NewClient()
-	resp, err := chapaClient.TransferToSeller(
-		escrow.BuyerID,
-		escrow.Amount,
+	// Refund the buyer, fee included, then mark the escrow Cancelled
+	err = payout.RefundInFull(db, &escrow, model.Cancelled,
 		fmt.Sprintf("cancel-escrow-%d", escrow.ID),
-		buyerRes.AccountName.Value,
-		buyerRes.AccountNumber.Value,
-		int(buyerRes.BankCode.Value),
-	)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to initiate cancellation refund: " + err.Error(),
-		})
-	}
-
-	// ✅ Update status to "Cancelled"
-	escrow.Status = "Cancelled"
-	db.Save(&escrow)
-
-	// Finalize via payment service
-	paymentClient, err := payment.NewPaymentServiceClient("payment-service:50053")
+		statemachine.Trigger{
+			Actor:  statemachine.Buyer,
+			UserID: uint(userID),
+			Source: model.SourceHTTP,
+		}, nil)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to connect to payment service",
-		})
-	}
-	response, err := paymentClient.Finalize(resp.Data)
-	if err != nil {
-		log.Printf("gRPC Finalize error: %v ", err)
-	}
-	if response != nil && !response.Success {
-		log.Printf("Finalize failed: %s", response.Error)
+		return payoutFailed(c, err)
 	}
 
 	return c.JSON(fiber.Map{
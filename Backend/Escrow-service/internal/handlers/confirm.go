@@ -2,12 +2,9 @@ package handlers
 
 import (
 	
-	"escrow_service/internal/auth"
 	"escrow_service/internal/model"
-	"escrow_service/internal/payment"
-	"fmt"
-	"log"
-	"shared/chapa"
+	"escrow_service/internal/payout"
+	"escrow_service/internal/statemachine"
 	"strconv"
 
 	"github.com/gofiber/fiber/v3"
@@ -52,84 +49,28 @@ func ConfirmReceipt(c fiber.Ctx) error {
 		})
 	}
 
-	
-	if escrow.Status != "Funded"{
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Escrow is not in a state to be confirmed",
-		})
+	if err := statemachine.Check(escrow.Status, model.TransferPending, statemachine.Buyer); err != nil {
+		return transitionFailed(c, err)
 	}
 	if !escrow.Active{
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Escrow must be accepted by seller first",
 		})
 	}
-	userServiceClient, err := auth.NewUserServiceClient("user-service:50051")
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to connect to user service"+ err.Error(),
-		})
-	}
-	defer userServiceClient.Close()
-
-	sellerRes, err := userServiceClient.GetUser(uint32(escrow.SellerID))
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get seller info",
+	var milestones int64
+	db.Model(&model.Milestone{}).Where("escrow_id = ?", escrow.ID).Count(&milestones)
+	if milestones > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Escrow has milestones, confirm them individually",
 		})
 	}
-	if !sellerRes.Activated{
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-		   "error": "Seller account is not activated",
-	     })
+	if err := payout.ReleaseEscrow(db, &escrow, statemachine.Trigger{
+		Actor:  statemachine.Buyer,
+		UserID: uint(userID),
+		Source: model.SourceHTTP,
+	}); err != nil {
+		return payoutFailed(c, err)
 	}
-	if sellerRes.AccountName == nil || sellerRes.AccountName.Value == "" ||
-	    sellerRes.AccountNumber == nil || sellerRes.AccountNumber.Value == "" ||
-	      sellerRes.BankCode == nil || sellerRes.BankCode.Value == 0 {
-	           return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-		          "error": "Seller has not added bank account details",
-	           })
-            }
-
-	err = chapa.ValidateAccount(int(sellerRes.BankCode.GetValue()), sellerRes.AccountNumber.GetValue())
-      if err != nil {
-	   return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-		     "error": "Invalid bank details: " + err.Error(),
-	      })
-        }
-
-    chapaClient := chapa.NewClient()
-	resp, err := chapaClient.TransferToSeller(
-		escrow.SellerID,
-	     escrow.Amount,
-	    fmt.Sprintf("escrow-%d", escrow.ID),
-		sellerRes.AccountName.Value,
-		sellerRes.AccountNumber.Value,
-		int(sellerRes.BankCode.Value),
-	
-	)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to initiate transfer: " + err.Error(),
-		})
-	}
-
-    escrow.Status = "TransferPending"
-	db.Save(&escrow)
-
-	paymentClient, err := payment.NewPaymentServiceClient("payment-service:50053")
-    if err != nil {
-	      return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-		        "error": "Failed to connect to payment service",
-	    })
-      }
-     response,err:=paymentClient.Finalize(resp.Data)
-	 if err!=nil{
-		log.Printf("grpc error: %v ",err)
-	 }
-     if !response.Success{
-		log.Printf("Finalize failed: %s", response.Error)
-      
-	 }
 
 	return c.JSON(fiber.Map{
 	  "message": "Receipt confirmed. Funds released.",
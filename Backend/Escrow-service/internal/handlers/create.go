@@ -1,23 +1,62 @@
 package handlers
 
 import (
-	
-	"escrow_service/internal/auth"
+	"bytes"
+	"encoding/json"
+	"escrow_service/internal/creation"
+	"escrow_service/internal/fee"
 	"escrow_service/internal/model"
-	"escrow_service/internal/rabbitmq"
-	"log"
+	"fmt"
+	"shared/money"
 	"strconv"
+	"strings"
+	"time"
 
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/gorm"
 )
 
+// createEscrowRequest holds the terms a caller may set on a new escrow.
+// Everything else (status, fees, deadlines the escrow reaches later,
+// delivery and arbitration records) is set by the service, so it is not
+// part of the request at all, and a JSON body naming any of it is rejected
+// (see bindCreateRequest).
+type createEscrowRequest struct {
+	BuyerID               uint                      `json:"buyer_id"`
+	SellerID              uint                      `json:"seller_id"`
+	Amount                money.Amount              `json:"amount"`
+	Currency              model.Currency            `json:"currency"`
+	Conditions            string                    `json:"conditions"`
+	ConditionsDoc         *model.ConditionsDocument `json:"conditions_doc"`
+	Milestones            []model.Milestone         `json:"milestones"`
+	FundingDeadline       *time.Time                `json:"funding_deadline"`
+	InspectionPeriodHours int                       `json:"inspection_period_hours"`
+	InitiatedBy           string                    `json:"initiated_by"`
+	// SellerEmail invites a seller who may not have an account yet instead
+	// of naming SellerID; see inviteSeller.
+	SellerEmail string `json:"seller_email"`
+}
+
+// escrow returns the new escrow with the requested terms.
+func (r *createEscrowRequest) escrow() *model.Escrow {
+	return &model.Escrow{
+		BuyerID:               r.BuyerID,
+		SellerID:              r.SellerID,
+		Amount:                r.Amount,
+		Currency:              r.Currency,
+		Conditions:            r.Conditions,
+		ConditionsDoc:         r.ConditionsDoc,
+		Milestones:            r.Milestones,
+		FundingDeadline:       r.FundingDeadline,
+		InspectionPeriodHours: r.InspectionPeriodHours,
+		InitiatedBy:           r.InitiatedBy,
+	}
+}
 
 func CreateEscrow(c fiber.Ctx) error {
-	escrow := new(model.Escrow)
-	if err := c.Bind().Body(escrow); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	req := new(createEscrowRequest)
+	if msg := bindCreateRequest(c, req); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": msg})
 	}
 
 	userIDStr := c.Get("X-User-ID")
@@ -27,116 +66,71 @@ func CreateEscrow(c fiber.Ctx) error {
 		})
 	}
 
-	buyerID, err := strconv.ParseUint(userIDStr, 10, 32)
+	callerID, err := strconv.ParseUint(userIDStr, 10, 32)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Invalid user ID",
 		})
 	}
 
-	if escrow.SellerID == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Seller ID is required",
-		})
-	}
-    if escrow.Amount <= 0 {
-		 return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-             "error": "Amount must be greater than zero",
-	       })
-    }
-	if uint32(buyerID) == uint32(escrow.SellerID) {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Buyer and seller cannot be the same user",
-		})
-	}
-
-	userServiceClient, err := auth.NewUserServiceClient("user-service:50051")
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to connect to user service",
-		})
-	}
-	defer userServiceClient.Close()
-
-	buyerRes, err := userServiceClient.GetUser(uint32(buyerID))
-	if err != nil || buyerRes == nil || !buyerRes.Activated {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Buyer account is not activated",
-		})
-	}
-
-	sellerRes, err := userServiceClient.GetUser(uint32(escrow.SellerID))
-	if err != nil || sellerRes == nil || !sellerRes.Activated {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Seller account is not activated",
-		})
-	}
-
-	// ✅ Validate bank details
-	if buyerRes.AccountName == nil || buyerRes.AccountName.Value == "" ||
-		buyerRes.AccountNumber == nil || buyerRes.AccountNumber.Value == "" ||
-		buyerRes.BankCode == nil || buyerRes.BankCode.Value == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Buyer has not added bank account details",
-		})
-	}
-	if sellerRes.AccountName == nil || sellerRes.AccountName.Value == "" ||
-		sellerRes.AccountNumber == nil || sellerRes.AccountNumber.Value == "" ||
-		sellerRes.BankCode == nil || sellerRes.BankCode.Value == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Seller has not added bank account details",
-		})
+	if req.SellerEmail != "" {
+		return inviteSeller(c, req.escrow(), req.SellerEmail, uint(callerID))
 	}
-
-	// ✅ Validate wallet
-	var buyerAddr, sellerAddr common.Address
-	if buyerRes.WalletAddress == nil || buyerRes.WalletAddress.Value == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Buyer has not created a wallet",
-		})
+	return createEscrow(c, req.escrow(), uint(callerID), nil)
+}
+
+// bindCreateRequest reads the request body into req. JSON bodies with a
+// field that is not a creatable term, such as active, arbitrator_id or
+// decline_reason, are refused so a client cannot believe it set them. On
+// failure it returns the message to respond with.
+func bindCreateRequest(c fiber.Ctx, req *createEscrowRequest) string {
+	if !strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEApplicationJSON) {
+		if err := c.Bind().Body(req); err != nil {
+			return "Invalid request"
+		}
+		return ""
 	}
-	buyerAddr = common.HexToAddress(buyerRes.WalletAddress.Value)
-
-	if sellerRes.WalletAddress == nil || sellerRes.WalletAddress.Value == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Seller has not created a wallet",
-		})
+	dec := json.NewDecoder(bytes.NewReader(c.Body()))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(req); err != nil {
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return fmt.Sprintf("%s cannot be set when creating an escrow", strings.Trim(field, `"`))
+		}
+		return "Invalid request"
 	}
-	sellerAddr = common.HexToAddress(sellerRes.WalletAddress.Value)
-
-	// ✅ Set escrow fields
-	escrow.BuyerID = uint(buyerID)
-	escrow.Status = model.Pending
-
-	
+	return ""
+}
 
-	// ✅ Save in DB
+// createEscrow creates the escrow for the caller and answers the request.
+// An escrow created from a template counts as a use of it.
+func createEscrow(c fiber.Ctx, escrow *model.Escrow, callerID uint, template *model.EscrowTemplate) error {
 	db := c.Locals("db").(*gorm.DB)
-	if err := db.Create(&escrow).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create escrow",
-		})
-	}
-
-	// ✅ Publish event
-	producer := rabbitmq.NewProducer()
-	err = producer.PublishCreateEscrow(
-		uint64(escrow.ID),
-		uint32(escrow.BuyerID),
-		uint32(escrow.SellerID),
-		escrow.Amount,
-		buyerAddr.Hex(),
-		sellerAddr.Hex(),
-	)
+	quote, err := creation.Create(db, creation.Request{
+		Escrow:   escrow,
+		CallerID: callerID,
+		Source:   model.SourceHTTP,
+		Template: template,
+	})
 	if err != nil {
-		log.Printf("Failed to publish CreateEscrow event: %v", err)
-		// Don't fail — best effort
+		return creationFailed(c, err)
 	}
+	return escrowCreated(c, escrow, quote)
+}
+
+// escrowCreated answers a request that created an escrow.
+func escrowCreated(c fiber.Ctx, escrow *model.Escrow, quote fee.Quote) error {
+	return c.Status(fiber.StatusAccepted).JSON(createdResponse(escrow, quote))
+}
 
-	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+func createdResponse(escrow *model.Escrow, quote fee.Quote) fiber.Map {
+	return fiber.Map{
 		"message": "Escrow creation started",
 		"id":      escrow.ID,
 		"status":  "Pending",
+		"currency": escrow.Currency,
+		"initiated_by": escrow.InitiatedBy,
+		"awaiting_buyer": escrow.AwaitingBuyer,
+		"fees":           quote,
 		"on_chain_status": "awaiting_confirmation",
-	})
-}
\ No newline at end of file
+	}
+}
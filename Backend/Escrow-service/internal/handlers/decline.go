@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"escrow_service/internal/model"
+	"escrow_service/internal/payout"
+	"escrow_service/internal/rabbitmq"
+	"escrow_service/internal/statemachine"
+	"fmt"
+	"message_broker/rabbitmq/events"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+const maxDeclineReasonLength = 500
+
+type declineRequest struct {
+	Reason string `json:"reason"`
+}
+
+// DeclineEscrow lets the invited party turn an escrow down: the seller on a
+// buyer-initiated escrow they have not accepted yet, or the buyer on a
+// seller-initiated one. The escrow ends in Declined. If the buyer already
+// funded it, the money is refunded the same way CancelEscrow does.
+func DeclineEscrow(c fiber.Ctx) error {
+	escrowID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid escrow ID"})
+	}
+
+	userIDStr := c.Get("X-User-ID")
+	if userIDStr == "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Missing X-User-ID"})
+	}
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+
+	// The reason is optional, so an empty body is fine.
+	var req declineRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().Body(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+		}
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if len(req.Reason) > maxDeclineReasonLength {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("Reason must be at most %d characters", maxDeclineReasonLength),
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var escrow model.Escrow
+	if err := db.First(&escrow, escrowID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Escrow not found"})
+	}
+
+	var actor statemachine.Actor
+	switch {
+	case uint(userID) == escrow.SellerID && escrow.InitiatedBy != string(statemachine.Seller):
+		if escrow.Active {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Escrow already accepted"})
+		}
+		actor = statemachine.Seller
+	case uint(userID) == escrow.BuyerID && escrow.InitiatedBy == string(statemachine.Seller):
+		if !escrow.AwaitingBuyer {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Escrow already accepted"})
+		}
+		actor = statemachine.Buyer
+	default:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Only the invited party can decline this escrow",
+		})
+	}
+
+	t := statemachine.Trigger{
+		Actor:  actor,
+		UserID: uint(userID),
+		Source: model.SourceHTTP,
+		Note:   req.Reason,
+	}
+	refunded := escrow.Status == model.Funded
+	declined := func(tx *gorm.DB) error {
+		if req.Reason != "" {
+			if err := tx.Model(&escrow).Update("decline_reason", req.Reason).Error; err != nil {
+				return err
+			}
+		}
+		return rabbitmq.NewProducer(tx).PublishEscrowDeclined(events.NewEscrowDeclinedEvent(
+			uint64(escrow.ID),
+			uint32(escrow.BuyerID),
+			uint32(escrow.SellerID),
+			uint32(userID),
+			req.Reason,
+			refunded,
+		))
+	}
+	if refunded {
+		// The reason and the event are written in the transaction that
+		// declines the escrow and starts the refund.
+		err = payout.RefundInFull(db, &escrow, model.Declined,
+			fmt.Sprintf("decline-escrow-%d", escrow.ID), t, declined)
+		if err != nil {
+			return payoutFailed(c, err)
+		}
+	} else {
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := statemachine.Fire(tx, &escrow, model.Declined, t); err != nil {
+				return err
+			}
+			return declined(tx)
+		})
+		if err != nil {
+			return transitionFailed(c, err)
+		}
+	}
+
+	message := "Escrow declined"
+	if refunded {
+		message = "Escrow declined. Funds have been refunded to the buyer."
+	}
+	return c.JSON(fiber.Map{
+		"message":  message,
+		"status":   escrow.Status,
+		"refunded": refunded,
+	})
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"escrow_service/internal/model"
+	"escrow_service/internal/statemachine"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UpdateDeliverable lets either party tick a deliverable of the escrow's
+// conditions document on or off.
+func UpdateDeliverable(c fiber.Ctx) error {
+	type Request struct {
+		Done *bool `json:"done"`
+	}
+
+	escrowID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid escrow ID",
+		})
+	}
+	deliverableID, err := strconv.Atoi(c.Params("deliverableId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid deliverable ID",
+		})
+	}
+
+	userIDStr := c.Get("X-User-ID")
+	if userIDStr == "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Missing X-User-ID",
+		})
+	}
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	var req Request
+	if err := c.Bind().Body(&req); err != nil || req.Done == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "done is required",
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var escrow model.Escrow
+	var deliverable model.Deliverable
+	status, msg := fiber.StatusOK, ""
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Lock the row: the checklist is rewritten as a whole.
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&escrow, escrowID).Error; err != nil {
+			status, msg = fiber.StatusNotFound, "Escrow not found"
+			return err
+		}
+		actor, ok := statemachine.ActorOf(&escrow, uint(userID))
+		if !ok {
+			status, msg = fiber.StatusForbidden, "Access denied to this escrow"
+			return fmt.Errorf("user %d is not a participant", userID)
+		}
+		if statemachine.IsTerminal(escrow.Status) {
+			status, msg = fiber.StatusBadRequest, "Escrow is closed"
+			return fmt.Errorf("escrow %d is %s", escrow.ID, escrow.Status)
+		}
+		if escrow.ConditionsDoc == nil {
+			status, msg = fiber.StatusNotFound, "Escrow has no conditions document"
+			return fmt.Errorf("escrow %d has no conditions document", escrow.ID)
+		}
+		item, ok := escrow.ConditionsDoc.Deliverable(deliverableID)
+		if !ok {
+			status, msg = fiber.StatusNotFound, "Deliverable not found"
+			return fmt.Errorf("deliverable %d not found", deliverableID)
+		}
+
+		item.Done = *req.Done
+		item.DoneBy, item.DoneAt = nil, nil
+		if item.Done {
+			by, at := uint(userID), time.Now()
+			item.DoneBy, item.DoneAt = &by, &at
+		}
+		deliverable = *item
+
+		if err := tx.Model(&escrow).Update("conditions_doc", escrow.ConditionsDoc).Error; err != nil {
+			status, msg = fiber.StatusInternalServerError, "Failed to update deliverable"
+			return err
+		}
+		note := fmt.Sprintf("deliverable %d unchecked", item.ID)
+		if item.Done {
+			note = fmt.Sprintf("deliverable %d checked", item.ID)
+		}
+		if err := tx.Create(&model.EscrowEvent{
+			EscrowID:   escrow.ID,
+			Kind:       model.EventDeliverableUpdated,
+			ActorID:    uint(userID),
+			ActorRole:  string(actor),
+			FromStatus: escrow.Status,
+			ToStatus:   escrow.Status,
+			Source:     model.SourceHTTP,
+			Note:       note,
+		}).Error; err != nil {
+			status, msg = fiber.StatusInternalServerError, "Failed to update deliverable"
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		if msg == "" {
+			status, msg = fiber.StatusInternalServerError, "Failed to update deliverable"
+		}
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	return c.JSON(fiber.Map{
+		"deliverable":    deliverable,
+		"conditions_doc": escrow.ConditionsDoc,
+	})
+}
@@ -0,0 +1,339 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"escrow_service/internal/deadline"
+	"escrow_service/internal/model"
+	"escrow_service/internal/rabbitmq"
+	"escrow_service/internal/rbac"
+	"escrow_service/internal/statemachine"
+	"escrow_service/internal/storage"
+	"fmt"
+	"log"
+	"message_broker/rabbitmq/events"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxCarrierLength      = 100
+	maxTrackingLength     = 100
+	maxDeliveryNoteLength = 2000
+)
+
+var errAlreadyDelivered = errors.New("escrow already marked delivered")
+
+// loadDeliveryEscrow resolves the escrow named in the route and the caller.
+// Staff who are not a party are only let in when readOnly is set. On
+// failure it returns nil with the status and message to respond with.
+func loadDeliveryEscrow(c fiber.Ctx, readOnly bool) (*model.Escrow, uint, int, string) {
+	escrowID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return nil, 0, fiber.StatusBadRequest, "Invalid escrow ID"
+	}
+	userIDStr := c.Get("X-User-ID")
+	if userIDStr == "" {
+		return nil, 0, fiber.StatusForbidden, "Missing X-User-ID"
+	}
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return nil, 0, fiber.StatusBadRequest, "Invalid user ID"
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var escrow model.Escrow
+	if err := db.First(&escrow, escrowID).Error; err != nil {
+		return nil, 0, fiber.StatusNotFound, "Escrow not found"
+	}
+	if _, ok := statemachine.ActorOf(&escrow, uint(userID)); !ok && !(readOnly && rbac.RoleOf(c).IsStaff()) {
+		return nil, 0, fiber.StatusForbidden, "Access denied to this escrow"
+	}
+	return &escrow, uint(userID), 0, ""
+}
+
+// MarkDelivery lets the seller record that the goods were shipped or
+// delivered. The request is multipart (or a plain form) with the fields
+// status ("shipped" or "delivered"), carrier, tracking_number and note, and
+// a "proof" file part.
+//
+// Shipping requires a carrier and tracking number and moves the automatic
+// release out to the end of the transit window (see transitDeadline), so
+// goods that are never marked delivered still release eventually. Delivery
+// requires a proof file and
+// starts the buyer's inspection period over from now, so the buyer always
+// has the escrow's full inspection period to check the goods.
+func MarkDelivery(c fiber.Ctx) error {
+	escrow, userID, status, msg := loadDeliveryEscrow(c, false)
+	if escrow == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	if userID != escrow.SellerID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Only the seller can record deliveries",
+		})
+	}
+	if escrow.Status != model.Funded || !escrow.Active {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Escrow must be funded and accepted first",
+		})
+	}
+
+	delivery := model.Delivery{
+		EscrowID:       escrow.ID,
+		SellerID:       userID,
+		Status:         model.DeliveryStatus(strings.ToLower(strings.TrimSpace(c.FormValue("status")))),
+		Carrier:        strings.TrimSpace(c.FormValue("carrier")),
+		TrackingNumber: strings.TrimSpace(c.FormValue("tracking_number")),
+		Note:           strings.TrimSpace(c.FormValue("note")),
+	}
+	if delivery.Status != model.DeliveryShipped && delivery.Status != model.DeliveryDelivered {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "status must be shipped or delivered",
+		})
+	}
+	if len(delivery.Carrier) > maxCarrierLength || len(delivery.TrackingNumber) > maxTrackingLength {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("Carrier and tracking number must be at most %d characters", maxCarrierLength),
+		})
+	}
+	if len(delivery.Note) > maxDeliveryNoteLength {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("Note must be at most %d characters", maxDeliveryNoteLength),
+		})
+	}
+	if escrow.DeliveredAt != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Escrow is already marked delivered",
+		})
+	}
+	if delivery.Status == model.DeliveryShipped && (delivery.Carrier == "" || delivery.TrackingNumber == "") {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "carrier and tracking_number are required when shipping",
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	if delivery.Status == model.DeliveryDelivered && delivery.TrackingNumber == "" {
+		// Deliveries usually follow a shipment; keep its tracking details
+		// on the record the arbitrator looks at.
+		var shipment model.Delivery
+		if err := db.Where("escrow_id = ? AND status = ?", escrow.ID, model.DeliveryShipped).
+			Order("created_at DESC").
+			First(&shipment).Error; err == nil {
+			if delivery.Carrier == "" {
+				delivery.Carrier = shipment.Carrier
+			}
+			delivery.TrackingNumber = shipment.TrackingNumber
+		}
+	}
+
+	header, err := c.FormFile("proof")
+	if err != nil && delivery.Status == model.DeliveryDelivered {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "proof is required when marking delivered",
+		})
+	}
+	if err == nil {
+		content, contentType, status, msg := readEvidenceFile(header, "Proof")
+		if content == nil {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+		sum := sha256.Sum256(content)
+		hash := hex.EncodeToString(sum[:])
+		key := fmt.Sprintf("escrows/%d/deliveries/%s", escrow.ID, hash)
+		store := c.Locals("storage").(storage.Store)
+		if err := store.Put(c.Context(), key, bytes.NewReader(content)); err != nil {
+			log.Printf("Failed to store delivery proof for escrow %d: %v", escrow.ID, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to store proof",
+			})
+		}
+		delivery.ProofFileName = sanitizeFileName(header.Filename)
+		delivery.ProofContentType = contentType
+		delivery.ProofSize = int64(len(content))
+		delivery.ProofSHA256 = hash
+		delivery.ProofKey = key
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Lock the row so a concurrent confirmation, dispute or second
+		// delivery cannot interleave with the deadline change.
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(escrow, escrow.ID).Error; err != nil {
+			return err
+		}
+		if escrow.Status != model.Funded {
+			return statemachine.ErrStaleStatus
+		}
+		if escrow.DeliveredAt != nil {
+			return errAlreadyDelivered
+		}
+
+		now := time.Now()
+		updates := map[string]any{}
+		kind, eventType := model.EventShipped, "escrow.shipped"
+		if delivery.Status == model.DeliveryShipped {
+			if escrow.ShippedAt == nil {
+				escrow.ShippedAt = &now
+				updates["shipped_at"] = now
+			}
+			transitUntil := transitDeadline(escrow, now)
+			escrow.InspectionDeadline = &transitUntil
+			delivery.InspectionDeadline = &transitUntil
+			updates["inspection_deadline"] = transitUntil
+		} else {
+			kind, eventType = model.EventDelivered, "escrow.delivered"
+			period := time.Duration(escrow.InspectionPeriodHours) * time.Hour
+			if period <= 0 {
+				period = deadline.InspectionWindow()
+			}
+			inspectUntil := now.Add(period)
+			// Never cut short a deadline the buyer already has.
+			if escrow.InspectionDeadline != nil && escrow.InspectionDeadline.After(inspectUntil) {
+				inspectUntil = *escrow.InspectionDeadline
+			}
+			escrow.DeliveredAt = &now
+			escrow.InspectionDeadline = &inspectUntil
+			delivery.InspectionDeadline = &inspectUntil
+			updates["delivered_at"] = now
+			updates["inspection_deadline"] = inspectUntil
+		}
+		if err := tx.Model(&model.Escrow{}).Where("id = ?", escrow.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&delivery).Error; err != nil {
+			return err
+		}
+
+		note := string(delivery.Status)
+		if delivery.Carrier != "" || delivery.TrackingNumber != "" {
+			note = fmt.Sprintf("%s via %s, tracking %s", delivery.Status, delivery.Carrier, delivery.TrackingNumber)
+		}
+		if delivery.ProofSHA256 != "" {
+			note += fmt.Sprintf(" (proof sha256 %s)", delivery.ProofSHA256)
+		}
+		if err := tx.Create(&model.EscrowEvent{
+			EscrowID:   escrow.ID,
+			Kind:       kind,
+			ActorID:    userID,
+			ActorRole:  string(statemachine.Seller),
+			FromStatus: escrow.Status,
+			ToStatus:   escrow.Status,
+			Source:     model.SourceHTTP,
+			Note:       note,
+		}).Error; err != nil {
+			return err
+		}
+		return rabbitmq.NewProducer(tx).PublishEscrowDelivery(events.NewEscrowDeliveryEvent(
+			eventType,
+			uint64(escrow.ID),
+			uint64(delivery.ID),
+			uint32(escrow.BuyerID),
+			uint32(escrow.SellerID),
+			delivery.Carrier,
+			delivery.TrackingNumber,
+			delivery.InspectionDeadline,
+		))
+	})
+	if errors.Is(err, statemachine.ErrStaleStatus) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Escrow changed, please retry"})
+	}
+	if errors.Is(err, errAlreadyDelivered) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Escrow is already marked delivered"})
+	}
+	if err != nil {
+		log.Printf("Failed to record delivery for escrow %d: %v", escrow.ID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to record delivery",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"delivery":            delivery,
+		"inspection_deadline": escrow.InspectionDeadline,
+	})
+}
+
+// transitDeadline is when an escrow whose goods were shipped at now is
+// released if the seller never marks them delivered: the end of the transit
+// window, or the delivery deadline of the conditions plus the inspection
+// period when that is later. A deadline the buyer already has is never cut
+// short.
+func transitDeadline(escrow *model.Escrow, now time.Time) time.Time {
+	until := now.Add(deadline.TransitWindow())
+	if doc := escrow.ConditionsDoc; doc != nil && doc.DeliveryDeadline != nil {
+		period := time.Duration(escrow.InspectionPeriodHours) * time.Hour
+		if period <= 0 {
+			period = deadline.InspectionWindow()
+		}
+		if deliverBy := doc.DeliveryDeadline.Add(period); deliverBy.After(until) {
+			until = deliverBy
+		}
+	}
+	if escrow.InspectionDeadline != nil && escrow.InspectionDeadline.After(until) {
+		until = *escrow.InspectionDeadline
+	}
+	return until
+}
+
+// GetDeliveries lists the shipping and delivery updates of an escrow,
+// oldest first. Support staff, arbitrators and admins can read them too.
+func GetDeliveries(c fiber.Ctx) error {
+	escrow, _, status, msg := loadDeliveryEscrow(c, true)
+	if escrow == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var deliveries []model.Delivery
+	if err := db.Where("escrow_id = ?", escrow.ID).
+		Order("created_at").
+		Find(&deliveries).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch deliveries",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"deliveries":          deliveries,
+		"shipped_at":          escrow.ShippedAt,
+		"delivered_at":        escrow.DeliveredAt,
+		"inspection_deadline": escrow.InspectionDeadline,
+	})
+}
+
+// DownloadDeliveryProof streams the proof file of a delivery update after
+// checking that its content still matches the hash recorded at upload.
+func DownloadDeliveryProof(c fiber.Ctx) error {
+	escrow, _, status, msg := loadDeliveryEscrow(c, true)
+	if escrow == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	deliveryID, err := strconv.ParseUint(c.Params("deliveryId"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid delivery ID",
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var delivery model.Delivery
+	if err := db.Where("id = ? AND escrow_id = ?", deliveryID, escrow.ID).First(&delivery).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Delivery not found",
+		})
+	}
+	if delivery.ProofKey == "" {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Delivery has no proof attached",
+		})
+	}
+	return sendEvidenceFile(c, delivery.ProofKey, delivery.ProofSHA256, delivery.ProofContentType, delivery.ProofFileName)
+}
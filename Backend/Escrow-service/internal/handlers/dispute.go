@@ -1,16 +1,52 @@
 package handlers
 
 import (
+	"errors"
 	"escrow_service/internal/model"
 	"escrow_service/internal/rabbitmq"
+	"escrow_service/internal/rbac"
+	"escrow_service/internal/statemachine"
 	"fmt"
-	"log"
+	"message_broker/rabbitmq/events"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/gorm"
 )
 
+const (
+	maxDisputeReasonLength    = 2000
+	maxDisputeStatementLength = 5000
+)
+
+type disputeRequest struct {
+	Reason   string                `json:"reason"`
+	Category model.DisputeCategory `json:"category"`
+}
+
+func (r *disputeRequest) validate() error {
+	r.Reason = strings.TrimSpace(r.Reason)
+	if r.Reason == "" {
+		return errors.New("A reason is required to open a dispute")
+	}
+	if len(r.Reason) > maxDisputeReasonLength {
+		return fmt.Errorf("Reason must be at most %d characters", maxDisputeReasonLength)
+	}
+	if r.Category == "" {
+		r.Category = model.DisputeOther
+	}
+	for _, category := range model.DisputeCategories {
+		if r.Category == category {
+			return nil
+		}
+	}
+	return fmt.Errorf("Unknown dispute category %q", r.Category)
+}
+
+// DisputeEscrow opens a dispute on an active, funded escrow. The reason and
+// category are stored on a Dispute record alongside the status change.
 func DisputeEscrow(c fiber.Ctx) error {
 	idParam := c.Params("id")
 	escrowID, err := strconv.ParseUint(idParam, 10, 64)
@@ -33,6 +69,18 @@ func DisputeEscrow(c fiber.Ctx) error {
 		})
 	}
 
+	var req disputeRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request",
+		})
+	}
+	if err := req.validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	db := c.Locals("db").(*gorm.DB)
 	var escrow model.Escrow
 
@@ -43,8 +91,8 @@ func DisputeEscrow(c fiber.Ctx) error {
 		})
 	}
 
-	
-	if uint(userID) != escrow.BuyerID && uint(userID) != escrow.SellerID {
+	actor, ok := statemachine.ActorOf(&escrow, uint(userID))
+	if !ok {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 			"error": "Only buyer or seller can dispute",
 		})
@@ -57,37 +105,255 @@ func DisputeEscrow(c fiber.Ctx) error {
 		})
 	}
 
-	if escrow.Status == model.Released{
-		return  c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":"Escrow is Released,Can't issue a dispute",
+	if err := statemachine.Check(escrow.Status, model.Disputed, actor); err != nil {
+		return transitionFailed(c, err)
+	}
+	dispute := model.Dispute{
+		EscrowID:     escrow.ID,
+		RaisedBy:     uint(userID),
+		RaisedByRole: string(actor),
+		Category:     req.Category,
+		Reason:       req.Reason,
+		Status:       model.DisputeOpen,
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := statemachine.Fire(tx, &escrow, model.Disputed, statemachine.Trigger{
+			Actor:  actor,
+			UserID: uint(userID),
+			Source: model.SourceHTTP,
+			Note:   string(req.Category),
+		}); err != nil {
+			return err
+		}
+		if err := tx.Create(&dispute).Error; err != nil {
+			return err
+		}
+		return rabbitmq.NewProducer(tx).PublishEscrowDisputed(events.NewEscrowDisputedEvent(
+			escrowID,
+			uint32(userID),
+			uint64(dispute.ID),
+			string(dispute.Category),
+			dispute.Reason,
+		))
+	})
+	if err != nil {
+		return transitionFailed(c, err)
+	}
+
+    return c.JSON(fiber.Map{
+		"message":     "Dispute raised successfully",
+		"status":      escrow.Status,
+		"escrow_id":   escrow.ID,
+		"dispute":     dispute,
+	})
+}
+
+// GetDisputes lists every dispute raised on an escrow with its statements,
+// newest first. Support staff, arbitrators and admins can read them too.
+func GetDisputes(c fiber.Ctx) error {
+	escrowID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid escrow ID",
+		})
+	}
+
+	userIDStr := c.Get("X-User-ID")
+	if userIDStr == "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Missing X-User-ID",
 		})
 	}
-	if escrow.Status == "Disputed" {
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Escrow is already resolved or disputed",
+			"error": "Invalid user ID",
 		})
 	}
-	var raisedBy string
-    if userID == uint64(escrow.BuyerID){
-		raisedBy = fmt.Sprintf("Buyer-Id:%d",userID)
-	}else{
-		raisedBy = fmt.Sprintf("Seller-Id:%d",userID)
+
+	db := c.Locals("db").(*gorm.DB)
+	var escrow model.Escrow
+	if err := db.First(&escrow, escrowID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Escrow not found",
+		})
+	}
+	if _, ok := statemachine.ActorOf(&escrow, uint(userID)); !ok && !rbac.RoleOf(c).IsStaff() {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access denied to this escrow",
+		})
+	}
+
+	var disputes []model.Dispute
+	if err := db.Preload("Statements", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at")
+	}).Where("escrow_id = ?", escrow.ID).
+		Order("created_at DESC").
+		Find(&disputes).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch disputes",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"disputes": disputes,
+		"total":    len(disputes),
+	})
+}
+
+// AddDisputeStatement lets either party add a written statement to an open
+// dispute.
+func AddDisputeStatement(c fiber.Ctx) error {
+	type Request struct {
+		Body string `json:"body"`
+	}
+
+	target, status, msg := loadDispute(c, false)
+	if target == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	escrow, dispute, actor, userID := target.escrow, target.dispute, target.actor, target.userID
+
+	var req Request
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Body == "" || len(req.Body) > maxDisputeStatementLength {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("Statement must be between 1 and %d characters", maxDisputeStatementLength),
+		})
+	}
+	if dispute.Status != model.DisputeOpen {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dispute is no longer open",
+		})
 	}
-	
-	escrow.Status = model.Disputed
-	db.Save(&escrow)
 
-	producer := rabbitmq.NewProducer()
-	err = producer.PublishEscrowDisputed(escrowID, uint32(userID))
+	statement := model.DisputeStatement{
+		DisputeID:  dispute.ID,
+		AuthorID:   userID,
+		AuthorRole: string(actor),
+		Body:       req.Body,
+	}
+	db := c.Locals("db").(*gorm.DB)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&statement).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.EscrowEvent{
+			EscrowID:   escrow.ID,
+			Kind:       model.EventDisputeStatement,
+			ActorID:    userID,
+			ActorRole:  string(actor),
+			FromStatus: escrow.Status,
+			ToStatus:   escrow.Status,
+			Source:     model.SourceHTTP,
+			Note:       fmt.Sprintf("dispute %d", dispute.ID),
+		}).Error
+	})
 	if err != nil {
-		log.Printf("Failed to publish escrow.disputed: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to add statement",
+		})
 	}
 
-    return c.JSON(fiber.Map{
-		"message":     "Dispute raised successfully",
-		"status":      "Disputed",
-		"escrow_id":   escrow.ID,
-		"RaisedBy":    raisedBy,
-		
+	return c.Status(fiber.StatusCreated).JSON(statement)
+}
+
+// WithdrawDispute lets the party who raised an open dispute withdraw it. The
+// escrow returns to Funded and its inspection deadline is pushed back by the
+// time spent in dispute.
+func WithdrawDispute(c fiber.Ctx) error {
+	target, status, msg := loadDispute(c, false)
+	if target == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	escrow, dispute, actor, userID := target.escrow, target.dispute, target.actor, target.userID
+	if dispute.Status != model.DisputeOpen {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dispute is no longer open",
+		})
+	}
+	if dispute.RaisedBy != userID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Only the party who raised the dispute can withdraw it",
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := statemachine.Fire(tx, escrow, model.Funded, statemachine.Trigger{
+			Actor:  actor,
+			UserID: userID,
+			Source: model.SourceHTTP,
+			Note:   fmt.Sprintf("dispute %d withdrawn", dispute.ID),
+		}); err != nil {
+			return err
+		}
+		if escrow.InspectionDeadline != nil {
+			extended := escrow.InspectionDeadline.Add(time.Since(dispute.CreatedAt))
+			escrow.InspectionDeadline = &extended
+			if err := tx.Model(escrow).Update("inspection_deadline", extended).Error; err != nil {
+				return err
+			}
+		}
+		return rabbitmq.NewProducer(tx).PublishDisputeWithdrawn(events.NewDisputeWithdrawnEvent(uint64(escrow.ID), uint32(userID), uint64(dispute.ID)))
+	})
+	if err != nil {
+		return transitionFailed(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message":             "Dispute withdrawn",
+		"status":              escrow.Status,
+		"inspection_deadline": escrow.InspectionDeadline,
 	})
-}
\ No newline at end of file
+}
+
+type disputeTarget struct {
+	escrow  *model.Escrow
+	dispute *model.Dispute
+	actor   statemachine.Actor
+	userID  uint
+}
+
+// loadDispute resolves the escrow and dispute named in the route and the
+// caller's role on the escrow. Staff who are not a party are only let in
+// when readOnly is set; their actor is left empty. On failure it returns nil
+// with the status and message to respond with.
+func loadDispute(c fiber.Ctx, readOnly bool) (*disputeTarget, int, string) {
+	escrowID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return nil, fiber.StatusBadRequest, "Invalid escrow ID"
+	}
+	disputeID, err := strconv.ParseUint(c.Params("disputeId"), 10, 64)
+	if err != nil {
+		return nil, fiber.StatusBadRequest, "Invalid dispute ID"
+	}
+
+	userIDStr := c.Get("X-User-ID")
+	if userIDStr == "" {
+		return nil, fiber.StatusForbidden, "Missing X-User-ID"
+	}
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return nil, fiber.StatusBadRequest, "Invalid user ID"
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var escrow model.Escrow
+	if err := db.First(&escrow, escrowID).Error; err != nil {
+		return nil, fiber.StatusNotFound, "Escrow not found"
+	}
+	actor, ok := statemachine.ActorOf(&escrow, uint(userID))
+	if !ok && !(readOnly && rbac.RoleOf(c).IsStaff()) {
+		return nil, fiber.StatusForbidden, "Access denied to this escrow"
+	}
+
+	var dispute model.Dispute
+	if err := db.Where("id = ? AND escrow_id = ?", disputeID, escrow.ID).First(&dispute).Error; err != nil {
+		return nil, fiber.StatusNotFound, "Dispute not found"
+	}
+	return &disputeTarget{escrow: &escrow, dispute: &dispute, actor: actor, userID: uint(userID)}, 0, ""
+}
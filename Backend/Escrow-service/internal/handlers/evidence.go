@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"escrow_service/internal/model"
+	"escrow_service/internal/storage"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+const maxEvidenceSize = 10 << 20
+
+// evidenceTypes are the content types accepted as evidence, as detected
+// from the file itself rather than trusted from the client.
+var evidenceTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+	"text/plain":      true,
+}
+
+// UploadEvidence attaches a file to an open dispute. The request is
+// multipart with a "file" part and an optional "description" field.
+func UploadEvidence(c fiber.Ctx) error {
+	target, status, msg := loadDispute(c, false)
+	if target == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	escrow, dispute := target.escrow, target.dispute
+	if dispute.Status != model.DisputeOpen {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dispute is no longer open",
+		})
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "file is required",
+		})
+	}
+	content, contentType, status, msg := readEvidenceFile(header, "Evidence")
+	if content == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+	// Content-addressed keys: a stored object can never be replaced by a
+	// different file without its key, and so its hash, changing too.
+	key := fmt.Sprintf("escrows/%d/disputes/%d/%s", escrow.ID, dispute.ID, hash)
+
+	store := c.Locals("storage").(storage.Store)
+	if err := store.Put(c.Context(), key, bytes.NewReader(content)); err != nil {
+		log.Printf("Failed to store evidence for dispute %d: %v", dispute.ID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to store evidence",
+		})
+	}
+
+	evidence := model.Evidence{
+		EscrowID:     escrow.ID,
+		DisputeID:    dispute.ID,
+		UploadedBy:   target.userID,
+		UploaderRole: string(target.actor),
+		FileName:     sanitizeFileName(header.Filename),
+		ContentType:  contentType,
+		Size:         int64(len(content)),
+		SHA256:       hash,
+		StorageKey:   key,
+		Description:  strings.TrimSpace(c.FormValue("description")),
+	}
+	db := c.Locals("db").(*gorm.DB)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&evidence).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.EscrowEvent{
+			EscrowID:   escrow.ID,
+			Kind:       model.EventEvidenceAdded,
+			ActorID:    target.userID,
+			ActorRole:  string(target.actor),
+			FromStatus: escrow.Status,
+			ToStatus:   escrow.Status,
+			Source:     model.SourceHTTP,
+			Note:       fmt.Sprintf("dispute %d: %s (sha256 %s)", dispute.ID, evidence.FileName, hash),
+		}).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save evidence",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(evidence)
+}
+
+// GetEvidence lists the evidence attached to a dispute.
+func GetEvidence(c fiber.Ctx) error {
+	target, status, msg := loadDispute(c, true)
+	if target == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var evidence []model.Evidence
+	if err := db.Where("dispute_id = ?", target.dispute.ID).
+		Order("created_at").
+		Find(&evidence).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch evidence",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"evidence": evidence,
+		"total":    len(evidence),
+	})
+}
+
+// DownloadEvidence streams an evidence file after checking that its content
+// still matches the hash recorded at upload.
+func DownloadEvidence(c fiber.Ctx) error {
+	target, status, msg := loadDispute(c, true)
+	if target == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	evidenceID, err := strconv.ParseUint(c.Params("evidenceId"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid evidence ID",
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var evidence model.Evidence
+	if err := db.Where("id = ? AND dispute_id = ?", evidenceID, target.dispute.ID).First(&evidence).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Evidence not found",
+		})
+	}
+
+	return sendEvidenceFile(c, evidence.StorageKey, evidence.SHA256, evidence.ContentType, evidence.FileName)
+}
+
+// sendEvidenceFile streams a file from the evidence store after checking
+// that its content still matches the hash recorded at upload.
+func sendEvidenceFile(c fiber.Ctx, key, hash, contentType, fileName string) error {
+	store := c.Locals("storage").(storage.Store)
+	r, err := store.Open(c.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Evidence file is missing",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to read evidence",
+		})
+	}
+	defer r.Close()
+	content, err := io.ReadAll(io.LimitReader(r, maxEvidenceSize+1))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to read evidence",
+		})
+	}
+
+	sum := sha256.Sum256(content)
+	if hex.EncodeToString(sum[:]) != hash {
+		log.Printf("Evidence object %s failed integrity check: stored object does not match sha256 %s", key, hash)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Evidence failed integrity check",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	c.Set("X-Content-SHA256", hash)
+	return c.Send(content)
+}
+
+// readEvidenceFile reads an uploaded file and checks its size and type
+// against the limits for evidence. label names the file in error messages.
+// On failure it returns nil with the status and message to respond with.
+func readEvidenceFile(header *multipart.FileHeader, label string) ([]byte, string, int, string) {
+	if header.Size > maxEvidenceSize {
+		return nil, "", fiber.StatusRequestEntityTooLarge,
+			fmt.Sprintf("%s files must be at most %d MB", label, maxEvidenceSize>>20)
+	}
+	f, err := header.Open()
+	if err != nil {
+		return nil, "", fiber.StatusBadRequest, "Failed to read file"
+	}
+	defer f.Close()
+	content, err := io.ReadAll(io.LimitReader(f, maxEvidenceSize+1))
+	if err != nil {
+		return nil, "", fiber.StatusBadRequest, "Failed to read file"
+	}
+	if len(content) == 0 || len(content) > maxEvidenceSize {
+		return nil, "", fiber.StatusBadRequest,
+			fmt.Sprintf("%s files must be between 1 byte and %d MB", label, maxEvidenceSize>>20)
+	}
+
+	contentType, _, _ := strings.Cut(http.DetectContentType(content), ";")
+	if !evidenceTypes[contentType] {
+		return nil, "", fiber.StatusUnsupportedMediaType,
+			label + " must be an image, a PDF or plain text"
+	}
+	return content, contentType, 0, ""
+}
+
+// sanitizeFileName keeps only the base name of an uploaded file and drops
+// characters that would break the Content-Disposition header.
+func sanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r == '"' || r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == "/" {
+		return "evidence"
+	}
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	return name
+}
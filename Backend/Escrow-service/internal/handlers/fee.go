@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"errors"
+	"escrow_service/internal/fee"
+	"escrow_service/internal/model"
+	"shared/money"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// QuoteFee shows what a new escrow of ?amount= (in ?currency=, ETB by
+// default) would cost each party under the active fee schedule.
+func QuoteFee(c fiber.Ctx) error {
+	amount, err := money.Parse(c.Query("amount"))
+	if err != nil || amount <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Amount must be greater than zero",
+		})
+	}
+	currency, err := model.ParseCurrency(c.Query("currency"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	quote, err := fee.QuoteFor(db, amount, currency)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to compute platform fee",
+		})
+	}
+	return c.JSON(quote)
+}
+
+// ListFeeSchedules returns every fee schedule by currency, the active one
+// first.
+func ListFeeSchedules(c fiber.Ctx) error {
+	db := c.Locals("db").(*gorm.DB)
+	var schedules []model.FeeSchedule
+	if err := db.Order("currency, active DESC, created_at DESC").Find(&schedules).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch fee schedules",
+		})
+	}
+	return c.JSON(fiber.Map{"schedules": schedules})
+}
+
+type feeScheduleRequest struct {
+	Name        string         `json:"name"`
+	Currency    model.Currency `json:"currency"`
+	Percent     float64        `json:"percent"`
+	FixedAmount money.Amount   `json:"fixed_amount"`
+	MinFee      money.Amount   `json:"min_fee"`
+	MaxFee      money.Amount   `json:"max_fee"`
+	Payer       model.FeePayer `json:"payer"`
+	Active      bool           `json:"active"`
+}
+
+func (r *feeScheduleRequest) apply(s *model.FeeSchedule) {
+	s.Name = r.Name
+	s.Currency = r.Currency
+	s.Percent = r.Percent
+	s.FixedAmount = r.FixedAmount
+	s.MinFee = r.MinFee
+	s.MaxFee = r.MaxFee
+	s.Payer = r.Payer
+	s.Active = r.Active
+}
+
+var errScheduleNameTaken = errors.New("A fee schedule with this name already exists")
+
+// saveFeeSchedule stores the schedule. Activating it deactivates every
+// other schedule of its currency so new escrows are only ever charged by
+// one.
+func saveFeeSchedule(db *gorm.DB, s *model.FeeSchedule) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var taken int64
+		if err := tx.Model(&model.FeeSchedule{}).
+			Where("name = ? AND id <> ?", s.Name, s.ID).
+			Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return errScheduleNameTaken
+		}
+		if s.Active {
+			if err := tx.Model(&model.FeeSchedule{}).
+				Where("active = ? AND currency = ? AND id <> ?", true, s.Currency, s.ID).
+				Update("active", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(s).Error
+	})
+}
+
+// CreateFeeSchedule adds a fee schedule. It only applies to new escrows
+// once it is active.
+func CreateFeeSchedule(c fiber.Ctx) error {
+	var req feeScheduleRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	var schedule model.FeeSchedule
+	req.apply(&schedule)
+	if err := fee.Validate(&schedule); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	if err := saveFeeSchedule(db, &schedule); err != nil {
+		if errors.Is(err, errScheduleNameTaken) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create fee schedule",
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(schedule)
+}
+
+// UpdateFeeSchedule replaces a schedule's terms. Escrows already created
+// under it keep the fees they were quoted.
+func UpdateFeeSchedule(c fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid fee schedule ID",
+		})
+	}
+	var req feeScheduleRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var schedule model.FeeSchedule
+	if err := db.First(&schedule, uint(id)).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Fee schedule not found",
+		})
+	}
+	req.apply(&schedule)
+	if err := fee.Validate(&schedule); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err := saveFeeSchedule(db, &schedule); err != nil {
+		if errors.Is(err, errScheduleNameTaken) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update fee schedule",
+		})
+	}
+	return c.JSON(schedule)
+}
+
+// DeleteFeeSchedule removes a schedule. Deleting the active one means new
+// escrows are created without a fee.
+func DeleteFeeSchedule(c fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid fee schedule ID",
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	res := db.Model(&model.FeeSchedule{}).Where("id = ?", uint(id)).Update("active", false)
+	if res.Error == nil && res.RowsAffected > 0 {
+		res = db.Delete(&model.FeeSchedule{}, uint(id))
+	}
+	if res.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete fee schedule",
+		})
+	}
+	if res.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Fee schedule not found",
+		})
+	}
+	return c.JSON(fiber.Map{"message": "Fee schedule deleted"})
+}
+
+// GetFeeLedger lists fee ledger entries, newest first, with the net fee
+// revenue per currency of every entry matching the filters. It can be narrowed with
+// ?escrow_id= and ?payer=.
+func GetFeeLedger(c fiber.Ctx) error {
+	page := 1
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p >= 1 {
+		page = p
+	}
+	pageSize := 50
+	if ps, err := strconv.Atoi(c.Query("page_size")); err == nil && ps >= 1 && ps <= 200 {
+		pageSize = ps
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	query := db.Model(&model.FeeLedgerEntry{})
+	if raw := c.Query("escrow_id"); raw != "" {
+		escrowID, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid escrow ID",
+			})
+		}
+		query = query.Where("escrow_id = ?", escrowID)
+	}
+	if payer := c.Query("payer"); payer != "" {
+		query = query.Where("payer = ?", payer)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to count fee ledger entries",
+		})
+	}
+	var nets []struct {
+		Currency string
+		Net      money.Amount
+	}
+	if err := query.Session(&gorm.Session{}).
+		Select("currency, COALESCE(SUM(amount), 0) AS net").
+		Group("currency").
+		Scan(&nets).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to summarize fee ledger",
+		})
+	}
+	netFees := make(map[string]money.Amount, len(nets))
+	for _, n := range nets {
+		netFees[n.Currency] = n.Net
+	}
+	var entries []model.FeeLedgerEntry
+	if err := query.Order("created_at DESC, id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&entries).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch fee ledger",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"entries":   entries,
+		"total":     total,
+		"net_fees":  netFees,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"escrow_service/internal/model"
+	"escrow_service/internal/rbac"
 	"strconv"
 
 	"github.com/gofiber/fiber/v3"
@@ -19,7 +20,9 @@ func GetEscrow(c fiber.Ctx) error {
 
 	db := c.Locals("db").(*gorm.DB)
 	var escrow model.Escrow
-	if err := db.First(&escrow, uint(escrowID)).Error; err != nil {
+	if err := db.Preload("Milestones", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sequence ASC")
+	}).First(&escrow, uint(escrowID)).Error; err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Escrow not found",
 		})
@@ -39,7 +42,8 @@ func GetEscrow(c fiber.Ctx) error {
 		})
 	}
 
-	if uint32(escrow.BuyerID) != uint32(userID) && uint32(escrow.SellerID) != uint32(userID) {
+	if uint32(escrow.BuyerID) != uint32(userID) && uint32(escrow.SellerID) != uint32(userID) &&
+		!rbac.RoleOf(c).IsStaff() {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 			"error": "Access denied to this escrow",
 		})
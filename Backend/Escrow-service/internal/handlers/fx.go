@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"escrow_service/internal/fx"
+	"escrow_service/internal/model"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// ListFxRates returns the FX rate table used to report totals in another
+// currency.
+func ListFxRates(c fiber.Ctx) error {
+	db := c.Locals("db").(*gorm.DB)
+	var rates []model.FxRate
+	if err := db.Order("base, quote").Find(&rates).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch exchange rates",
+		})
+	}
+	return c.JSON(fiber.Map{"rates": rates})
+}
+
+// SetFxRate adds or replaces the rate of a currency pair. Only one
+// direction needs to be maintained; the inverse is derived.
+func SetFxRate(c fiber.Ctx) error {
+	userID, err := strconv.ParseUint(c.Get("X-User-ID"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	var req struct {
+		Base  string  `json:"base"`
+		Quote string  `json:"quote"`
+		Rate  float64 `json:"rate"`
+	}
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if req.Base == "" || req.Quote == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "base and quote are required",
+		})
+	}
+	base, err := model.ParseCurrency(req.Base)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	quote, err := model.ParseCurrency(req.Quote)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	rate, err := fx.Set(db, base, quote, req.Rate, uint(userID))
+	if errors.Is(err, fx.ErrInvalidRate) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save exchange rate",
+		})
+	}
+	return c.JSON(rate)
+}
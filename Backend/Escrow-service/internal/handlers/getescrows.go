@@ -1,19 +1,35 @@
 package handlers
 
 import (
+	"errors"
+	"escrow_service/internal/listing"
 	"escrow_service/internal/model"
+	"escrow_service/internal/statemachine"
+	"fmt"
+	"shared/money"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/gorm"
 )
 
-type EscrowSummary struct {
-	Total      int `json:"total"`
-	Active     int `json:"active"`
-	Completed  int `json:"completed"`
-}
-
+// GetUserEscrows lists the caller's escrows a page at a time. Query
+// parameters:
+//
+//	status           comma-separated statuses
+//	role             buyer or seller
+//	counterparty_id  the other party
+//	min_amount, max_amount
+//	created_from, created_to  RFC 3339 timestamps or YYYY-MM-DD dates
+//	active           true or false
+//	sort             created_at (default), updated_at or amount
+//	order            desc (default) or asc
+//	limit, cursor    page size and the next_cursor of the previous page
+//	summary_currency currency the summary total is reported in, ETB by default
+//
+// The summary covers every escrow matching the filters, not just the page.
 func GetUserEscrows(c fiber.Ctx) error {
 	userIDStr := c.Get("X-User-ID")
 	if userIDStr == "" {
@@ -29,57 +45,147 @@ func GetUserEscrows(c fiber.Ctx) error {
 		})
 	}
 
-	db := c.Locals("db").(*gorm.DB)
-	var escrows []model.Escrow
-
-	if userID == 2 {
-		if err := db.Order("created_at DESC").
-		Find(&escrows).Error; err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to fetch escrows",
+	filter, err := parseListingFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	filter.UserID = uint(userID)
+	if err := filter.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	sort, err := listing.ParseSort(c.Query("sort"), c.Query("order"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	summaryCurrency, err := model.ParseCurrency(c.Query("summary_currency"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	limit := listing.DefaultLimit
+	if l := c.Query("limit"); l != "" {
+		if limit, err = strconv.Atoi(l); err != nil || limit < 1 || limit > listing.MaxLimit {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": fmt.Sprintf("limit must be between 1 and %d", listing.MaxLimit),
 			})
 		}
-	}else{
+	}
 
-		if err := db.Where("buyer_id = ? OR seller_id = ?", userID, userID).
-		Order("created_at DESC").
-		Find(&escrows).Error; err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to fetch escrows",
-			})
-		}
+	db := c.Locals("db").(*gorm.DB)
+	page, err := listing.List(db, filter, sort, limit, c.Query("cursor"))
+	if errors.Is(err, listing.ErrInvalidCursor) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid cursor"})
 	}
-	
-	summary := EscrowSummary{}
-	for _, e := range escrows {
-		summary.Total++
-		if e.Active {
-			summary.Active++
-		}
-		if e.Status == model.Released {
-			summary.Completed++
-		}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch escrows",
+		})
+	}
+	summary, err := listing.Summarize(db, filter, summaryCurrency)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to summarize escrows",
+		})
 	}
 
-
-	var results []fiber.Map
-	for _, e := range escrows {
+	results := make([]fiber.Map, 0, len(page.Escrows))
+	for _, e := range page.Escrows {
 		results = append(results, fiber.Map{
-			"id":                    e.ID,
-			"buyer_id":              e.BuyerID,
-			"seller_id":             e.SellerID,
-			"amount":                e.Amount,
-			"status":                e.Status,
-			"conditions":            e.Conditions,
-			"blockchain_tx_hash":    e.BlockchainTxHash,
-			"blockchain_escrow_id":  e.BlockchainEscrowID,
-			"active":                e.Active,
-			"created_at":            e.CreatedAt,
+			"id":                   e.ID,
+			"buyer_id":             e.BuyerID,
+			"seller_id":            e.SellerID,
+			"amount":               e.Amount,
+			"currency":             e.Currency,
+			"buyer_fee":            e.BuyerFee,
+			"seller_fee":           e.SellerFee,
+			"status":               e.Status,
+			"conditions":           e.Conditions,
+			"blockchain_tx_hash":   e.BlockchainTxHash,
+			"blockchain_escrow_id": e.BlockchainEscrowID,
+			"active":               e.Active,
+			"created_at":           e.CreatedAt,
+			"updated_at":           e.UpdatedAt,
 		})
 	}
 
 	return c.JSON(fiber.Map{
-		"escrows": results,
-		"summary": summary,
+		"escrows":     results,
+		"summary":     summary,
+		"next_cursor": page.NextCursor,
+		"has_more":    page.NextCursor != "",
 	})
-}
\ No newline at end of file
+}
+
+// parseListingFilter reads the filter query parameters shared by escrow
+// listings. The caller sets UserID.
+func parseListingFilter(c fiber.Ctx) (listing.Filter, error) {
+	var f listing.Filter
+
+	if raw := c.Query("status"); raw != "" {
+		for _, s := range strings.Split(raw, ",") {
+			status, err := statemachine.Parse(strings.TrimSpace(s))
+			if err != nil {
+				return f, fmt.Errorf("Unknown status %q", s)
+			}
+			f.Statuses = append(f.Statuses, status)
+		}
+	}
+
+	f.Role = c.Query("role")
+
+	if raw := c.Query("counterparty_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return f, errors.New("Invalid counterparty_id")
+		}
+		f.CounterpartyID = uint(id)
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  **money.Amount
+	}{{"min_amount", &f.MinAmount}, {"max_amount", &f.MaxAmount}} {
+		if raw := c.Query(p.name); raw != "" {
+			v, err := money.Parse(raw)
+			if err != nil || v < 0 {
+				return f, fmt.Errorf("Invalid %s", p.name)
+			}
+			*p.dst = &v
+		}
+	}
+
+	var err error
+	if f.CreatedFrom, err = parseListingTime(c.Query("created_from"), false); err != nil {
+		return f, errors.New("Invalid created_from")
+	}
+	if f.CreatedTo, err = parseListingTime(c.Query("created_to"), true); err != nil {
+		return f, errors.New("Invalid created_to")
+	}
+
+	if raw := c.Query("active"); raw != "" {
+		active, err := strconv.ParseBool(raw)
+		if err != nil {
+			return f, errors.New("active must be true or false")
+		}
+		f.Active = &active
+	}
+	return f, nil
+}
+
+// parseListingTime accepts an RFC 3339 timestamp or a plain date. A date
+// used as an upper bound covers the whole day.
+func parseListingTime(raw string, endOfDay bool) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &t, nil
+}
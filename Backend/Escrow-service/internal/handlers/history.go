@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"escrow_service/internal/model"
+	"escrow_service/internal/rbac"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// GetEscrowHistory returns the audit trail of an escrow, oldest event first.
+// Only the buyer, the seller and staff may read it.
+func GetEscrowHistory(c fiber.Ctx) error {
+	escrowID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid escrow ID",
+		})
+	}
+
+	userIDStr := c.Get("X-User-ID")
+	if userIDStr == "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Missing X-User-ID",
+		})
+	}
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var escrow model.Escrow
+	if err := db.First(&escrow, escrowID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Escrow not found",
+		})
+	}
+
+	if uint(userID) != escrow.BuyerID && uint(userID) != escrow.SellerID && !rbac.RoleOf(c).IsStaff() {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access denied to this escrow",
+		})
+	}
+
+	var events []model.EscrowEvent
+	if err := db.Where("escrow_id = ?", escrow.ID).
+		Order("created_at ASC, id ASC").
+		Find(&events).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch escrow history",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"escrow_id": escrow.ID,
+		"status":    escrow.Status,
+		"events":    events,
+	})
+}
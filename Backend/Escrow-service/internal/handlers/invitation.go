@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"escrow_service/internal/creation"
+	"escrow_service/internal/deadline"
+	"escrow_service/internal/model"
+	"escrow_service/internal/rabbitmq"
+	"escrow_service/internal/statemachine"
+	"message_broker/rabbitmq/events"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// maxPendingInvitations caps how many unclaimed invitations a buyer can
+// have out at once.
+const maxPendingInvitations = 20
+
+// errInvitationClaimed is returned when the invitation stopped being
+// pending while its escrow was being created.
+var errInvitationClaimed = errors.New("Invitation was already claimed")
+
+// inviteSeller stores the escrow as an invitation to the seller at email
+// and queues the invitation mail. The escrow itself is created when the
+// seller claims the invitation.
+func inviteSeller(c fiber.Ctx, escrow *model.Escrow, email string, buyerID uint) error {
+	if escrow.InitiatedBy != "" && escrow.InitiatedBy != string(statemachine.Buyer) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Only buyers can invite a seller by email",
+		})
+	}
+	if escrow.SellerID != 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Set either seller_id or seller_email, not both",
+		})
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Name != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid seller email",
+		})
+	}
+	email = strings.ToLower(addr.Address)
+
+	// The funding window of an invitation without a funding deadline starts
+	// when the seller claims it, not now.
+	now := time.Now()
+	fundBy := escrow.FundingDeadline
+	if err := creation.ValidateTerms(escrow, now); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	escrow.FundingDeadline = fundBy
+	if err := creation.CheckBuyer(buyerID); err != nil {
+		return creationFailed(c, err)
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var pending int64
+	if err := db.Model(&model.EscrowInvitation{}).
+		Where("buyer_id = ? AND status = ?", buyerID, model.InvitationPending).
+		Count(&pending).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create invitation",
+		})
+	}
+	if pending >= maxPendingInvitations {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "You can have at most 20 pending invitations",
+		})
+	}
+
+	token, err := newInvitationToken()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create invitation",
+		})
+	}
+	terms := model.TermsOf(escrow)
+	invitation := model.EscrowInvitation{
+		BuyerID:   buyerID,
+		Email:     email,
+		TokenHash: hashInvitationToken(token),
+		Terms:     terms,
+		Status:    model.InvitationPending,
+		ExpiresAt: terms.LatestExpiry(now.Add(deadline.InvitationWindow())),
+	}
+	// Only the hash is kept on the invitation; the token goes out with
+	// escrow.invitation, which user-service mails to the seller.
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&invitation).Error; err != nil {
+			return err
+		}
+		return rabbitmq.NewProducer(tx).PublishEscrowInvitation(events.NewEscrowInvitationEvent(
+			uint64(invitation.ID), uint32(buyerID), email, token,
+			terms.Amount, string(terms.Currency), invitation.ExpiresAt))
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create invitation",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":    "Invitation sent to " + email,
+		"invitation": invitation,
+	})
+}
+
+func newInvitationToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ListInvitations returns the invitations the caller sent, newest first.
+func ListInvitations(c fiber.Ctx) error {
+	userID, err := strconv.ParseUint(c.Get("X-User-ID"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Missing X-User-ID",
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	query := db.Where("buyer_id = ?", userID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var invitations []model.EscrowInvitation
+	if err := query.Order("created_at DESC").Find(&invitations).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch invitations",
+		})
+	}
+	return c.JSON(fiber.Map{"invitations": invitations})
+}
+
+// CancelInvitation withdraws a pending invitation. Only the buyer who sent
+// it can cancel it.
+func CancelInvitation(c fiber.Ctx) error {
+	userID, err := strconv.ParseUint(c.Get("X-User-ID"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Missing X-User-ID",
+		})
+	}
+	invitationID, err := strconv.ParseUint(c.Params("invitationId"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid invitation ID",
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var invitation model.EscrowInvitation
+	if err := db.Where("id = ? AND buyer_id = ?", invitationID, userID).First(&invitation).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Invitation not found",
+		})
+	}
+	if invitation.Status != model.InvitationPending {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invitation is no longer pending",
+		})
+	}
+
+	res := db.Model(&model.EscrowInvitation{}).
+		Where("id = ? AND status = ?", invitation.ID, model.InvitationPending).
+		Update("status", model.InvitationCancelled)
+	if res.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to cancel invitation",
+		})
+	}
+	if res.RowsAffected == 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Invitation changed, please retry",
+		})
+	}
+	invitation.Status = model.InvitationCancelled
+	return c.JSON(invitation)
+}
+
+type claimInvitationRequest struct {
+	Token string `json:"token"`
+}
+
+// ClaimInvitation creates the escrow of an invitation with the caller as
+// seller. The caller must have signed up with the address the invitation
+// was sent to. Until they added bank details and a wallet the escrow is
+// refused like any other and the invitation stays pending, so they can
+// claim it again once they did.
+func ClaimInvitation(c fiber.Ctx) error {
+	userID, err := strconv.ParseUint(c.Get("X-User-ID"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Missing X-User-ID",
+		})
+	}
+	var req claimInvitationRequest
+	if err := c.Bind().Body(&req); err != nil || req.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invitation token is required"})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var invitation model.EscrowInvitation
+	if err := db.Where("token_hash = ?", hashInvitationToken(req.Token)).First(&invitation).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Invitation not found",
+		})
+	}
+	switch {
+	case invitation.Status == model.InvitationClaimed:
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": errInvitationClaimed.Error()})
+	case invitation.Status != model.InvitationPending || !time.Now().Before(invitation.ExpiresAt):
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{"error": "Invitation is no longer valid"})
+	case invitation.BuyerID == uint(userID):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "You cannot claim your own invitation",
+		})
+	}
+
+	user, err := userServiceClient.GetUser(uint32(userID))
+	if err != nil || user == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch user",
+		})
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "This invitation was sent to a different email address",
+		})
+	}
+
+	escrow := invitation.Terms.Escrow()
+	escrow.SellerID = uint(userID)
+	quote, err := creation.Create(db, creation.Request{
+		Escrow:   escrow,
+		CallerID: invitation.BuyerID,
+		Source:   model.SourceHTTP,
+		Claim: func(tx *gorm.DB, escrow *model.Escrow) error {
+			return claimInvitation(tx, &invitation, escrow)
+		},
+	})
+	if errors.Is(err, errInvitationClaimed) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": errInvitationClaimed.Error()})
+	}
+	if err != nil {
+		return creationFailed(c, err)
+	}
+	return escrowCreated(c, escrow, quote)
+}
+
+// claimInvitation binds the invitation to the escrow created from it, in
+// the same transaction, unless it was claimed, cancelled or expired in the
+// meantime.
+func claimInvitation(tx *gorm.DB, invitation *model.EscrowInvitation, escrow *model.Escrow) error {
+	now := time.Now()
+	res := tx.Model(&model.EscrowInvitation{}).
+		Where("id = ? AND status = ? AND expires_at > ?", invitation.ID, model.InvitationPending, now).
+		Updates(map[string]any{
+			"status":     model.InvitationClaimed,
+			"seller_id":  escrow.SellerID,
+			"escrow_id":  escrow.ID,
+			"claimed_at": now,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errInvitationClaimed
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"escrow_service/internal/model"
+	"escrow_service/internal/payout"
+	"escrow_service/internal/statemachine"
+	"shared/money"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// paidOutMilestoneTotal sums the milestones whose funds already left escrow
+// or are on their way to the seller.
+func paidOutMilestoneTotal(db *gorm.DB, escrowID uint) (money.Amount, error) {
+	var total money.Amount
+	err := db.Model(&model.Milestone{}).
+		Where("escrow_id = ? AND status <> ?", escrowID, model.MilestonePending).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// ConfirmMilestone lets the buyer release a single milestone to the seller.
+// Confirming the last outstanding milestone moves the escrow itself to
+// TransferPending so it is released once every transfer has succeeded.
+func ConfirmMilestone(c fiber.Ctx) error {
+	escrowID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid escrow ID",
+		})
+	}
+	milestoneID, err := strconv.ParseUint(c.Params("milestoneId"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid milestone ID",
+		})
+	}
+
+	userIDStr := c.Get("X-User-ID")
+	if userIDStr == "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Missing X-User-ID",
+		})
+	}
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var escrow model.Escrow
+	if err := db.First(&escrow, escrowID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Escrow not found",
+		})
+	}
+
+	if uint(userID) != escrow.BuyerID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Only the buyer can confirm a milestone",
+		})
+	}
+	// Milestones are released from a Funded escrow, and the last one moves
+	// it to TransferPending.
+	if err := statemachine.Check(escrow.Status, model.TransferPending, statemachine.Buyer); err != nil {
+		return transitionFailed(c, err)
+	}
+	if !escrow.Active {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Escrow must be accepted by seller first",
+		})
+	}
+
+	var milestone model.Milestone
+	if err := db.Where("id = ? AND escrow_id = ?", milestoneID, escrow.ID).First(&milestone).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Milestone not found",
+		})
+	}
+	if milestone.Status != model.MilestonePending {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Milestone has already been released",
+		})
+	}
+
+	final, err := payout.ReleaseMilestone(db, &escrow, &milestone, statemachine.Trigger{
+		Actor:  statemachine.Buyer,
+		UserID: uint(userID),
+		Source: model.SourceHTTP,
+	})
+	if err != nil {
+		return payoutFailed(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message":      "Milestone confirmed. Funds released.",
+		"milestone_id": milestone.ID,
+		"final":        final,
+	})
+}
@@ -0,0 +1,406 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"escrow_service/internal/creation"
+	"escrow_service/internal/model"
+	"shared/money"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxPaymentLinksPerUser = 100
+	maxPaymentLinkTitle    = 120
+)
+
+var (
+	errPaymentLinkUnavailable = errors.New("This payment link is no longer available")
+	errPaymentLinkSoldOut     = errors.New("This offer is sold out")
+)
+
+// fundedStatuses are the statuses of escrows that were funded at some
+// point; they count as conversions of the link they came from.
+var fundedStatuses = []model.EscrowStatus{
+	model.Funded, model.TransferPending, model.Released,
+	model.Disputed, model.Settling, model.Settled, model.Refunded,
+}
+
+// freedStatuses are the statuses of escrows that no longer hold a unit of
+// their link's quantity.
+var freedStatuses = []model.EscrowStatus{model.Cancelled, model.Declined}
+
+type paymentLinkRequest struct {
+	Title                 string                    `json:"title"`
+	Amount                money.Amount              `json:"amount"`
+	Currency              model.Currency            `json:"currency"`
+	Conditions            string                    `json:"conditions"`
+	ConditionsDoc         *model.ConditionsDocument `json:"conditions_doc"`
+	FundingWindowHours    int                       `json:"funding_window_hours"`
+	InspectionPeriodHours int                       `json:"inspection_period_hours"`
+	Quantity              int                       `json:"quantity"`
+	ExpiresAt             *time.Time                `json:"expires_at"`
+}
+
+// linkStats is how a payment link is doing. Escrows counts the escrows
+// buyers created from it, Funded those of them that were funded and
+// Reserved those that still hold a unit of its quantity. ConversionRate is
+// Funded over Opens.
+type linkStats struct {
+	Opens          int     `json:"opens"`
+	Escrows        int64   `json:"escrows"`
+	Funded         int64   `json:"funded"`
+	Reserved       int64   `json:"reserved"`
+	ConversionRate float64 `json:"conversion_rate"`
+}
+
+type paymentLinkView struct {
+	model.PaymentLink
+	Stats *linkStats `json:"stats,omitempty"`
+}
+
+// validatePaymentLink checks a payment link with the rules of CreateEscrow,
+// by validating the escrow it would create right now, and normalizes it
+// the same way.
+func validatePaymentLink(l *model.PaymentLink, now time.Time) error {
+	l.Title = strings.TrimSpace(l.Title)
+	if l.Title == "" {
+		return errors.New("Title is required")
+	}
+	if len(l.Title) > maxPaymentLinkTitle {
+		return errors.New("Title must be at most 120 characters")
+	}
+	if l.FundingWindowHours < 0 {
+		return errors.New("funding_window_hours must not be negative")
+	}
+	if l.Quantity < 0 {
+		return errors.New("quantity must not be negative")
+	}
+	if l.ExpiresAt != nil && !l.ExpiresAt.After(now) {
+		return errors.New("expires_at must be in the future")
+	}
+	if l.ConditionsDoc != nil && l.ConditionsDoc.DeliveryDeadline != nil {
+		return errors.New("Payment links cannot set a delivery deadline")
+	}
+
+	escrow := l.Escrow(now)
+	if err := creation.ValidateTerms(escrow, now); err != nil {
+		return err
+	}
+	l.Amount = escrow.Amount
+	l.Currency = escrow.Currency
+	l.ConditionsDoc = escrow.ConditionsDoc
+	return nil
+}
+
+func newPaymentLinkCode() (string, error) {
+	b := make([]byte, 9)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CreatePaymentLink publishes an offer of the caller's that any buyer can
+// fund through its link.
+func CreatePaymentLink(c fiber.Ctx) error {
+	userID, err := strconv.ParseUint(c.Get("X-User-ID"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Missing X-User-ID",
+		})
+	}
+	var req paymentLinkRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	link := model.PaymentLink{
+		SellerID:              uint(userID),
+		Title:                 req.Title,
+		Amount:                req.Amount,
+		Currency:              req.Currency,
+		Conditions:            req.Conditions,
+		ConditionsDoc:         req.ConditionsDoc,
+		FundingWindowHours:    req.FundingWindowHours,
+		InspectionPeriodHours: req.InspectionPeriodHours,
+		Quantity:              req.Quantity,
+		ExpiresAt:             req.ExpiresAt,
+		Active:                true,
+	}
+	if err := validatePaymentLink(&link, time.Now()); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	// Buyers cannot fund an escrow with a seller who cannot be paid out.
+	if err := creation.CheckSeller(uint(userID)); err != nil {
+		return creationFailed(c, err)
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var count int64
+	if err := db.Model(&model.PaymentLink{}).Where("seller_id = ?", userID).Count(&count).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create payment link",
+		})
+	}
+	if count >= maxPaymentLinksPerUser {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "You can keep at most 100 payment links",
+		})
+	}
+	if link.Code, err = newPaymentLinkCode(); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create payment link",
+		})
+	}
+	if err := db.Create(&link).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create payment link",
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(paymentLinkView{PaymentLink: link, Stats: &linkStats{}})
+}
+
+// paymentLinkStats computes the stats of the given links from the escrows
+// created from them.
+func paymentLinkStats(db *gorm.DB, links []model.PaymentLink) (map[uint]*linkStats, error) {
+	stats := make(map[uint]*linkStats, len(links))
+	ids := make([]uint, 0, len(links))
+	for _, l := range links {
+		stats[l.ID] = &linkStats{Opens: l.Opens}
+		ids = append(ids, l.ID)
+	}
+	if len(ids) == 0 {
+		return stats, nil
+	}
+
+	var rows []struct {
+		PaymentLinkID uint
+		Status        model.EscrowStatus
+		Count         int64
+	}
+	if err := db.Model(&model.Escrow{}).
+		Select("payment_link_id, status, COUNT(*) AS count").
+		Where("payment_link_id IN ?", ids).
+		Group("payment_link_id, status").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		s := stats[row.PaymentLinkID]
+		s.Escrows += row.Count
+		if slices.Contains(fundedStatuses, row.Status) {
+			s.Funded += row.Count
+		}
+		if !slices.Contains(freedStatuses, row.Status) {
+			s.Reserved += row.Count
+		}
+	}
+	for _, s := range stats {
+		if s.Opens > 0 {
+			s.ConversionRate = float64(s.Funded) / float64(s.Opens)
+		}
+	}
+	return stats, nil
+}
+
+// ListPaymentLinks returns the caller's payment links with their stats,
+// newest first.
+func ListPaymentLinks(c fiber.Ctx) error {
+	userID, err := strconv.ParseUint(c.Get("X-User-ID"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Missing X-User-ID",
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var links []model.PaymentLink
+	if err := db.Where("seller_id = ?", userID).Order("created_at DESC").Find(&links).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch payment links",
+		})
+	}
+	stats, err := paymentLinkStats(db, links)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch payment link stats",
+		})
+	}
+	views := make([]paymentLinkView, 0, len(links))
+	for _, l := range links {
+		views = append(views, paymentLinkView{PaymentLink: l, Stats: stats[l.ID]})
+	}
+	return c.JSON(fiber.Map{"payment_links": views})
+}
+
+// loadPaymentLink returns the payment link named by the :code parameter
+// and the caller's ID. On failure it returns the status and message to
+// respond with.
+func loadPaymentLink(c fiber.Ctx) (*model.PaymentLink, uint, int, string) {
+	userID, err := strconv.ParseUint(c.Get("X-User-ID"), 10, 32)
+	if err != nil {
+		return nil, 0, fiber.StatusForbidden, "Missing X-User-ID"
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var link model.PaymentLink
+	if err := db.Where("code = ?", c.Params("code")).First(&link).Error; err != nil {
+		return nil, 0, fiber.StatusNotFound, "Payment link not found"
+	}
+	return &link, uint(userID), 0, ""
+}
+
+// GetPaymentLink shows a payment link. Its seller gets its stats; anyone
+// else sees the offer and how many units are left, which counts as an open
+// of the link.
+func GetPaymentLink(c fiber.Ctx) error {
+	link, userID, status, msg := loadPaymentLink(c)
+	if link == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	if link.SellerID == userID {
+		stats, err := paymentLinkStats(db, []model.PaymentLink{*link})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to fetch payment link stats",
+			})
+		}
+		return c.JSON(paymentLinkView{PaymentLink: *link, Stats: stats[link.ID]})
+	}
+
+	if !link.Available(time.Now()) {
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{"error": errPaymentLinkUnavailable.Error()})
+	}
+	if err := db.Model(&model.PaymentLink{}).Where("id = ?", link.ID).
+		UpdateColumn("opens", gorm.Expr("opens + 1")).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to open payment link",
+		})
+	}
+	offer := fiber.Map{
+		"code":                    link.Code,
+		"seller_id":               link.SellerID,
+		"title":                   link.Title,
+		"amount":                  link.Amount,
+		"currency":                link.Currency,
+		"conditions":              link.Conditions,
+		"conditions_doc":          link.ConditionsDoc,
+		"funding_window_hours":    link.FundingWindowHours,
+		"inspection_period_hours": link.InspectionPeriodHours,
+		"expires_at":              link.ExpiresAt,
+	}
+	if link.Quantity > 0 {
+		stats, err := paymentLinkStats(db, []model.PaymentLink{*link})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to fetch payment link",
+			})
+		}
+		offer["remaining"] = max(int64(link.Quantity)-stats[link.ID].Reserved, 0)
+	}
+	return c.JSON(offer)
+}
+
+// DeactivatePaymentLink stops a payment link from taking new buyers.
+// Escrows already created from it are not affected.
+func DeactivatePaymentLink(c fiber.Ctx) error {
+	link, userID, status, msg := loadPaymentLink(c)
+	if link == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	if link.SellerID != userID {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Payment link not found"})
+	}
+	if !link.Active {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Payment link is already deactivated",
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	if err := db.Model(link).Update("active", false).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to deactivate payment link",
+		})
+	}
+	return c.JSON(link)
+}
+
+// OpenPaymentLink creates an escrow with the link's terms and the caller as
+// buyer, validated like any other. The response says where to fund it, so
+// the buyer can go straight on to the payment.
+func OpenPaymentLink(c fiber.Ctx) error {
+	link, userID, status, msg := loadPaymentLink(c)
+	if link == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	now := time.Now()
+	if !link.Available(now) {
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{"error": errPaymentLinkUnavailable.Error()})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	escrow := link.Escrow(now)
+	quote, err := creation.Create(db, creation.Request{
+		Escrow:   escrow,
+		CallerID: userID,
+		Source:   model.SourceHTTP,
+		Claim: func(tx *gorm.DB, escrow *model.Escrow) error {
+			return reservePaymentLink(tx, link.ID, now)
+		},
+	})
+	switch {
+	case errors.Is(err, errPaymentLinkUnavailable):
+		return c.Status(fiber.StatusGone).JSON(fiber.Map{"error": errPaymentLinkUnavailable.Error()})
+	case errors.Is(err, errPaymentLinkSoldOut):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": errPaymentLinkSoldOut.Error()})
+	case err != nil:
+		return creationFailed(c, err)
+	}
+
+	res := createdResponse(escrow, quote)
+	res["payment"] = fiber.Map{
+		"method":    "POST",
+		"path":      "/api/payments/initiate",
+		"escrow_id": escrow.ID,
+	}
+	return c.Status(fiber.StatusAccepted).JSON(res)
+}
+
+// reservePaymentLink takes a unit of the link for the escrow just created
+// from it, in the same transaction. The link row is locked so concurrent
+// buyers cannot take the last unit twice.
+func reservePaymentLink(tx *gorm.DB, linkID uint, now time.Time) error {
+	var link model.PaymentLink
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&link, linkID).Error; err != nil {
+		return err
+	}
+	if !link.Available(now) {
+		return errPaymentLinkUnavailable
+	}
+	if link.Quantity == 0 {
+		return nil
+	}
+	// The new escrow is already part of the count.
+	var reserved int64
+	if err := tx.Model(&model.Escrow{}).
+		Where("payment_link_id = ? AND status NOT IN ?", link.ID, freedStatuses).
+		Count(&reserved).Error; err != nil {
+		return err
+	}
+	if reserved > int64(link.Quantity) {
+		return errPaymentLinkSoldOut
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"escrow_service/internal/model"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type recurringRequest struct {
+	TemplateID uint `json:"template_id"`
+	// CounterpartyID defaults to the template's counterparty.
+	CounterpartyID *uint                    `json:"counterparty_id"`
+	Interval       model.RecurrenceInterval `json:"interval"`
+	// StartsAt is when the first escrow is created, now by default.
+	StartsAt  *time.Time `json:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at"`
+	MaxCycles int        `json:"max_cycles"`
+}
+
+// CreateRecurringEscrow sets up an escrow to be created from one of the
+// caller's templates every week or month.
+func CreateRecurringEscrow(c fiber.Ctx) error {
+	userID, err := strconv.ParseUint(c.Get("X-User-ID"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Missing X-User-ID",
+		})
+	}
+	var req recurringRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	switch req.Interval {
+	case model.RecurWeekly, model.RecurMonthly:
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "interval must be weekly or monthly",
+		})
+	}
+	now := time.Now()
+	startsAt := now
+	if req.StartsAt != nil {
+		if req.StartsAt.Before(now.Add(-time.Minute)) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "starts_at must not be in the past",
+			})
+		}
+		startsAt = *req.StartsAt
+	}
+	if req.EndsAt != nil && !req.EndsAt.After(startsAt) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ends_at must be after starts_at",
+		})
+	}
+	if req.MaxCycles < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "max_cycles must not be negative",
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var template model.EscrowTemplate
+	if err := db.Where("id = ? AND owner_id = ?", req.TemplateID, userID).First(&template).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Template not found",
+		})
+	}
+	counterparty := template.CounterpartyID
+	if req.CounterpartyID != nil {
+		counterparty = req.CounterpartyID
+	}
+	if counterparty == nil || *counterparty == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "counterparty_id is required",
+		})
+	}
+	// The template's terms must still make a valid escrow, with the
+	// counterparty of this schedule.
+	check := template
+	check.CounterpartyID = counterparty
+	if err := validateTemplate(&check); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	recurring := model.RecurringEscrow{
+		OwnerID:        uint(userID),
+		TemplateID:     template.ID,
+		CounterpartyID: *counterparty,
+		Interval:       req.Interval,
+		StartsAt:       startsAt,
+		EndsAt:         req.EndsAt,
+		MaxCycles:      req.MaxCycles,
+		Status:         model.RecurringActive,
+	}
+	recurring.NextRunAt = recurring.NextRun(0)
+	if err := db.Create(&recurring).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create recurring escrow",
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(recurring)
+}
+
+// ListRecurringEscrows returns the recurring escrows the caller set up or
+// is the counterparty of, newest first.
+func ListRecurringEscrows(c fiber.Ctx) error {
+	userID, err := strconv.ParseUint(c.Get("X-User-ID"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Missing X-User-ID",
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var recurring []model.RecurringEscrow
+	if err := db.Where("owner_id = ? OR counterparty_id = ?", userID, userID).
+		Order("created_at DESC").
+		Find(&recurring).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch recurring escrows",
+		})
+	}
+	return c.JSON(fiber.Map{"recurring_escrows": recurring})
+}
+
+// loadRecurring returns the recurring escrow named by the :recurringId
+// parameter if the caller is a party to it, and the caller's ID. On failure
+// it returns the status and message to respond with.
+func loadRecurring(c fiber.Ctx) (*model.RecurringEscrow, uint, int, string) {
+	userID, err := strconv.ParseUint(c.Get("X-User-ID"), 10, 32)
+	if err != nil {
+		return nil, 0, fiber.StatusForbidden, "Missing X-User-ID"
+	}
+	recurringID, err := strconv.ParseUint(c.Params("recurringId"), 10, 32)
+	if err != nil {
+		return nil, 0, fiber.StatusBadRequest, "Invalid recurring escrow ID"
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var recurring model.RecurringEscrow
+	if err := db.First(&recurring, recurringID).Error; err != nil {
+		return nil, 0, fiber.StatusNotFound, "Recurring escrow not found"
+	}
+	if recurring.OwnerID != uint(userID) && recurring.CounterpartyID != uint(userID) {
+		return nil, 0, fiber.StatusForbidden, "Access denied to this recurring escrow"
+	}
+	return &recurring, uint(userID), 0, ""
+}
+
+// GetRecurringEscrow returns a recurring escrow with the escrows it
+// created so far.
+func GetRecurringEscrow(c fiber.Ctx) error {
+	recurring, _, status, msg := loadRecurring(c)
+	if recurring == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var escrows []model.Escrow
+	if err := db.Where("recurring_escrow_id = ?", recurring.ID).
+		Order("recurring_cycle DESC").
+		Find(&escrows).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch escrows",
+		})
+	}
+	return c.JSON(fiber.Map{
+		"recurring_escrow": recurring,
+		"escrows":          escrows,
+	})
+}
+
+// updateRecurring applies updates if the schedule is still in one of the
+// given statuses.
+func updateRecurring(c fiber.Ctx, recurring *model.RecurringEscrow, from []model.RecurringStatus, updates map[string]any) error {
+	db := c.Locals("db").(*gorm.DB)
+	res := db.Model(&model.RecurringEscrow{}).
+		Where("id = ? AND status IN ?", recurring.ID, from).
+		Updates(updates)
+	if res.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update recurring escrow",
+		})
+	}
+	if res.RowsAffected == 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Recurring escrow changed, please retry",
+		})
+	}
+	if err := db.First(recurring, recurring.ID).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch recurring escrow",
+		})
+	}
+	return c.JSON(recurring)
+}
+
+// PauseRecurringEscrow stops a recurring escrow from creating escrows
+// until its owner resumes it.
+func PauseRecurringEscrow(c fiber.Ctx) error {
+	recurring, userID, status, msg := loadRecurring(c)
+	if recurring == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	if recurring.OwnerID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Only the owner can pause a recurring escrow",
+		})
+	}
+	if recurring.Status != model.RecurringActive {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Recurring escrow is not active",
+		})
+	}
+	return updateRecurring(c, recurring, []model.RecurringStatus{model.RecurringActive}, map[string]any{
+		"status": model.RecurringPaused,
+	})
+}
+
+// ResumeRecurringEscrow restarts a paused recurring escrow. Cycles that
+// fell due while it was paused are skipped.
+func ResumeRecurringEscrow(c fiber.Ctx) error {
+	recurring, userID, status, msg := loadRecurring(c)
+	if recurring == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	if recurring.OwnerID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Only the owner can resume a recurring escrow",
+		})
+	}
+	if recurring.Status != model.RecurringPaused {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Recurring escrow is not paused",
+		})
+	}
+
+	period := recurring.PeriodAfter(recurring.NextPeriod, time.Now())
+	nextRun := recurring.NextRun(period)
+	updates := map[string]any{
+		"status":      model.RecurringActive,
+		"next_period": period,
+		"next_run_at": nextRun,
+		"last_error":  "",
+	}
+	if nextRun == nil {
+		updates["status"] = model.RecurringCompleted
+	}
+	return updateRecurring(c, recurring, []model.RecurringStatus{model.RecurringPaused}, updates)
+}
+
+// CancelRecurringEscrow ends a recurring escrow for good. Either party can
+// cancel it; escrows it already created are not affected.
+func CancelRecurringEscrow(c fiber.Ctx) error {
+	recurring, _, status, msg := loadRecurring(c)
+	if recurring == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	if recurring.Status != model.RecurringActive && recurring.Status != model.RecurringPaused {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Recurring escrow has already ended",
+		})
+	}
+	return updateRecurring(c, recurring,
+		[]model.RecurringStatus{model.RecurringActive, model.RecurringPaused},
+		map[string]any{"status": model.RecurringCancelled, "next_run_at": nil})
+}
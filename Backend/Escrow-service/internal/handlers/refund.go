@@ -3,12 +3,10 @@
 package handlers
 
 import (
-	"escrow_service/internal/auth"
 	"escrow_service/internal/model"
-	"escrow_service/internal/payment"
+	"escrow_service/internal/payout"
+	"escrow_service/internal/statemachine"
 	"fmt"
-	"log"
-	"shared/chapa"
 	"strconv"
 
 	"github.com/gofiber/fiber/v3"
@@ -55,11 +53,9 @@ func RefundEscrow(c fiber.Ctx) error {
 		})
 	}
 
-	// Business rules: only allow refund if still funded and active
-	if escrow.Status != "Disputed" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Escrow is not in a refundable state",
-		})
+	// Business rules: only allow refund of an accepted escrow under dispute
+	if err := statemachine.Check(escrow.Status, model.Refunded, statemachine.Seller); err != nil {
+		return transitionFailed(c, err)
 	}
 	if !escrow.Active {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -67,77 +63,28 @@ func RefundEscrow(c fiber.Ctx) error {
 		})
 	}
 
-	// Get buyer's bank details from user-service
-	userServiceClient, err := auth.NewUserServiceClient("user-service:50051")
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to connect to user service" + err.Error(),
-		})
-	}
-	defer userServiceClient.Close()
-
-	buyerRes, err := userServiceClient.GetUser(uint32(escrow.BuyerID))
+	// Milestones already released to the seller are not refunded
+	paidOut, err := paidOutMilestoneTotal(db, escrow.ID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get buyer info",
-		})
-	}
-
-	if !buyerRes.Activated {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Buyer account is not activated",
-		})
-	}
-
-	if buyerRes.AccountName == nil || buyerRes.AccountName.Value == "" ||
-		buyerRes.AccountNumber == nil || buyerRes.AccountNumber.Value == "" ||
-		buyerRes.BankCode == nil || buyerRes.BankCode.Value == 0 {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Buyer has not added bank account details",
+			"error": "Failed to compute refund amount",
 		})
 	}
-
-	// Validate bank account using Chapa
-	err = chapa.ValidateAccount(int(buyerRes.BankCode.GetValue()), buyerRes.AccountNumber.GetValue())
-	if err != nil {
+	refundAmount := escrow.Amount - paidOut
+	if refundAmount <= 0 {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid bank details: " + err.Error(),
-		})
-	}
-
-	// Initiate transfer to buyer (yes, TransferToSeller is used for any payout)
-	chapaClient := chapa.NewClient()
-	resp, err := chapaClient.TransferToSeller(
-		escrow.BuyerID,
-		escrow.Amount,
-		fmt.Sprintf("refund-escrow-%d", escrow.ID),
-		buyerRes.AccountName.Value,
-		buyerRes.AccountNumber.Value,
-		int(buyerRes.BankCode.Value),
-	)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to initiate refund transfer: " + err.Error(),
+			"error": "Nothing left to refund",
 		})
 	}
 
-	// Update escrow status
-	escrow.Status = "Refunded"
-	db.Save(&escrow)
-
-	// Finalize via payment service
-	paymentClient, err := payment.NewPaymentServiceClient("payment-service:50053")
+	err = payout.RefundBuyer(db, &escrow, refundAmount, model.Refunded,
+		fmt.Sprintf("refund-escrow-%d", escrow.ID), statemachine.Trigger{
+			Actor:  statemachine.Seller,
+			UserID: uint(userID),
+			Source: model.SourceHTTP,
+		}, nil)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to connect to payment service",
-		})
-	}
-	response, err := paymentClient.Finalize(resp.Data)
-	if err != nil {
-		log.Printf("gRPC Finalize error: %v ", err)
-	}
-	if !response.Success {
-		log.Printf("Finalize failed: %s", response.Error)
+		return payoutFailed(c, err)
 	}
 
 	return c.JSON(fiber.Map{
@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"errors"
+	"escrow_service/internal/fee"
+	"escrow_service/internal/model"
+	"escrow_service/internal/payout"
+	"escrow_service/internal/rabbitmq"
+	"escrow_service/internal/statemachine"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// errSettlementDecided is returned when a settlement was accepted, rejected
+// or superseded while it was being accepted.
+var errSettlementDecided = errors.New("Settlement is no longer open")
+
+// ProposeSettlement lets either party of a disputed escrow propose splitting
+// the outstanding funds. A new proposal supersedes any open one.
+func ProposeSettlement(c fiber.Ctx) error {
+	type Request struct {
+		SellerPercent *float64 `json:"seller_percent"`
+	}
+
+	escrowID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid escrow ID",
+		})
+	}
+
+	userIDStr := c.Get("X-User-ID")
+	if userIDStr == "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Missing X-User-ID",
+		})
+	}
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	var req Request
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if req.SellerPercent == nil || *req.SellerPercent < 0 || *req.SellerPercent > 100 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "seller_percent must be between 0 and 100",
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var escrow model.Escrow
+	if err := db.First(&escrow, escrowID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Escrow not found",
+		})
+	}
+
+	actor, ok := statemachine.ActorOf(&escrow, uint(userID))
+	if !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access denied to this escrow",
+		})
+	}
+	if err := statemachine.Check(escrow.Status, model.Settling, actor); err != nil {
+		return transitionFailed(c, err)
+	}
+
+	settlement := model.Settlement{
+		EscrowID:      escrow.ID,
+		ProposedBy:    uint(userID),
+		SellerPercent: *req.SellerPercent,
+		Status:        model.SettlementProposed,
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Settlement{}).
+			Where("escrow_id = ? AND status = ?", escrow.ID, model.SettlementProposed).
+			Update("status", model.SettlementRejected).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&settlement).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&model.EscrowEvent{
+			EscrowID:   escrow.ID,
+			Kind:       model.EventSettlementProposed,
+			ActorID:    uint(userID),
+			ActorRole:  string(actor),
+			FromStatus: escrow.Status,
+			ToStatus:   escrow.Status,
+			Source:     model.SourceHTTP,
+			Note:       fmt.Sprintf("settlement %d: %.2f%% to seller", settlement.ID, settlement.SellerPercent),
+		}).Error; err != nil {
+			return err
+		}
+		return rabbitmq.NewProducer(tx).PublishSettlementProposed(uint64(escrow.ID), uint64(settlement.ID), uint32(userID), settlement.SellerPercent)
+	})
+	if err != nil {
+		log.Printf("Failed to propose settlement on escrow %d: %v", escrow.ID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to propose settlement",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(settlement)
+}
+
+// GetSettlements lists every settlement proposal of an escrow.
+func GetSettlements(c fiber.Ctx) error {
+	escrowID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid escrow ID",
+		})
+	}
+
+	userIDStr := c.Get("X-User-ID")
+	if userIDStr == "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Missing X-User-ID",
+		})
+	}
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var escrow model.Escrow
+	if err := db.First(&escrow, escrowID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Escrow not found",
+		})
+	}
+	if _, ok := statemachine.ActorOf(&escrow, uint(userID)); !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access denied to this escrow",
+		})
+	}
+
+	var settlements []model.Settlement
+	if err := db.Where("escrow_id = ?", escrow.ID).
+		Order("created_at DESC").
+		Find(&settlements).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch settlements",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"settlements": settlements,
+		"total":       len(settlements),
+	})
+}
+
+// AcceptSettlement executes an open proposal. Only the counterparty of the
+// user who proposed it may accept.
+func AcceptSettlement(c fiber.Ctx) error {
+	return decideSettlement(c, true)
+}
+
+// RejectSettlement declines an open proposal made by the counterparty.
+func RejectSettlement(c fiber.Ctx) error {
+	return decideSettlement(c, false)
+}
+
+func decideSettlement(c fiber.Ctx, accept bool) error {
+	escrowID, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid escrow ID",
+		})
+	}
+	settlementID, err := strconv.ParseUint(c.Params("settlementId"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid settlement ID",
+		})
+	}
+
+	userIDStr := c.Get("X-User-ID")
+	if userIDStr == "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Missing X-User-ID",
+		})
+	}
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var escrow model.Escrow
+	if err := db.First(&escrow, escrowID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Escrow not found",
+		})
+	}
+	actor, ok := statemachine.ActorOf(&escrow, uint(userID))
+	if !ok {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access denied to this escrow",
+		})
+	}
+
+	var settlement model.Settlement
+	if err := db.Where("id = ? AND escrow_id = ?", settlementID, escrow.ID).First(&settlement).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Settlement not found",
+		})
+	}
+	if settlement.Status != model.SettlementProposed {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Settlement is no longer open",
+		})
+	}
+	if settlement.ProposedBy == uint(userID) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Only the other party can decide on this settlement",
+		})
+	}
+
+	if !accept {
+		decidedBy := uint(userID)
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&settlement).Updates(model.Settlement{
+				Status:    model.SettlementRejected,
+				DecidedBy: &decidedBy,
+			}).Error; err != nil {
+				return err
+			}
+			return tx.Create(&model.EscrowEvent{
+				EscrowID:   escrow.ID,
+				Kind:       model.EventSettlementRejected,
+				ActorID:    uint(userID),
+				ActorRole:  string(actor),
+				FromStatus: escrow.Status,
+				ToStatus:   escrow.Status,
+				Source:     model.SourceHTTP,
+				Note:       fmt.Sprintf("settlement %d", settlement.ID),
+			}).Error
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to reject settlement",
+			})
+		}
+		return c.JSON(fiber.Map{
+			"message":       "Settlement rejected",
+			"settlement_id": settlement.ID,
+		})
+	}
+
+	err = executeSettlement(db, &escrow, &settlement, statemachine.Trigger{
+		Actor:  actor,
+		UserID: uint(userID),
+		Source: model.SourceHTTP,
+	})
+	if errors.Is(err, errSettlementDecided) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return payoutFailed(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message":    "Settlement accepted. Funds are being split.",
+		"settlement": settlement,
+	})
+}
+
+// executeSettlement moves a disputed escrow to Settling and pays the
+// outstanding funds out according to settlement.SellerPercent, one Chapa
+// transfer per party. The escrow only becomes Settled once both transfers
+// were started; a failed leg leaves it Settling for the scheduler to retry.
+func executeSettlement(db *gorm.DB, escrow *model.Escrow, settlement *model.Settlement, t statemachine.Trigger) error {
+	if err := statemachine.Check(escrow.Status, model.Settling, t.Actor); err != nil {
+		return err
+	}
+
+	paidOut, err := paidOutMilestoneTotal(db, escrow.ID)
+	if err != nil {
+		return err
+	}
+	outstanding := escrow.Amount - paidOut
+	sellerAmount := outstanding.Percent(settlement.SellerPercent)
+	buyerAmount := outstanding - sellerAmount
+
+	// The seller's fee is charged on their share of the split only.
+	sellerFee, err := fee.SellerShare(db, escrow, sellerAmount, false)
+	if err != nil {
+		return err
+	}
+
+	// Fail before any state changes when a party has no payout account.
+	if sellerAmount > 0 {
+		if _, err := payout.LookupAccount(escrow.SellerID, "Seller"); err != nil {
+			return err
+		}
+	}
+	if buyerAmount > 0 {
+		if _, err := payout.LookupAccount(escrow.BuyerID, "Buyer"); err != nil {
+			return err
+		}
+	}
+
+	if t.Note == "" {
+		t.Note = fmt.Sprintf("settlement %d", settlement.ID)
+	}
+	decidedBy := t.UserID
+	err = statemachine.FireWith(db, escrow, model.Settling, t, func(tx *gorm.DB) error {
+		// Only an open proposal can be accepted, and only once.
+		res := tx.Model(&model.Settlement{}).
+			Where("id = ? AND status = ?", settlement.ID, model.SettlementProposed).
+			Updates(map[string]any{
+				"decided_by":    decidedBy,
+				"seller_amount": sellerAmount,
+				"seller_fee":    sellerFee,
+				"buyer_amount":  buyerAmount,
+				"status":        model.SettlementPaying,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errSettlementDecided
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	settlement.DecidedBy = &decidedBy
+	settlement.SellerAmount = sellerAmount
+	settlement.SellerFee = sellerFee
+	settlement.BuyerAmount = buyerAmount
+	settlement.Status = model.SettlementPaying
+
+	return payout.PaySettlement(db, escrow, settlement, t.Source)
+}
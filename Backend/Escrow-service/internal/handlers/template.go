@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"errors"
+	"escrow_service/internal/creation"
+	"escrow_service/internal/model"
+	"escrow_service/internal/statemachine"
+	"fmt"
+	"shared/money"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+const (
+	maxTemplatesPerUser   = 100
+	maxTemplateNameLength = 100
+)
+
+var errTemplateNameTaken = errors.New("You already have a template with this name")
+
+type templateRequest struct {
+	Name                  string                    `json:"name"`
+	InitiatedBy           string                    `json:"initiated_by"`
+	CounterpartyID        *uint                     `json:"counterparty_id"`
+	Amount                money.Amount              `json:"amount"`
+	Currency              model.Currency            `json:"currency"`
+	Conditions            string                    `json:"conditions"`
+	ConditionsDoc         *model.ConditionsDocument `json:"conditions_doc"`
+	Milestones            model.TemplateMilestones  `json:"milestones"`
+	FundingWindowHours    int                       `json:"funding_window_hours"`
+	DeliveryWindowHours   int                       `json:"delivery_window_hours"`
+	InspectionPeriodHours int                       `json:"inspection_period_hours"`
+}
+
+func (r *templateRequest) apply(t *model.EscrowTemplate) {
+	t.Name = strings.TrimSpace(r.Name)
+	t.InitiatedBy = r.InitiatedBy
+	t.CounterpartyID = r.CounterpartyID
+	t.Amount = r.Amount
+	t.Currency = r.Currency
+	t.Conditions = r.Conditions
+	t.ConditionsDoc = r.ConditionsDoc
+	t.Milestones = r.Milestones
+	t.FundingWindowHours = r.FundingWindowHours
+	t.DeliveryWindowHours = r.DeliveryWindowHours
+	t.InspectionPeriodHours = r.InspectionPeriodHours
+}
+
+// validateTemplate checks a template with the rules of CreateEscrow, by
+// validating the escrow it would create right now, and normalizes it the
+// same way.
+func validateTemplate(t *model.EscrowTemplate) error {
+	if t.Name == "" {
+		return errors.New("Name is required")
+	}
+	if len(t.Name) > maxTemplateNameLength {
+		return errors.New("Name must be at most 100 characters")
+	}
+	switch t.InitiatedBy {
+	case "":
+		t.InitiatedBy = string(statemachine.Buyer)
+	case string(statemachine.Buyer), string(statemachine.Seller):
+	default:
+		return errors.New("initiated_by must be buyer or seller")
+	}
+	if t.CounterpartyID != nil && *t.CounterpartyID == t.OwnerID {
+		return errors.New("Buyer and seller cannot be the same user")
+	}
+	if t.FundingWindowHours < 0 || t.DeliveryWindowHours < 0 {
+		return errors.New("Deadline windows must not be negative")
+	}
+	if t.ConditionsDoc != nil && t.ConditionsDoc.DeliveryDeadline != nil {
+		return errors.New("Templates set delivery_window_hours instead of a delivery deadline")
+	}
+	if t.DeliveryWindowHours > 0 && t.ConditionsDoc == nil {
+		return errors.New("delivery_window_hours requires conditions_doc")
+	}
+	for i, m := range t.Milestones {
+		if m.DueInHours < 0 {
+			return fmt.Errorf("milestone %d: due_in_hours must not be negative", i+1)
+		}
+	}
+
+	escrow := t.Escrow(time.Now())
+	if err := creation.ValidateTerms(escrow, time.Now()); err != nil {
+		return err
+	}
+	t.Amount = escrow.Amount
+	t.Currency = escrow.Currency
+	if escrow.ConditionsDoc != nil {
+		doc := *escrow.ConditionsDoc
+		doc.DeliveryDeadline = nil
+		t.ConditionsDoc = &doc
+	}
+	for i := range t.Milestones {
+		t.Milestones[i].Description = strings.TrimSpace(t.Milestones[i].Description)
+	}
+	return nil
+}
+
+// saveTemplate stores the template if its owner has no other template of
+// the same name.
+func saveTemplate(db *gorm.DB, t *model.EscrowTemplate) error {
+	var taken int64
+	if err := db.Model(&model.EscrowTemplate{}).
+		Where("owner_id = ? AND name = ? AND id <> ?", t.OwnerID, t.Name, t.ID).
+		Count(&taken).Error; err != nil {
+		return err
+	}
+	if taken > 0 {
+		return errTemplateNameTaken
+	}
+	return db.Save(t).Error
+}
+
+// loadTemplate returns the caller's template named by the :templateId
+// parameter. On failure it returns the status and message to respond with.
+func loadTemplate(c fiber.Ctx) (*model.EscrowTemplate, uint, int, string) {
+	userID, err := strconv.ParseUint(c.Get("X-User-ID"), 10, 32)
+	if err != nil {
+		return nil, 0, fiber.StatusForbidden, "Missing X-User-ID"
+	}
+	templateID, err := strconv.ParseUint(c.Params("templateId"), 10, 32)
+	if err != nil {
+		return nil, 0, fiber.StatusBadRequest, "Invalid template ID"
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var template model.EscrowTemplate
+	if err := db.Where("id = ? AND owner_id = ?", templateID, userID).First(&template).Error; err != nil {
+		return nil, 0, fiber.StatusNotFound, "Template not found"
+	}
+	return &template, uint(userID), 0, ""
+}
+
+// ListTemplates returns the caller's escrow templates, most used first.
+func ListTemplates(c fiber.Ctx) error {
+	userID, err := strconv.ParseUint(c.Get("X-User-ID"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Missing X-User-ID",
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var templates []model.EscrowTemplate
+	if err := db.Where("owner_id = ?", userID).
+		Order("usage_count DESC, updated_at DESC").
+		Find(&templates).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch templates",
+		})
+	}
+	return c.JSON(fiber.Map{"templates": templates})
+}
+
+// GetTemplate returns one of the caller's templates.
+func GetTemplate(c fiber.Ctx) error {
+	template, _, status, msg := loadTemplate(c)
+	if template == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	return c.JSON(template)
+}
+
+// CreateTemplate saves a set of escrow terms for the caller to reuse.
+func CreateTemplate(c fiber.Ctx) error {
+	userID, err := strconv.ParseUint(c.Get("X-User-ID"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Missing X-User-ID",
+		})
+	}
+	var req templateRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	template := model.EscrowTemplate{OwnerID: uint(userID)}
+	req.apply(&template)
+	if err := validateTemplate(&template); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var count int64
+	if err := db.Model(&model.EscrowTemplate{}).Where("owner_id = ?", userID).Count(&count).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create template",
+		})
+	}
+	if count >= maxTemplatesPerUser {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "You can keep at most 100 templates",
+		})
+	}
+	if err := saveTemplate(db, &template); err != nil {
+		if errors.Is(err, errTemplateNameTaken) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create template",
+		})
+	}
+	return c.Status(fiber.StatusCreated).JSON(template)
+}
+
+// UpdateTemplate replaces a template's terms. Its usage count is kept, and
+// escrows already created from it are not affected.
+func UpdateTemplate(c fiber.Ctx) error {
+	template, _, status, msg := loadTemplate(c)
+	if template == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	var req templateRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	req.apply(template)
+	if err := validateTemplate(template); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	db := c.Locals("db").(*gorm.DB)
+	if err := saveTemplate(db, template); err != nil {
+		if errors.Is(err, errTemplateNameTaken) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update template",
+		})
+	}
+	return c.JSON(template)
+}
+
+// DeleteTemplate removes one of the caller's templates, unless a recurring
+// escrow still creates escrows from it.
+func DeleteTemplate(c fiber.Ctx) error {
+	template, _, status, msg := loadTemplate(c)
+	if template == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	db := c.Locals("db").(*gorm.DB)
+	var inUse int64
+	if err := db.Model(&model.RecurringEscrow{}).
+		Where("template_id = ? AND status IN ?", template.ID,
+			[]model.RecurringStatus{model.RecurringActive, model.RecurringPaused}).
+		Count(&inUse).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete template",
+		})
+	}
+	if inUse > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Template is used by a recurring escrow; cancel it first",
+		})
+	}
+	if err := db.Delete(template).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete template",
+		})
+	}
+	return c.JSON(fiber.Map{"message": "Template deleted"})
+}
+
+// templateOverrides are the terms a caller can change when creating an
+// escrow from a template. Fields left out keep the template's value.
+type templateOverrides struct {
+	CounterpartyID        *uint                     `json:"counterparty_id"`
+	Amount                *money.Amount             `json:"amount"`
+	Currency              *model.Currency           `json:"currency"`
+	Conditions            *string                   `json:"conditions"`
+	ConditionsDoc         *model.ConditionsDocument `json:"conditions_doc"`
+	Milestones            []model.Milestone         `json:"milestones"`
+	FundingDeadline       *time.Time                `json:"funding_deadline"`
+	InspectionPeriodHours *int                      `json:"inspection_period_hours"`
+}
+
+// CreateEscrowFromTemplate creates an escrow with the terms of one of the
+// caller's templates and any overrides in the body. The escrow is validated
+// exactly like one sent to CreateEscrow.
+func CreateEscrowFromTemplate(c fiber.Ctx) error {
+	template, userID, status, msg := loadTemplate(c)
+	if template == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	var overrides templateOverrides
+	if len(c.Body()) > 0 {
+		if err := c.Bind().Body(&overrides); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+		}
+	}
+
+	escrow := template.Escrow(time.Now())
+	counterparty := template.CounterpartyID
+	if overrides.CounterpartyID != nil {
+		counterparty = overrides.CounterpartyID
+	}
+	if counterparty != nil {
+		if escrow.InitiatedBy == string(statemachine.Seller) {
+			escrow.BuyerID = *counterparty
+		} else {
+			escrow.SellerID = *counterparty
+		}
+	}
+	if overrides.Milestones != nil {
+		// New milestones set the amount unless it is overridden as well.
+		escrow.Milestones = overrides.Milestones
+		escrow.Amount = 0
+	}
+	if overrides.Amount != nil {
+		escrow.Amount = *overrides.Amount
+	}
+	if overrides.Currency != nil {
+		escrow.Currency = *overrides.Currency
+	}
+	if overrides.Conditions != nil {
+		escrow.Conditions = *overrides.Conditions
+	}
+	if overrides.ConditionsDoc != nil {
+		escrow.ConditionsDoc = overrides.ConditionsDoc
+	}
+	if overrides.FundingDeadline != nil {
+		escrow.FundingDeadline = overrides.FundingDeadline
+	}
+	if overrides.InspectionPeriodHours != nil {
+		escrow.InspectionPeriodHours = *overrides.InspectionPeriodHours
+	}
+
+	return createEscrow(c, escrow, userID, template)
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+	"escrow_service/internal/creation"
+	"escrow_service/internal/payout"
+	"escrow_service/internal/statemachine"
+	"log"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// transitionFailed maps a state machine error onto an HTTP response.
+func transitionFailed(c fiber.Ctx, err error) error {
+	var terr *statemachine.TransitionError
+	if !errors.As(err, &terr) {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update escrow status",
+		})
+	}
+
+	status := fiber.StatusBadRequest
+	switch {
+	case errors.Is(err, statemachine.ErrActorNotAllowed):
+		status = fiber.StatusForbidden
+	case errors.Is(err, statemachine.ErrStaleStatus):
+		status = fiber.StatusConflict
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
+
+// payoutFailed maps an error from the payout package onto an HTTP response.
+func payoutFailed(c fiber.Ctx, err error) error {
+	var terr *statemachine.TransitionError
+	switch {
+	case errors.As(err, &terr):
+		return transitionFailed(c, err)
+	case errors.Is(err, payout.ErrInvalidAccount):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, payout.ErrMilestoneClaimed):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
+
+// creationFailed maps an error from the creation package onto an HTTP
+// response.
+func creationFailed(c fiber.Ctx, err error) error {
+	var cerr *creation.Error
+	if errors.As(err, &cerr) {
+		if cerr.Err != nil {
+			log.Printf("%v", err)
+		}
+		return c.Status(cerr.Status).JSON(fiber.Map{"error": cerr.Message})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": "Failed to create escrow",
+	})
+}
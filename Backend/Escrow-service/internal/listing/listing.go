@@ -0,0 +1,305 @@
+package listing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"escrow_service/internal/fx"
+	"escrow_service/internal/model"
+	"fmt"
+	"shared/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Filter narrows an escrow listing. Zero values mean "no restriction";
+// UserID 0 lists every user's escrows and is only used by admin callers.
+type Filter struct {
+	UserID uint
+	// Role is "buyer" or "seller" to only list escrows where UserID plays
+	// that part.
+	Role           string
+	Statuses       []model.EscrowStatus
+	CounterpartyID uint
+	MinAmount      *money.Amount
+	MaxAmount      *money.Amount
+	CreatedFrom    *time.Time
+	CreatedTo      *time.Time
+	Active         *bool
+}
+
+// Sort is the column the listing is ordered by. The escrow ID breaks ties
+// so the order is total, which keyset pagination relies on.
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Sort fields a listing can be ordered by.
+const (
+	SortCreatedAt = "created_at"
+	SortUpdatedAt = "updated_at"
+	SortAmount    = "amount"
+)
+
+// Validate checks the filter for contradictions.
+func (f *Filter) Validate() error {
+	switch f.Role {
+	case "", "buyer", "seller":
+	default:
+		return errors.New("role must be buyer or seller")
+	}
+	if f.Role != "" && f.UserID == 0 {
+		return errors.New("role needs a user")
+	}
+	if f.CounterpartyID != 0 && f.UserID == 0 {
+		return errors.New("counterparty needs a user")
+	}
+	if f.MinAmount != nil && f.MaxAmount != nil && *f.MinAmount > *f.MaxAmount {
+		return errors.New("min_amount must not exceed max_amount")
+	}
+	if f.CreatedFrom != nil && f.CreatedTo != nil && f.CreatedFrom.After(*f.CreatedTo) {
+		return errors.New("created_from must not be after created_to")
+	}
+	return nil
+}
+
+// ParseSort builds a Sort from the sort and order query values. Listings
+// default to the newest escrows first.
+func ParseSort(field, order string) (Sort, error) {
+	s := Sort{Field: SortCreatedAt, Desc: true}
+	switch field {
+	case "":
+	case SortCreatedAt, SortUpdatedAt, SortAmount:
+		s.Field = field
+	default:
+		return s, fmt.Errorf("cannot sort by %q", field)
+	}
+	switch order {
+	case "", "desc":
+	case "asc":
+		s.Desc = false
+	default:
+		return s, errors.New("order must be asc or desc")
+	}
+	return s, nil
+}
+
+// apply adds the filter's conditions to query.
+func (f *Filter) apply(query *gorm.DB) *gorm.DB {
+	if f.UserID != 0 {
+		switch f.Role {
+		case "buyer":
+			query = query.Where("buyer_id = ?", f.UserID)
+		case "seller":
+			query = query.Where("seller_id = ?", f.UserID)
+		default:
+			query = query.Where("(buyer_id = ? OR seller_id = ?)", f.UserID, f.UserID)
+		}
+		if f.CounterpartyID != 0 {
+			query = query.Where(
+				"((buyer_id = ? AND seller_id = ?) OR (seller_id = ? AND buyer_id = ?))",
+				f.UserID, f.CounterpartyID, f.UserID, f.CounterpartyID,
+			)
+		}
+	}
+	if len(f.Statuses) > 0 {
+		query = query.Where("status IN ?", f.Statuses)
+	}
+	if f.MinAmount != nil {
+		query = query.Where("amount >= ?", *f.MinAmount)
+	}
+	if f.MaxAmount != nil {
+		query = query.Where("amount <= ?", *f.MaxAmount)
+	}
+	if f.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *f.CreatedFrom)
+	}
+	if f.CreatedTo != nil {
+		query = query.Where("created_at <= ?", *f.CreatedTo)
+	}
+	if f.Active != nil {
+		query = query.Where("active = ?", *f.Active)
+	}
+	return query
+}
+
+// cursor marks the last escrow of a page. It carries the sort so a cursor
+// cannot be replayed against a different ordering.
+type cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+}
+
+func (s Sort) key() string {
+	if s.Desc {
+		return s.Field + ":desc"
+	}
+	return s.Field + ":asc"
+}
+
+func encodeCursor(s Sort, e *model.Escrow) string {
+	c := cursor{Sort: s.key(), ID: e.ID}
+	switch s.Field {
+	case SortAmount:
+		c.Value = e.Amount.String()
+	case SortUpdatedAt:
+		c.Value = e.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	default:
+		c.Value = e.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s Sort, raw string) (any, uint, error) {
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, 0, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Sort != s.key() {
+		return nil, 0, ErrInvalidCursor
+	}
+	if s.Field == SortAmount {
+		v, err := money.Parse(c.Value)
+		if err != nil {
+			return nil, 0, ErrInvalidCursor
+		}
+		return v, c.ID, nil
+	}
+	v, err := time.Parse(time.RFC3339Nano, c.Value)
+	if err != nil {
+		return nil, 0, ErrInvalidCursor
+	}
+	return v, c.ID, nil
+}
+
+// Page is one page of a listing. NextCursor is empty on the last page.
+type Page struct {
+	Escrows    []model.Escrow
+	NextCursor string
+}
+
+// List returns up to limit escrows matching the filter, starting after the
+// escrow the cursor points at. Pagination is keyset based, so escrows
+// created while paging neither shift nor repeat rows.
+func List(db *gorm.DB, f Filter, s Sort, limit int, after string) (*Page, error) {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	direction, cmp := "ASC", ">"
+	if s.Desc {
+		direction, cmp = "DESC", "<"
+	}
+
+	query := f.apply(db.Model(&model.Escrow{}))
+	if after != "" {
+		value, id, err := decodeCursor(s, after)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(fmt.Sprintf("(%s, id) %s (?, ?)", s.Field, cmp), value, id)
+	}
+
+	var escrows []model.Escrow
+	err := query.Order(fmt.Sprintf("%s %s, id %s", s.Field, direction, direction)).
+		Limit(limit + 1).
+		Find(&escrows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{Escrows: escrows}
+	if len(escrows) > limit {
+		page.Escrows = escrows[:limit]
+		page.NextCursor = encodeCursor(s, &page.Escrows[limit-1])
+	}
+	return page, nil
+}
+
+// Summary aggregates every escrow matching a filter, regardless of paging.
+// TotalAmount is reported in Currency, converted with the FX rate table;
+// currencies without a rate are listed in MissingRates and left out.
+type Summary struct {
+	Total            int64                   `json:"total"`
+	Active           int64                   `json:"active"`
+	Completed        int64                   `json:"completed"`
+	Disputed         int64                   `json:"disputed"`
+	TotalAmount      money.Amount            `json:"total_amount"`
+	Currency         model.Currency          `json:"currency"`
+	TotalsByCurrency map[string]money.Amount `json:"totals_by_currency"`
+	MissingRates     []string                `json:"missing_rates,omitempty"`
+}
+
+// Summarize computes the summary with one aggregate query for the counts
+// and one for the totals of each currency.
+func Summarize(db *gorm.DB, f Filter, in model.Currency) (*Summary, error) {
+	// Scan zeroes its target, so the counts get a struct of their own.
+	var counts struct {
+		Total     int64
+		Active    int64
+		Completed int64
+		Disputed  int64
+	}
+	err := f.apply(db.Model(&model.Escrow{})).
+		Select(`COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE active) AS active,
+			COUNT(*) FILTER (WHERE status = ?) AS completed,
+			COUNT(*) FILTER (WHERE status = ?) AS disputed`, model.Released, model.Disputed).
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	summary := Summary{
+		Total:            counts.Total,
+		Active:           counts.Active,
+		Completed:        counts.Completed,
+		Disputed:         counts.Disputed,
+		Currency:         in,
+		TotalsByCurrency: map[string]money.Amount{},
+	}
+
+	var totals []struct {
+		Currency model.Currency
+		Amount   money.Amount
+	}
+	err = f.apply(db.Model(&model.Escrow{})).
+		Select("currency, COALESCE(SUM(amount), 0) AS amount").
+		Group("currency").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(totals) == 0 {
+		return &summary, nil
+	}
+
+	rates, err := fx.Load(db)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range totals {
+		summary.TotalsByCurrency[string(t.Currency)] = t.Amount
+		converted, err := rates.Convert(t.Amount, t.Currency, in)
+		if errors.Is(err, fx.ErrNoRate) {
+			summary.MissingRates = append(summary.MissingRates, string(t.Currency))
+			continue
+		}
+		summary.TotalAmount += converted
+	}
+	return &summary, nil
+}
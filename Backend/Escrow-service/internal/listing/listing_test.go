@@ -0,0 +1,251 @@
+package listing
+
+import (
+	"encoding/base64"
+	"errors"
+	"escrow_service/internal/model"
+	"shared/money"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	created := time.Date(2026, 3, 14, 9, 26, 53, 589793000, time.UTC)
+	escrow := &model.Escrow{
+		Model:  gorm.Model{ID: 42, CreatedAt: created, UpdatedAt: created.Add(time.Hour)},
+		Amount: 125050,
+	}
+	tests := []struct {
+		sort Sort
+		want any
+	}{
+		{Sort{Field: SortCreatedAt, Desc: true}, created},
+		{Sort{Field: SortCreatedAt}, created},
+		{Sort{Field: SortUpdatedAt, Desc: true}, created.Add(time.Hour)},
+		{Sort{Field: SortAmount}, money.Amount(125050)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort.key(), func(t *testing.T) {
+			value, id, err := decodeCursor(tt.sort, encodeCursor(tt.sort, escrow))
+			if err != nil {
+				t.Fatalf("decodeCursor() = %v", err)
+			}
+			if id != escrow.ID {
+				t.Errorf("id = %d, want %d", id, escrow.ID)
+			}
+			switch want := tt.want.(type) {
+			case time.Time:
+				if got, ok := value.(time.Time); !ok || !got.Equal(want) {
+					t.Errorf("value = %v, want %v", value, want)
+				}
+			default:
+				if value != want {
+					t.Errorf("value = %v, want %v", value, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	byCreated := Sort{Field: SortCreatedAt, Desc: true}
+	escrow := &model.Escrow{Model: gorm.Model{ID: 7, CreatedAt: time.Now()}, Amount: 100}
+	valid := encodeCursor(byCreated, escrow)
+	raw := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name   string
+		sort   Sort
+		cursor string
+	}{
+		{"not base64", byCreated, "not a cursor!"},
+		{"padded base64", byCreated, base64.URLEncoding.EncodeToString([]byte(`{"s":"created_at:desc"}`)) + "="},
+		{"not json", byCreated, raw("created_at:desc,7")},
+		{"truncated", byCreated, valid[:len(valid)/2]},
+		{"other order", Sort{Field: SortCreatedAt}, valid},
+		{"other field", Sort{Field: SortUpdatedAt, Desc: true}, valid},
+		{"tampered sort", Sort{Field: SortAmount, Desc: true}, raw(`{"s":"amount:desc","v":"2026-01-01T00:00:00Z","id":7}`)},
+		{"bad time", byCreated, raw(`{"s":"created_at:desc","v":"yesterday","id":7}`)},
+		{"bad amount", Sort{Field: SortAmount}, raw(`{"s":"amount:asc","v":"1e9","id":7}`)},
+		{"sql in value", byCreated, raw(`{"s":"created_at:desc","v":"0) OR 1=1 --","id":7}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := decodeCursor(tt.sort, tt.cursor); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("decodeCursor() = %v, want %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestListPagesThroughEqualTimestamps(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&model.Escrow{}, &model.Milestone{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	// Escrows 2 to 6 were created in the same instant, so only their IDs
+	// tell them apart.
+	base := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	created := []time.Time{base.Add(-time.Hour), base, base, base, base, base, base.Add(time.Hour)}
+	for _, at := range created {
+		escrow := model.Escrow{
+			Model:    gorm.Model{CreatedAt: at, UpdatedAt: at},
+			BuyerID:  1,
+			SellerID: 2,
+			Amount:   1000,
+			Currency: "ETB",
+			Status:   model.Pending,
+		}
+		if err := db.Create(&escrow).Error; err != nil {
+			t.Fatalf("create escrow: %v", err)
+		}
+	}
+
+	tests := []struct {
+		sort Sort
+		want []uint
+	}{
+		{Sort{Field: SortCreatedAt, Desc: true}, []uint{7, 6, 5, 4, 3, 2, 1}},
+		{Sort{Field: SortCreatedAt}, []uint{1, 2, 3, 4, 5, 6, 7}},
+		// Every amount is the same: the order falls back to the ID.
+		{Sort{Field: SortAmount, Desc: true}, []uint{7, 6, 5, 4, 3, 2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort.key(), func(t *testing.T) {
+			var got []uint
+			after := ""
+			for pages := 0; ; pages++ {
+				if pages > len(tt.want) {
+					t.Fatalf("paging did not end, got %v so far", got)
+				}
+				page, err := List(db, Filter{}, tt.sort, 2, after)
+				if err != nil {
+					t.Fatalf("List() = %v", err)
+				}
+				for _, e := range page.Escrows {
+					got = append(got, e.ID)
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				after = page.NextCursor
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("paged through %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("paged through %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	if _, err := List(db, Filter{}, Sort{Field: SortCreatedAt}, 2, "garbage"); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("List() with a bad cursor = %v, want %v", err, ErrInvalidCursor)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&model.Escrow{}, &model.Milestone{}, &model.FxRate{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	escrows := []model.Escrow{
+		{BuyerID: 1, SellerID: 2, Amount: 10000, Currency: model.ETB, Status: model.Released, Active: true},
+		{BuyerID: 3, SellerID: 1, Amount: 5000, Currency: model.ETB, Status: model.Disputed, Active: true},
+		{BuyerID: 1, SellerID: 4, Amount: 1000, Currency: model.USD, Status: model.Pending},
+		// Not one of user 1's escrows.
+		{BuyerID: 5, SellerID: 6, Amount: 99900, Currency: model.ETB, Status: model.Released, Active: true},
+	}
+	for i := range escrows {
+		if err := db.Create(&escrows[i]).Error; err != nil {
+			t.Fatalf("create escrow: %v", err)
+		}
+	}
+	if err := db.Create(&model.FxRate{Base: model.USD, Quote: model.ETB, Rate: 57.5}).Error; err != nil {
+		t.Fatalf("create rate: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		currency model.Currency
+		want     Summary
+	}{
+		{
+			"user's escrows in ETB",
+			Filter{UserID: 1},
+			model.ETB,
+			Summary{
+				Total: 3, Active: 2, Completed: 1, Disputed: 1,
+				// 150.00 ETB plus 10.00 USD at 57.5.
+				TotalAmount:      72500,
+				Currency:         model.ETB,
+				TotalsByCurrency: map[string]money.Amount{"ETB": 15000, "USD": 1000},
+			},
+		},
+		{
+			"converted with the inverse rate",
+			Filter{UserID: 1, Statuses: []model.EscrowStatus{model.Released}},
+			model.USD,
+			Summary{
+				Total: 1, Active: 1, Completed: 1,
+				// 100.00 ETB at 1/57.5.
+				TotalAmount:      174,
+				Currency:         model.USD,
+				TotalsByCurrency: map[string]money.Amount{"ETB": 10000},
+			},
+		},
+		{
+			"no escrows",
+			Filter{UserID: 42},
+			model.USD,
+			Summary{Currency: model.USD, TotalsByCurrency: map[string]money.Amount{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Summarize(db, tt.filter, tt.currency)
+			if err != nil {
+				t.Fatalf("Summarize() = %v", err)
+			}
+			if got.Total != tt.want.Total || got.Active != tt.want.Active ||
+				got.Completed != tt.want.Completed || got.Disputed != tt.want.Disputed {
+				t.Errorf("counts = %d/%d/%d/%d, want %d/%d/%d/%d",
+					got.Total, got.Active, got.Completed, got.Disputed,
+					tt.want.Total, tt.want.Active, tt.want.Completed, tt.want.Disputed)
+			}
+			if got.Currency != tt.want.Currency || got.TotalAmount != tt.want.TotalAmount {
+				t.Errorf("total = %s %s, want %s %s", got.TotalAmount, got.Currency, tt.want.TotalAmount, tt.want.Currency)
+			}
+			if got.TotalsByCurrency == nil || len(got.TotalsByCurrency) != len(tt.want.TotalsByCurrency) {
+				t.Fatalf("TotalsByCurrency = %v, want %v", got.TotalsByCurrency, tt.want.TotalsByCurrency)
+			}
+			for c, amount := range tt.want.TotalsByCurrency {
+				if got.TotalsByCurrency[c] != amount {
+					t.Errorf("TotalsByCurrency[%s] = %s, want %s", c, got.TotalsByCurrency[c], amount)
+				}
+			}
+			if len(got.MissingRates) != 0 {
+				t.Errorf("MissingRates = %v, want none", got.MissingRates)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"shared/money"
+
+	"gorm.io/gorm"
+)
+
+type AmendmentStatus string
+
+const (
+	AmendmentProposed   AmendmentStatus = "Proposed"
+	AmendmentAccepted   AmendmentStatus = "Accepted"
+	AmendmentRejected   AmendmentStatus = "Rejected"
+	AmendmentSuperseded AmendmentStatus = "Superseded"
+)
+
+// Amendment is a proposal to change the amount and/or conditions of an
+// escrow before it is funded. Rows are kept after a decision so the escrow
+// has a full record of how its terms evolved; the Previous fields hold the
+// terms that were in force when the proposal was made.
+type Amendment struct {
+	gorm.Model
+	EscrowID           uint            `gorm:"not null;index" json:"escrow_id"`
+	ProposedBy         uint            `gorm:"not null" json:"proposed_by"`
+	DecidedBy          *uint           `json:"decided_by,omitempty"`
+	Amount             *money.Amount   `json:"amount,omitempty"`
+	Conditions         *string         `gorm:"type:text" json:"conditions,omitempty"`
+	PreviousAmount     money.Amount    `json:"previous_amount"`
+	PreviousConditions string          `gorm:"type:text" json:"previous_conditions"`
+	Status             AmendmentStatus `gorm:"type:varchar(16);not null" json:"status"`
+}
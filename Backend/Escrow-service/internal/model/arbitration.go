@@ -0,0 +1,58 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ArbitrationOutcome string
+
+const (
+	OutcomeRelease ArbitrationOutcome = "release"
+	OutcomeRefund  ArbitrationOutcome = "refund"
+	OutcomeSplit   ArbitrationOutcome = "split"
+)
+
+// OutcomeFromAI maps the decision string returned by the AI arbitrator to an
+// outcome. Anything else, including UNDECIDED, maps to "".
+func OutcomeFromAI(decision string) ArbitrationOutcome {
+	switch decision {
+	case "RELEASE_TO_SELLER":
+		return OutcomeRelease
+	case "REFUND_TO_BUYER":
+		return OutcomeRefund
+	}
+	return ""
+}
+
+type ArbitrationStatus string
+
+const (
+	ArbitrationPending   ArbitrationStatus = "Pending"
+	ArbitrationExecuting ArbitrationStatus = "Executing"
+	ArbitrationExecuted  ArbitrationStatus = "Executed"
+	ArbitrationFailed    ArbitrationStatus = "Failed"
+)
+
+// Arbitration is the decision on one dispute. It holds the AI arbitrator's
+// recommendation, once requested, and the outcome an arbitrator chose.
+// FollowedAI is set when the arbitrator approved the recommendation rather
+// than overriding it.
+type Arbitration struct {
+	gorm.Model
+	EscrowID        uint               `gorm:"not null;index" json:"escrow_id"`
+	DisputeID       uint               `gorm:"not null;uniqueIndex" json:"dispute_id"`
+	AIDecision      string             `gorm:"type:varchar(32)" json:"ai_decision,omitempty"`
+	AIOutcome       ArbitrationOutcome `gorm:"type:varchar(16)" json:"ai_outcome,omitempty"`
+	AIJustification string             `gorm:"type:text" json:"ai_justification,omitempty"`
+	RecommendedAt   *time.Time         `json:"recommended_at,omitempty"`
+	Status          ArbitrationStatus  `gorm:"type:varchar(16);not null;index" json:"status"`
+	Outcome         ArbitrationOutcome `gorm:"type:varchar(16)" json:"outcome,omitempty"`
+	SellerPercent   *float64           `json:"seller_percent,omitempty"`
+	FollowedAI      bool               `json:"followed_ai"`
+	ArbitratorID    *uint              `json:"arbitrator_id,omitempty"`
+	Note            string             `gorm:"type:text" json:"note,omitempty"`
+	DecidedAt       *time.Time         `json:"decided_at,omitempty"`
+	FailureReason   string             `gorm:"type:text" json:"failure_reason,omitempty"`
+}
@@ -0,0 +1,126 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type RefundPolicyType string
+
+const (
+	RefundFull    RefundPolicyType = "full"
+	RefundPartial RefundPolicyType = "partial"
+	RefundNone    RefundPolicyType = "none"
+)
+
+const maxDeliverables = 50
+
+// Deliverable is one item of the checklist. Either party can tick it off;
+// DoneBy and DoneAt record who did so last.
+type Deliverable struct {
+	ID          int        `json:"id"`
+	Description string     `json:"description"`
+	Done        bool       `json:"done"`
+	DoneBy      *uint      `json:"done_by,omitempty"`
+	DoneAt      *time.Time `json:"done_at,omitempty"`
+}
+
+// RefundPolicy states what the buyer gets back if the deal falls through.
+// Percent is only used by the partial policy.
+type RefundPolicy struct {
+	Type    RefundPolicyType `json:"type"`
+	Percent float64          `json:"percent,omitempty"`
+	Notes   string           `json:"notes,omitempty"`
+}
+
+// ConditionsDocument is the structured counterpart of Escrow.Conditions. It
+// is stored as JSON and handed to the AI arbitrator as-is.
+type ConditionsDocument struct {
+	Deliverables       []Deliverable `json:"deliverables"`
+	DeliveryDeadline   *time.Time    `json:"delivery_deadline,omitempty"`
+	AcceptanceCriteria []string      `json:"acceptance_criteria,omitempty"`
+	RefundPolicy       *RefundPolicy `json:"refund_policy,omitempty"`
+}
+
+// Prepare validates a document submitted with a new escrow, numbers the
+// deliverables and clears any checklist state the client sent.
+func (d *ConditionsDocument) Prepare(now time.Time) error {
+	if len(d.Deliverables) == 0 {
+		return errors.New("conditions must list at least one deliverable")
+	}
+	if len(d.Deliverables) > maxDeliverables {
+		return fmt.Errorf("conditions can list at most %d deliverables", maxDeliverables)
+	}
+	for i := range d.Deliverables {
+		item := &d.Deliverables[i]
+		item.Description = strings.TrimSpace(item.Description)
+		if item.Description == "" {
+			return fmt.Errorf("deliverable %d needs a description", i+1)
+		}
+		item.ID = i + 1
+		item.Done = false
+		item.DoneBy = nil
+		item.DoneAt = nil
+	}
+
+	if d.DeliveryDeadline != nil && !d.DeliveryDeadline.After(now) {
+		return errors.New("delivery deadline must be in the future")
+	}
+
+	for i, criterion := range d.AcceptanceCriteria {
+		d.AcceptanceCriteria[i] = strings.TrimSpace(criterion)
+		if d.AcceptanceCriteria[i] == "" {
+			return fmt.Errorf("acceptance criterion %d is empty", i+1)
+		}
+	}
+
+	if p := d.RefundPolicy; p != nil {
+		switch p.Type {
+		case RefundFull, RefundNone:
+			p.Percent = 0
+		case RefundPartial:
+			if p.Percent <= 0 || p.Percent >= 100 {
+				return errors.New("partial refund percent must be between 0 and 100")
+			}
+		default:
+			return errors.New("refund policy type must be full, partial or none")
+		}
+	}
+	return nil
+}
+
+// Deliverable returns the checklist item with the given ID.
+func (d *ConditionsDocument) Deliverable(id int) (*Deliverable, bool) {
+	for i := range d.Deliverables {
+		if d.Deliverables[i].ID == id {
+			return &d.Deliverables[i], true
+		}
+	}
+	return nil, false
+}
+
+// Value implements driver.Valuer so the document is stored as JSON.
+func (d ConditionsDocument) Value() (driver.Value, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner.
+func (d *ConditionsDocument) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, d)
+	case string:
+		return json.Unmarshal([]byte(v), d)
+	}
+	return fmt.Errorf("cannot scan %T into ConditionsDocument", src)
+}
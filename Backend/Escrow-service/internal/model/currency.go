@@ -0,0 +1,49 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Currency is an ISO 4217 code that escrows can be denominated in. Only
+// currencies Chapa can collect and pay out are supported.
+type Currency string
+
+const (
+	ETB Currency = "ETB"
+	USD Currency = "USD"
+)
+
+// DefaultCurrency is used for escrows created without a currency, which
+// includes every escrow from before currencies were introduced.
+const DefaultCurrency = ETB
+
+var currencies = []Currency{ETB, USD}
+
+// ParseCurrency normalizes code and checks that it is supported. An empty
+// code means DefaultCurrency.
+func ParseCurrency(code string) (Currency, error) {
+	if code == "" {
+		return DefaultCurrency, nil
+	}
+	c := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	for _, known := range currencies {
+		if c == known {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported currency %q", code)
+}
+
+// FxRate is a locally maintained exchange rate: one unit of Base is worth
+// Rate units of Quote. It is only used for reporting; money always moves in
+// the escrow's own currency.
+type FxRate struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	Base      Currency  `gorm:"type:varchar(3);not null;uniqueIndex:idx_fx_pair" json:"base"`
+	Quote     Currency  `gorm:"type:varchar(3);not null;uniqueIndex:idx_fx_pair" json:"quote"`
+	Rate      float64   `gorm:"not null" json:"rate"`
+	UpdatedBy uint      `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
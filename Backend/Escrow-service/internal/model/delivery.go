@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DeliveryStatus string
+
+const (
+	// DeliveryShipped means the goods are on their way. The inspection
+	// period does not run while they are in transit; the escrow is released
+	// at the end of the transit window instead.
+	DeliveryShipped DeliveryStatus = "shipped"
+	// DeliveryDelivered means the seller reports the goods handed over;
+	// the buyer's inspection period starts.
+	DeliveryDelivered DeliveryStatus = "delivered"
+)
+
+// Delivery is a shipping or delivery update the seller recorded on an
+// escrow. Rows are kept so arbitrators can check "not delivered" claims
+// against them. The optional proof file (a receipt, a photo of the handover)
+// lives in the evidence store under ProofKey; ProofSHA256 is checked again
+// on every download.
+type Delivery struct {
+	gorm.Model
+	EscrowID       uint           `gorm:"not null;index" json:"escrow_id"`
+	SellerID       uint           `gorm:"not null" json:"seller_id"`
+	Status         DeliveryStatus `gorm:"type:varchar(16);not null" json:"status"`
+	Carrier        string         `gorm:"type:varchar(100)" json:"carrier,omitempty"`
+	TrackingNumber string         `gorm:"type:varchar(100)" json:"tracking_number,omitempty"`
+	Note           string         `gorm:"type:text" json:"note,omitempty"`
+
+	ProofFileName    string `gorm:"type:varchar(255)" json:"proof_file_name,omitempty"`
+	ProofContentType string `gorm:"type:varchar(100)" json:"proof_content_type,omitempty"`
+	ProofSize        int64  `json:"proof_size,omitempty"`
+	ProofSHA256      string `gorm:"column:proof_sha256;type:char(64)" json:"proof_sha256,omitempty"`
+	ProofKey         string `gorm:"type:varchar(255)" json:"-"`
+
+	// InspectionDeadline is the deadline the update set on the escrow, if
+	// any.
+	InspectionDeadline *time.Time `json:"inspection_deadline,omitempty"`
+}
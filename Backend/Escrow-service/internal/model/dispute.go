@@ -0,0 +1,65 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DisputeCategory string
+
+const (
+	DisputeNotDelivered   DisputeCategory = "not_delivered"
+	DisputeNotAsDescribed DisputeCategory = "not_as_described"
+	DisputeDamaged        DisputeCategory = "damaged"
+	DisputeLateDelivery   DisputeCategory = "late_delivery"
+	DisputeUnresponsive   DisputeCategory = "unresponsive"
+	DisputeOther          DisputeCategory = "other"
+)
+
+// DisputeCategories lists every category a dispute can be opened with.
+var DisputeCategories = []DisputeCategory{
+	DisputeNotDelivered,
+	DisputeNotAsDescribed,
+	DisputeDamaged,
+	DisputeLateDelivery,
+	DisputeUnresponsive,
+	DisputeOther,
+}
+
+type DisputeStatus string
+
+const (
+	DisputeOpen      DisputeStatus = "Open"
+	DisputeWithdrawn DisputeStatus = "Withdrawn"
+	DisputeResolved  DisputeStatus = "Resolved"
+)
+
+// Dispute records why an escrow was disputed and how the dispute ended. An
+// escrow has at most one open dispute, the one that put it in Disputed.
+// Resolution holds the escrow status the dispute ended with (Refunded,
+// Settled, Released) and is filled in by the state machine.
+type Dispute struct {
+	gorm.Model
+	EscrowID       uint               `gorm:"not null;index" json:"escrow_id"`
+	RaisedBy       uint               `gorm:"not null" json:"raised_by"`
+	RaisedByRole   string             `gorm:"type:varchar(16);not null" json:"raised_by_role"`
+	Category       DisputeCategory    `gorm:"type:varchar(32);not null" json:"category"`
+	Reason         string             `gorm:"type:text;not null" json:"reason"`
+	Status         DisputeStatus      `gorm:"type:varchar(16);not null;index" json:"status"`
+	Resolution     string             `gorm:"type:varchar(32)" json:"resolution,omitempty"`
+	ResolutionNote string             `gorm:"type:text" json:"resolution_note,omitempty"`
+	ResolvedBy     *uint              `json:"resolved_by,omitempty"`
+	ClosedAt       *time.Time         `json:"closed_at,omitempty"`
+	Statements     []DisputeStatement `gorm:"foreignKey:DisputeID" json:"statements,omitempty"`
+}
+
+// DisputeStatement is a written statement added to a dispute by one of the
+// parties.
+type DisputeStatement struct {
+	gorm.Model
+	DisputeID  uint   `gorm:"not null;index" json:"dispute_id"`
+	AuthorID   uint   `gorm:"not null" json:"author_id"`
+	AuthorRole string `gorm:"type:varchar(16);not null" json:"author_role"`
+	Body       string `gorm:"type:text;not null" json:"body"`
+}
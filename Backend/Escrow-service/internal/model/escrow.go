@@ -1,26 +1,83 @@
 package model
 
-import "gorm.io/gorm"
+import (
+    "shared/money"
+    "time"
+
+    "gorm.io/gorm"
+)
 
 type EscrowStatus string
 
 const (
     Pending   EscrowStatus = "Pending"
     Funded    EscrowStatus = "Funded"
+    TransferPending EscrowStatus = "TransferPending"
     Released  EscrowStatus = "Released"
     Disputed  EscrowStatus = "Disputed"
     Refunded  EscrowStatus = "Refunded"
 	Cancelled EscrowStatus = "Cancelled"
+    // Settling is set once a settlement was accepted, while its transfers
+    // to both parties are being made.
+    Settling  EscrowStatus = "Settling"
+    // Settled ends a dispute with the funds split between both parties.
+    Settled   EscrowStatus = "Settled"
+    // Declined is set when an invited party turns the escrow down.
+    Declined  EscrowStatus = "Declined"
 )
 
 type Escrow struct {
     gorm.Model
 	BuyerID    uint           `gorm:"column:buyer_id;not null" json:"buyer_id"`
     SellerID   uint           `gorm:"column:seller_id;not null" json:"seller_id"`
-    Amount     float64        `gorm:"column:amount;not null" json:"amount"`
+    Amount     money.Amount   `gorm:"column:amount;not null" json:"amount"`
+    Currency   Currency       `gorm:"column:currency;type:varchar(3);not null;default:ETB" json:"currency"`
     Status     EscrowStatus   `gorm:"column:status;not null" json:"status"`
     Conditions string         `gorm:"column:conditions" json:"conditions,omitempty"`
+    // ConditionsDoc is the structured, machine-checkable version of the deal.
+    ConditionsDoc *ConditionsDocument `gorm:"column:conditions_doc;type:jsonb" json:"conditions_doc,omitempty"`
     BlockchainTxHash      *string   `gorm:"type:varchar(66);uniqueIndex" json:"blockchain_tx_hash"` 
 	BlockchainEscrowID    *uint64   `gorm:"uniqueIndex" json:"blockchain_escrow_id"`
     Active                bool     `gorm:"default:false"`               
+    // InitiatedBy is "buyer" or "seller", whoever created the escrow.
+    InitiatedBy           string   `gorm:"column:initiated_by;type:varchar(16);default:buyer" json:"initiated_by"`
+    // AwaitingBuyer is set on seller-initiated escrows until the buyer
+    // accepts the terms. They cannot be funded before that.
+    AwaitingBuyer         bool     `gorm:"column:awaiting_buyer;default:false" json:"awaiting_buyer"`
+    DeclineReason         string   `gorm:"column:decline_reason;type:text" json:"decline_reason,omitempty"`
+    Milestones            []Milestone `gorm:"foreignKey:EscrowID" json:"milestones,omitempty"`
+    // PaymentInitiatedAt is when payment-service opened the buyer's checkout.
+    // From then on the amount and conditions can no longer be amended.
+    PaymentInitiatedAt    *time.Time `gorm:"column:payment_initiated_at" json:"payment_initiated_at,omitempty"`
+    // FundingDeadline is when an unfunded escrow is cancelled automatically.
+    FundingDeadline       *time.Time `gorm:"column:funding_deadline;index" json:"funding_deadline,omitempty"`
+    // InspectionPeriodHours is how long the buyer has, once the seller
+    // accepted, to confirm or dispute before the funds are released. When
+    // the seller marks the goods delivered the period starts over from then.
+    InspectionPeriodHours int        `gorm:"column:inspection_period_hours" json:"inspection_period_hours,omitempty"`
+    // InspectionDeadline is when a funded, accepted escrow is released
+    // automatically. While shipped goods are in transit it is the end of
+    // the transit window.
+    InspectionDeadline    *time.Time `gorm:"column:inspection_deadline;index" json:"inspection_deadline,omitempty"`
+    // ShippedAt and DeliveredAt are set when the seller marks the goods
+    // shipped and delivered. See Delivery for the details.
+    ShippedAt             *time.Time `gorm:"column:shipped_at" json:"shipped_at,omitempty"`
+    DeliveredAt           *time.Time `gorm:"column:delivered_at" json:"delivered_at,omitempty"`
+    // ArbitratorID and ArbitrationOutcome are set when a dispute on the
+    // escrow was decided by an arbitrator.
+    ArbitratorID          *uint      `gorm:"column:arbitrator_id" json:"arbitrator_id,omitempty"`
+    ArbitrationOutcome    string     `gorm:"column:arbitration_outcome;type:varchar(16)" json:"arbitration_outcome,omitempty"`
+    // FeeScheduleID is the schedule the platform fee was computed with.
+    // BuyerFee is added to the Chapa checkout and SellerFee is deducted
+    // from what the seller is paid.
+    FeeScheduleID         *uint      `gorm:"column:fee_schedule_id" json:"fee_schedule_id,omitempty"`
+    BuyerFee              money.Amount `gorm:"column:buyer_fee;not null;default:0" json:"buyer_fee"`
+    SellerFee             money.Amount `gorm:"column:seller_fee;not null;default:0" json:"seller_fee"`
+    // RecurringEscrowID is the recurring schedule that spawned the escrow,
+    // and RecurringCycle which of its cycles it is, counting from 1.
+    RecurringEscrowID     *uint      `gorm:"column:recurring_escrow_id;index" json:"recurring_escrow_id,omitempty"`
+    RecurringCycle        int        `gorm:"column:recurring_cycle" json:"recurring_cycle,omitempty"`
+    // PaymentLinkID is the seller's payment link the buyer opened to
+    // create the escrow.
+    PaymentLinkID         *uint      `gorm:"column:payment_link_id;index" json:"payment_link_id,omitempty"`
 }
\ No newline at end of file
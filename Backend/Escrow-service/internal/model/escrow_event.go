@@ -0,0 +1,89 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type EventKind string
+
+const (
+	EventCreated          EventKind = "created"
+	EventLinkedOnChain    EventKind = "linked_on_chain"
+	EventFunded           EventKind = "funded"
+	EventAccepted         EventKind = "accepted"
+	EventBuyerAccepted    EventKind = "buyer_accepted"
+	EventDeclined         EventKind = "declined"
+	EventDisputed         EventKind = "disputed"
+	EventReleaseRequested EventKind = "release_requested"
+	EventReleaseFailed    EventKind = "release_failed"
+	EventReleased         EventKind = "released"
+	EventRefunded         EventKind = "refunded"
+	EventCancelled        EventKind = "cancelled"
+	EventSettled          EventKind = "settled"
+
+	// EventPaymentReturned records a checkout that was sent back because
+	// the escrow could no longer be funded with it.
+	EventPaymentReturned EventKind = "payment_returned"
+
+	EventMilestoneReleaseRequested EventKind = "milestone_release_requested"
+	EventMilestoneReleaseFailed    EventKind = "milestone_release_failed"
+	EventMilestoneReleased         EventKind = "milestone_released"
+
+	EventSettlementProposed EventKind = "settlement_proposed"
+	EventSettlementAccepted EventKind = "settlement_accepted"
+	EventSettlementRejected EventKind = "settlement_rejected"
+
+	EventAmendmentProposed EventKind = "amendment_proposed"
+	EventAmendmentAccepted EventKind = "amendment_accepted"
+	EventAmendmentRejected EventKind = "amendment_rejected"
+
+	EventDeliverableUpdated EventKind = "deliverable_updated"
+
+	EventShipped   EventKind = "shipped"
+	EventDelivered EventKind = "delivered"
+
+	EventDisputeWithdrawn EventKind = "dispute_withdrawn"
+	EventDisputeStatement EventKind = "dispute_statement"
+	EventEvidenceAdded    EventKind = "evidence_added"
+
+	EventAIRecommendation EventKind = "ai_recommendation"
+	EventArbitrated       EventKind = "arbitrated"
+)
+
+type EventSource string
+
+const (
+	SourceHTTP     EventSource = "http"
+	SourceConsumer EventSource = "consumer"
+	SourceGRPC     EventSource = "grpc"
+	// SourceScheduler marks transitions made when a deadline expired.
+	SourceScheduler EventSource = "scheduler"
+)
+
+var ErrEventImmutable = errors.New("escrow events are append-only")
+
+// EscrowEvent is one entry in an escrow's audit history. Rows are only ever
+// inserted; updates and deletes are rejected by the hooks below.
+type EscrowEvent struct {
+	ID         uint         `gorm:"primaryKey" json:"id"`
+	EscrowID   uint         `gorm:"not null;index" json:"escrow_id"`
+	Kind       EventKind    `gorm:"type:varchar(32);not null" json:"kind"`
+	ActorID    uint         `gorm:"not null;default:0" json:"actor_id"`
+	ActorRole  string       `gorm:"type:varchar(16);not null" json:"actor_role"`
+	FromStatus EscrowStatus `gorm:"type:varchar(32)" json:"from_status,omitempty"`
+	ToStatus   EscrowStatus `gorm:"type:varchar(32)" json:"to_status,omitempty"`
+	Source     EventSource  `gorm:"type:varchar(16);not null" json:"source"`
+	Note       string       `gorm:"type:text" json:"note,omitempty"`
+	CreatedAt  time.Time    `gorm:"index" json:"created_at"`
+}
+
+func (e *EscrowEvent) BeforeUpdate(tx *gorm.DB) error {
+	return ErrEventImmutable
+}
+
+func (e *EscrowEvent) BeforeDelete(tx *gorm.DB) error {
+	return ErrEventImmutable
+}
@@ -0,0 +1,20 @@
+package model
+
+import "gorm.io/gorm"
+
+// Evidence is a file attached to a dispute. The content lives in the
+// evidence store under StorageKey; SHA256 is taken at upload time and
+// checked again on every download.
+type Evidence struct {
+	gorm.Model
+	EscrowID     uint   `gorm:"not null;index" json:"escrow_id"`
+	DisputeID    uint   `gorm:"not null;index" json:"dispute_id"`
+	UploadedBy   uint   `gorm:"not null" json:"uploaded_by"`
+	UploaderRole string `gorm:"type:varchar(16);not null" json:"uploader_role"`
+	FileName     string `gorm:"type:varchar(255);not null" json:"file_name"`
+	ContentType  string `gorm:"type:varchar(100);not null" json:"content_type"`
+	Size         int64  `gorm:"not null" json:"size"`
+	SHA256       string `gorm:"column:sha256;type:char(64);not null" json:"sha256"`
+	StorageKey   string `gorm:"type:varchar(255);not null" json:"-"`
+	Description  string `gorm:"type:text" json:"description,omitempty"`
+}
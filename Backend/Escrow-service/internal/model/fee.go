@@ -0,0 +1,64 @@
+package model
+
+import (
+	"shared/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FeePayer is who a fee schedule charges.
+type FeePayer string
+
+const (
+	FeePayerBuyer  FeePayer = "buyer"
+	FeePayerSeller FeePayer = "seller"
+	// FeePayerSplit charges each party half of the fee.
+	FeePayerSplit FeePayer = "split"
+)
+
+// FeeSchedule describes how the platform fee of an escrow is computed: a
+// percentage of the amount plus a fixed amount, clamped to [MinFee, MaxFee].
+// New escrows use the single active schedule of their currency; escrows
+// keep the schedule they were created with.
+type FeeSchedule struct {
+	gorm.Model
+	Name string `gorm:"type:varchar(64);not null;uniqueIndex" json:"name"`
+	// Currency is the currency of the fixed amounts and of the escrows the
+	// schedule applies to.
+	Currency    Currency     `gorm:"type:varchar(3);not null;default:ETB" json:"currency"`
+	Percent     float64      `gorm:"not null;default:0" json:"percent"`
+	FixedAmount money.Amount `gorm:"not null;default:0" json:"fixed_amount"`
+	MinFee      money.Amount `gorm:"not null;default:0" json:"min_fee"`
+	// MaxFee of 0 means the fee is not capped.
+	MaxFee money.Amount `gorm:"not null;default:0" json:"max_fee"`
+	Payer  FeePayer     `gorm:"type:varchar(8);not null" json:"payer"`
+	Active bool         `gorm:"not null;default:false;index" json:"active"`
+}
+
+type FeeEntryKind string
+
+const (
+	// FeeCharged is recorded when a fee is collected: the buyer's share
+	// with the Chapa checkout, the seller's share when it is deducted from
+	// a transfer.
+	FeeCharged FeeEntryKind = "charged"
+	// FeeRefunded reverses a buyer fee returned with a full refund.
+	FeeRefunded FeeEntryKind = "refunded"
+)
+
+// FeeLedgerEntry is one movement of platform fee money. Refunds are
+// recorded with a negative amount so the ledger sums to the revenue.
+type FeeLedgerEntry struct {
+	ID         uint         `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time    `json:"created_at"`
+	EscrowID   uint         `gorm:"not null;index" json:"escrow_id"`
+	ScheduleID *uint        `json:"schedule_id,omitempty"`
+	Payer      FeePayer     `gorm:"type:varchar(8);not null;uniqueIndex:idx_fee_ledger_ref" json:"payer"`
+	Kind       FeeEntryKind `gorm:"type:varchar(16);not null;uniqueIndex:idx_fee_ledger_ref" json:"kind"`
+	Amount     money.Amount `gorm:"not null" json:"amount"`
+	Currency   Currency     `gorm:"type:varchar(3);not null;default:ETB" json:"currency"`
+	// Reference is the Chapa checkout or transfer the fee moved with. It
+	// makes recording a fee idempotent.
+	Reference string `gorm:"type:varchar(64);not null;uniqueIndex:idx_fee_ledger_ref" json:"reference"`
+}
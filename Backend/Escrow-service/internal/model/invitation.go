@@ -0,0 +1,144 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"shared/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type InvitationStatus string
+
+const (
+	InvitationPending   InvitationStatus = "pending"
+	InvitationClaimed   InvitationStatus = "claimed"
+	InvitationExpired   InvitationStatus = "expired"
+	InvitationCancelled InvitationStatus = "cancelled"
+)
+
+// EscrowInvitation is an escrow offered to a seller by email, for sellers
+// who do not have an account yet. No escrow exists until the invitee signs
+// up, adds bank details and a wallet, and claims the invitation with the
+// token they were mailed; the escrow is then created with the stored terms
+// like any other. Unclaimed invitations expire at ExpiresAt.
+type EscrowInvitation struct {
+	gorm.Model
+	BuyerID uint   `gorm:"not null;index" json:"buyer_id"`
+	Email   string `gorm:"type:varchar(255);not null;index" json:"email"`
+	// TokenHash is the SHA-256 of the token sent to Email; the token
+	// itself is not stored.
+	TokenHash string           `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	Terms     InvitationTerms  `gorm:"type:jsonb;not null" json:"terms"`
+	Status    InvitationStatus `gorm:"type:varchar(16);not null;index" json:"status"`
+	ExpiresAt time.Time        `gorm:"not null;index" json:"expires_at"`
+	// SellerID and EscrowID are set once the invitation is claimed.
+	SellerID  *uint      `json:"seller_id,omitempty"`
+	EscrowID  *uint      `json:"escrow_id,omitempty"`
+	ClaimedAt *time.Time `json:"claimed_at,omitempty"`
+}
+
+// InvitationTerms are the terms of the escrow an invitation offers. A nil
+// FundingDeadline means the default funding window, counted from when the
+// invitation is claimed.
+type InvitationTerms struct {
+	Amount                money.Amount          `json:"amount"`
+	Currency              Currency              `json:"currency"`
+	Conditions            string                `json:"conditions,omitempty"`
+	ConditionsDoc         *ConditionsDocument   `json:"conditions_doc,omitempty"`
+	Milestones            []InvitationMilestone `json:"milestones,omitempty"`
+	FundingDeadline       *time.Time            `json:"funding_deadline,omitempty"`
+	InspectionPeriodHours int                   `json:"inspection_period_hours,omitempty"`
+}
+
+type InvitationMilestone struct {
+	Description string       `json:"description"`
+	Amount      money.Amount `json:"amount"`
+	DueDate     *time.Time   `json:"due_date,omitempty"`
+}
+
+// Value implements driver.Valuer.
+func (t InvitationTerms) Value() (driver.Value, error) {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner.
+func (t *InvitationTerms) Scan(src any) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	}
+	return fmt.Errorf("cannot scan %T into InvitationTerms", src)
+}
+
+// TermsOf returns the terms of an escrow submitted for an invitation.
+func TermsOf(escrow *Escrow) InvitationTerms {
+	terms := InvitationTerms{
+		Amount:                escrow.Amount,
+		Currency:              escrow.Currency,
+		Conditions:            escrow.Conditions,
+		ConditionsDoc:         escrow.ConditionsDoc,
+		FundingDeadline:       escrow.FundingDeadline,
+		InspectionPeriodHours: escrow.InspectionPeriodHours,
+	}
+	for _, m := range escrow.Milestones {
+		terms.Milestones = append(terms.Milestones, InvitationMilestone{
+			Description: m.Description,
+			Amount:      m.Amount,
+			DueDate:     m.DueDate,
+		})
+	}
+	return terms
+}
+
+// Escrow returns a new buyer-initiated escrow with the invitation's terms.
+// The parties are left for the caller to set.
+func (t *InvitationTerms) Escrow() *Escrow {
+	escrow := &Escrow{
+		Amount:                t.Amount,
+		Currency:              t.Currency,
+		Conditions:            t.Conditions,
+		InitiatedBy:           "buyer",
+		FundingDeadline:       t.FundingDeadline,
+		InspectionPeriodHours: t.InspectionPeriodHours,
+	}
+	if t.ConditionsDoc != nil {
+		doc := *t.ConditionsDoc
+		escrow.ConditionsDoc = &doc
+	}
+	for _, m := range t.Milestones {
+		escrow.Milestones = append(escrow.Milestones, Milestone{
+			Description: m.Description,
+			Amount:      m.Amount,
+			DueDate:     m.DueDate,
+		})
+	}
+	return escrow
+}
+
+// LatestExpiry returns the last moment the invitation can be claimed
+// without its terms having gone stale: before the funding deadline, the
+// delivery deadline and the first milestone due date, if any.
+func (t *InvitationTerms) LatestExpiry(expiresAt time.Time) time.Time {
+	if t.FundingDeadline != nil && t.FundingDeadline.Before(expiresAt) {
+		expiresAt = *t.FundingDeadline
+	}
+	if t.ConditionsDoc != nil && t.ConditionsDoc.DeliveryDeadline != nil &&
+		t.ConditionsDoc.DeliveryDeadline.Before(expiresAt) {
+		expiresAt = *t.ConditionsDoc.DeliveryDeadline
+	}
+	for _, m := range t.Milestones {
+		if m.DueDate != nil && m.DueDate.Before(expiresAt) {
+			expiresAt = *m.DueDate
+		}
+	}
+	return expiresAt
+}
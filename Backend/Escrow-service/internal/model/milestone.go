@@ -0,0 +1,29 @@
+package model
+
+import (
+	"shared/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type MilestoneStatus string
+
+const (
+	MilestonePending         MilestoneStatus = "Pending"
+	MilestoneTransferPending MilestoneStatus = "TransferPending"
+	MilestoneReleased        MilestoneStatus = "Released"
+)
+
+// Milestone is one independently releasable part of an escrow. The escrow
+// Amount is always the sum of its milestone amounts.
+type Milestone struct {
+	gorm.Model
+	EscrowID    uint            `gorm:"not null;index" json:"escrow_id"`
+	Sequence    int             `gorm:"not null" json:"sequence"`
+	Description string          `gorm:"type:text;not null" json:"description"`
+	Amount      money.Amount    `gorm:"not null" json:"amount"`
+	DueDate     *time.Time      `json:"due_date,omitempty"`
+	Status      MilestoneStatus `gorm:"type:varchar(32);not null" json:"status"`
+	TransferRef *string         `gorm:"type:varchar(64)" json:"transfer_ref,omitempty"`
+}
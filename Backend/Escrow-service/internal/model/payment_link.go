@@ -0,0 +1,70 @@
+package model
+
+import (
+	"shared/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PaymentLink is a seller's standing offer that any buyer can take up by
+// opening its link, e.g. "pay 2,500 ETB for X". Each buyer who does gets
+// an escrow of their own with the link's terms.
+type PaymentLink struct {
+	gorm.Model
+	SellerID uint `gorm:"not null;index" json:"seller_id"`
+	// Code identifies the link in its URL.
+	Code          string              `gorm:"type:varchar(16);not null;uniqueIndex" json:"code"`
+	Title         string              `gorm:"type:varchar(120);not null" json:"title"`
+	Amount        money.Amount        `gorm:"not null" json:"amount"`
+	Currency      Currency            `gorm:"type:varchar(3);not null;default:ETB" json:"currency"`
+	Conditions    string              `gorm:"type:text" json:"conditions,omitempty"`
+	ConditionsDoc *ConditionsDocument `gorm:"type:jsonb" json:"conditions_doc,omitempty"`
+	// FundingWindowHours and InspectionPeriodHours are counted from when a
+	// buyer opens the link; 0 means the platform default.
+	FundingWindowHours    int `json:"funding_window_hours,omitempty"`
+	InspectionPeriodHours int `json:"inspection_period_hours,omitempty"`
+	// Quantity caps how many escrows can be open on the link at once;
+	// escrows that were cancelled or declined free their unit again. 0
+	// means no limit.
+	Quantity  int        `gorm:"not null;default:0" json:"quantity,omitempty"`
+	ExpiresAt *time.Time `gorm:"index" json:"expires_at,omitempty"`
+	Active    bool       `gorm:"not null;default:true" json:"active"`
+	// Opens counts how often buyers opened the link.
+	Opens int `gorm:"not null;default:0" json:"opens"`
+}
+
+// Escrow returns a new buyer-initiated escrow with the link's terms, its
+// deadlines resolved against now. The buyer is left for the caller to set.
+func (l *PaymentLink) Escrow(now time.Time) *Escrow {
+	linkID := l.ID
+	escrow := &Escrow{
+		SellerID:              l.SellerID,
+		Amount:                l.Amount,
+		Currency:              l.Currency,
+		Conditions:            l.Conditions,
+		InitiatedBy:           "buyer",
+		InspectionPeriodHours: l.InspectionPeriodHours,
+		PaymentLinkID:         &linkID,
+	}
+	if l.FundingWindowHours > 0 {
+		fundBy := now.Add(time.Duration(l.FundingWindowHours) * time.Hour)
+		escrow.FundingDeadline = &fundBy
+	}
+	if l.ConditionsDoc != nil {
+		doc := *l.ConditionsDoc
+		doc.Deliverables = append([]Deliverable(nil), doc.Deliverables...)
+		doc.AcceptanceCriteria = append([]string(nil), doc.AcceptanceCriteria...)
+		if doc.RefundPolicy != nil {
+			policy := *doc.RefundPolicy
+			doc.RefundPolicy = &policy
+		}
+		escrow.ConditionsDoc = &doc
+	}
+	return escrow
+}
+
+// Available reports whether buyers can still open the link.
+func (l *PaymentLink) Available(now time.Time) bool {
+	return l.Active && (l.ExpiresAt == nil || now.Before(*l.ExpiresAt))
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"shared/money"
+
+	"gorm.io/gorm"
+)
+
+type PaymentReturnStatus string
+
+const (
+	PaymentReturnPending PaymentReturnStatus = "Pending"
+	// PaymentReturnSending is a return claimed by the scheduler whose
+	// transfer is being started.
+	PaymentReturnSending PaymentReturnStatus = "Sending"
+	PaymentReturnSent    PaymentReturnStatus = "Sent"
+	// PaymentReturnFailed is a return whose transfer could not be started.
+	// The scheduler retries it.
+	PaymentReturnFailed PaymentReturnStatus = "Failed"
+)
+
+// PaymentReturn is a checkout that arrived for an escrow it could not fund,
+// e.g. one that was cancelled or declined while the buyer was paying. The
+// scheduler sends it back to the buyer. TransactionRef is the checkout's
+// reference, so a redelivered payment.success is only returned once.
+type PaymentReturn struct {
+	gorm.Model
+	EscrowID       uint                `gorm:"not null;index" json:"escrow_id"`
+	TransactionRef string              `gorm:"type:varchar(64);not null;uniqueIndex" json:"transaction_ref"`
+	Amount         money.Amount        `gorm:"not null" json:"amount"`
+	Currency       Currency            `gorm:"type:varchar(3);not null" json:"currency"`
+	Reason         string              `gorm:"type:text" json:"reason"`
+	Status         PaymentReturnStatus `gorm:"type:varchar(16);not null" json:"status"`
+	TransferRef    *string             `gorm:"type:varchar(64)" json:"transfer_ref,omitempty"`
+	FailureReason  string              `gorm:"type:text" json:"failure_reason,omitempty"`
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RecurrenceInterval string
+
+const (
+	RecurWeekly  RecurrenceInterval = "weekly"
+	RecurMonthly RecurrenceInterval = "monthly"
+)
+
+type RecurringStatus string
+
+const (
+	RecurringActive    RecurringStatus = "active"
+	RecurringPaused    RecurringStatus = "paused"
+	RecurringCancelled RecurringStatus = "cancelled"
+	// RecurringCompleted is set once the last cycle was created.
+	RecurringCompleted RecurringStatus = "completed"
+)
+
+// RecurringEscrow creates an escrow from one of its owner's templates with
+// the same counterparty every week or month, e.g. for a retainer. It ends
+// after MaxCycles escrows or at EndsAt, whichever comes first, or when
+// either party cancels it.
+type RecurringEscrow struct {
+	gorm.Model
+	OwnerID        uint               `gorm:"not null;index" json:"owner_id"`
+	TemplateID     uint               `gorm:"not null;index" json:"template_id"`
+	CounterpartyID uint               `gorm:"not null;index" json:"counterparty_id"`
+	Interval       RecurrenceInterval `gorm:"type:varchar(16);not null" json:"interval"`
+	StartsAt       time.Time          `gorm:"not null" json:"starts_at"`
+	EndsAt         *time.Time         `json:"ends_at,omitempty"`
+	// MaxCycles is how many escrows to create; 0 means no limit.
+	MaxCycles int `gorm:"not null;default:0" json:"max_cycles,omitempty"`
+	// Cycles is how many escrows were created so far.
+	Cycles int `gorm:"not null;default:0" json:"cycles"`
+	// NextPeriod is the number of intervals from StartsAt to the next
+	// run. It runs ahead of Cycles when cycles were skipped while paused.
+	NextPeriod   int             `gorm:"not null;default:0" json:"-"`
+	NextRunAt    *time.Time      `gorm:"index" json:"next_run_at,omitempty"`
+	Status       RecurringStatus `gorm:"type:varchar(16);not null;index" json:"status"`
+	LastEscrowID *uint           `json:"last_escrow_id,omitempty"`
+	// LastError says why the schedule was paused automatically.
+	LastError string `gorm:"type:text" json:"last_error,omitempty"`
+}
+
+// RunAt returns when the run period intervals after StartsAt is due.
+// Monthly runs keep the day of the month of StartsAt, or take the last day
+// of shorter months.
+func (r *RecurringEscrow) RunAt(period int) time.Time {
+	if r.Interval == RecurWeekly {
+		return r.StartsAt.AddDate(0, 0, 7*period)
+	}
+	start := r.StartsAt
+	first := time.Date(start.Year(), start.Month()+time.Month(period), 1,
+		start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(start.Day(), lastDay)-1)
+}
+
+// NextRun returns when the run of the given period is due, or nil when the
+// schedule ends before it.
+func (r *RecurringEscrow) NextRun(period int) *time.Time {
+	if r.MaxCycles > 0 && r.Cycles >= r.MaxCycles {
+		return nil
+	}
+	at := r.RunAt(period)
+	if r.EndsAt != nil && at.After(*r.EndsAt) {
+		return nil
+	}
+	return &at
+}
+
+// PeriodAfter returns the first period at or after period whose run is not
+// before now.
+func (r *RecurringEscrow) PeriodAfter(period int, now time.Time) int {
+	for r.RunAt(period).Before(now) {
+		period++
+	}
+	return period
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"shared/money"
+
+	"gorm.io/gorm"
+)
+
+type SettlementStatus string
+
+const (
+	SettlementProposed SettlementStatus = "Proposed"
+	SettlementRejected SettlementStatus = "Rejected"
+	// SettlementPaying is an accepted settlement whose transfers are being
+	// made.
+	SettlementPaying   SettlementStatus = "Paying"
+	SettlementExecuted SettlementStatus = "Executed"
+	// SettlementFailed is an accepted settlement with a transfer that could
+	// not be started. The scheduler retries the missing transfers.
+	SettlementFailed SettlementStatus = "Failed"
+)
+
+// Settlement is a proposal to resolve a disputed escrow by splitting the
+// outstanding funds between seller and buyer. Once executed it records the
+// Chapa references of both transfers.
+type Settlement struct {
+	gorm.Model
+	EscrowID      uint         `gorm:"not null;index" json:"escrow_id"`
+	ProposedBy    uint         `gorm:"not null" json:"proposed_by"`
+	DecidedBy     *uint        `json:"decided_by,omitempty"`
+	SellerPercent float64      `gorm:"not null" json:"seller_percent"`
+	SellerAmount  money.Amount `json:"seller_amount"`
+	// SellerFee is the platform fee deducted from SellerAmount.
+	SellerFee         money.Amount     `json:"seller_fee"`
+	BuyerAmount       money.Amount     `json:"buyer_amount"`
+	Status            SettlementStatus `gorm:"type:varchar(16);not null" json:"status"`
+	SellerTransferRef *string          `gorm:"type:varchar(64)" json:"seller_transfer_ref,omitempty"`
+	BuyerTransferRef  *string          `gorm:"type:varchar(64)" json:"buyer_transfer_ref,omitempty"`
+	FailureReason     string           `gorm:"type:text" json:"failure_reason,omitempty"`
+}
@@ -0,0 +1,115 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"shared/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EscrowTemplate is a reusable set of escrow terms owned by one user.
+// Deadlines are kept relative to the moment an escrow is created from it.
+// Escrows copy the terms, so editing a template never changes them.
+type EscrowTemplate struct {
+	gorm.Model
+	OwnerID uint   `gorm:"not null;index" json:"owner_id"`
+	Name    string `gorm:"type:varchar(100);not null" json:"name"`
+	// InitiatedBy is the owner's side of the deal, "buyer" or "seller".
+	InitiatedBy string `gorm:"type:varchar(16);not null;default:buyer" json:"initiated_by"`
+	// CounterpartyID is the other party, if the template is always used
+	// with the same one.
+	CounterpartyID *uint               `json:"counterparty_id,omitempty"`
+	Amount         money.Amount        `gorm:"not null" json:"amount"`
+	Currency       Currency            `gorm:"type:varchar(3);not null;default:ETB" json:"currency"`
+	Conditions     string              `gorm:"type:text" json:"conditions,omitempty"`
+	ConditionsDoc  *ConditionsDocument `gorm:"type:jsonb" json:"conditions_doc,omitempty"`
+	Milestones     TemplateMilestones  `gorm:"type:jsonb" json:"milestones,omitempty"`
+	// FundingWindowHours, DeliveryWindowHours and InspectionPeriodHours are
+	// counted from when an escrow is created; 0 means the platform default
+	// (or, for delivery, no deadline).
+	FundingWindowHours    int        `json:"funding_window_hours,omitempty"`
+	DeliveryWindowHours   int        `json:"delivery_window_hours,omitempty"`
+	InspectionPeriodHours int        `json:"inspection_period_hours,omitempty"`
+	UsageCount            int        `gorm:"not null;default:0" json:"usage_count"`
+	LastUsedAt            *time.Time `json:"last_used_at,omitempty"`
+}
+
+// TemplateMilestone is a milestone of a template. DueInHours is counted
+// from when an escrow is created; 0 means no due date.
+type TemplateMilestone struct {
+	Description string       `json:"description"`
+	Amount      money.Amount `json:"amount"`
+	DueInHours  int          `json:"due_in_hours,omitempty"`
+}
+
+// TemplateMilestones is stored as a JSON array.
+type TemplateMilestones []TemplateMilestone
+
+// Value implements driver.Valuer.
+func (m TemplateMilestones) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner.
+func (m *TemplateMilestones) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, m)
+	case string:
+		return json.Unmarshal([]byte(v), m)
+	}
+	return fmt.Errorf("cannot scan %T into TemplateMilestones", src)
+}
+
+// Escrow returns a new escrow with the template's terms, its deadlines
+// resolved against now. The parties are left for the caller to set.
+func (t *EscrowTemplate) Escrow(now time.Time) *Escrow {
+	escrow := &Escrow{
+		Amount:                t.Amount,
+		Currency:              t.Currency,
+		Conditions:            t.Conditions,
+		InitiatedBy:           t.InitiatedBy,
+		InspectionPeriodHours: t.InspectionPeriodHours,
+	}
+	if t.FundingWindowHours > 0 {
+		fundBy := now.Add(time.Duration(t.FundingWindowHours) * time.Hour)
+		escrow.FundingDeadline = &fundBy
+	}
+	if t.ConditionsDoc != nil {
+		doc := *t.ConditionsDoc
+		doc.Deliverables = append([]Deliverable(nil), doc.Deliverables...)
+		doc.AcceptanceCriteria = append([]string(nil), doc.AcceptanceCriteria...)
+		if doc.RefundPolicy != nil {
+			policy := *doc.RefundPolicy
+			doc.RefundPolicy = &policy
+		}
+		doc.DeliveryDeadline = nil
+		if t.DeliveryWindowHours > 0 {
+			deliverBy := now.Add(time.Duration(t.DeliveryWindowHours) * time.Hour)
+			doc.DeliveryDeadline = &deliverBy
+		}
+		escrow.ConditionsDoc = &doc
+	}
+	for _, m := range t.Milestones {
+		milestone := Milestone{Description: m.Description, Amount: m.Amount}
+		if m.DueInHours > 0 {
+			due := now.Add(time.Duration(m.DueInHours) * time.Hour)
+			milestone.DueDate = &due
+		}
+		escrow.Milestones = append(escrow.Milestones, milestone)
+	}
+	return escrow
+}
@@ -0,0 +1,93 @@
+package payout
+
+import (
+	"errors"
+	"escrow_service/internal/auth"
+	"escrow_service/internal/model"
+	"escrow_service/internal/payment"
+	"fmt"
+	"log"
+	"shared/chapa"
+	"shared/money"
+)
+
+// ErrInvalidAccount marks problems with the recipient's account that the
+// caller can fix, as opposed to connectivity failures.
+var ErrInvalidAccount = errors.New("invalid payout account")
+
+// Account holds the bank details a Chapa transfer is sent to.
+type Account struct {
+	Name     string
+	Number   string
+	BankCode int
+}
+
+// LookupAccount loads and validates the bank details of userID. role is only
+// used to phrase error messages ("Seller", "Buyer").
+func LookupAccount(userID uint, role string) (*Account, error) {
+	userServiceClient, err := auth.NewUserServiceClient("user-service:50051")
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to user service: %v", err)
+	}
+	defer userServiceClient.Close()
+
+	user, err := userServiceClient.GetUser(uint32(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s info: %v", role, err)
+	}
+	if !user.Activated {
+		return nil, fmt.Errorf("%w: %s account is not activated", ErrInvalidAccount, role)
+	}
+	if user.AccountName == nil || user.AccountName.Value == "" ||
+		user.AccountNumber == nil || user.AccountNumber.Value == "" ||
+		user.BankCode == nil || user.BankCode.Value == 0 {
+		return nil, fmt.Errorf("%w: %s has not added bank account details", ErrInvalidAccount, role)
+	}
+	if err := chapa.ValidateAccount(int(user.BankCode.GetValue()), user.AccountNumber.GetValue()); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAccount, err)
+	}
+
+	return &Account{
+		Name:     user.AccountName.Value,
+		Number:   user.AccountNumber.Value,
+		BankCode: int(user.BankCode.Value),
+	}, nil
+}
+
+// Transfer starts a Chapa transfer of amount, in currency, to the account.
+func Transfer(recipientID uint, account *Account, amount money.Amount, currency model.Currency, reference string) (*chapa.TransferResponse, error) {
+	chapaClient := chapa.NewClient()
+	return chapaClient.TransferToSeller(
+		recipientID,
+		amount,
+		string(currency),
+		reference,
+		account.Name,
+		account.Number,
+		account.BankCode,
+	)
+}
+
+// Send starts a Chapa transfer of amount to the account and hands the
+// reference to payment-service, which publishes transfer.success.
+func Send(recipientID uint, account *Account, amount money.Amount, currency model.Currency, reference string) error {
+	resp, err := Transfer(recipientID, account, amount, currency, reference)
+	if err != nil {
+		return err
+	}
+
+	paymentClient, err := payment.NewPaymentServiceClient("payment-service:50053")
+	if err != nil {
+		log.Printf("Failed to connect to payment service: %v", err)
+		return nil
+	}
+	response, err := paymentClient.Finalize(resp.Data)
+	if err != nil {
+		log.Printf("gRPC Finalize error: %v ", err)
+		return nil
+	}
+	if !response.Success {
+		log.Printf("Finalize failed: %s", response.Error)
+	}
+	return nil
+}
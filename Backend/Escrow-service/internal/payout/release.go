@@ -0,0 +1,225 @@
+package payout
+
+import (
+	"errors"
+	"escrow_service/internal/fee"
+	"escrow_service/internal/model"
+	"escrow_service/internal/statemachine"
+	"fmt"
+	"log"
+	"shared/money"
+
+	"gorm.io/gorm"
+)
+
+// ErrMilestoneClaimed is returned when another request already started
+// releasing the milestone.
+var ErrMilestoneClaimed = errors.New("milestone is already being released")
+
+// ReleaseEscrow pays the escrow amount, less the seller's fee, to the
+// seller. The escrow is
+// moved to TransferPending before any money leaves so a repeated call cannot
+// start a second transfer; it becomes Released once payment-service reports
+// transfer.success.
+func ReleaseEscrow(db *gorm.DB, escrow *model.Escrow, t statemachine.Trigger) error {
+	if err := statemachine.Check(escrow.Status, model.TransferPending, t.Actor); err != nil {
+		return err
+	}
+
+	account, err := LookupAccount(escrow.SellerID, "Seller")
+	if err != nil {
+		return err
+	}
+
+	if err := statemachine.Fire(db, escrow, model.TransferPending, t); err != nil {
+		return err
+	}
+
+	if err := PaySeller(db, escrow, account, escrow.Amount, fmt.Sprintf("escrow-%d", escrow.ID), true); err != nil {
+		rollbackEscrow(db, escrow, t.Source)
+		return fmt.Errorf("failed to initiate transfer: %v", err)
+	}
+	return nil
+}
+
+// ReleaseMilestone pays a single milestone to the seller. When it was the
+// last pending milestone the escrow itself moves to TransferPending, and it
+// is released once every milestone transfer has succeeded. The returned
+// bool reports whether this was that final milestone.
+func ReleaseMilestone(db *gorm.DB, escrow *model.Escrow, milestone *model.Milestone, t statemachine.Trigger) (bool, error) {
+	if err := statemachine.Check(escrow.Status, model.TransferPending, t.Actor); err != nil {
+		return false, err
+	}
+
+	account, err := LookupAccount(escrow.SellerID, "Seller")
+	if err != nil {
+		return false, err
+	}
+
+	reference := fmt.Sprintf("escrow-%d-milestone-%d", escrow.ID, milestone.ID)
+	claimed := false
+	err = db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.Milestone{}).
+			Where("id = ? AND status = ?", milestone.ID, model.MilestonePending).
+			Updates(map[string]any{"status": model.MilestoneTransferPending, "transfer_ref": reference})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		claimed = true
+		return tx.Create(&model.EscrowEvent{
+			EscrowID:   escrow.ID,
+			Kind:       model.EventMilestoneReleaseRequested,
+			ActorID:    t.UserID,
+			ActorRole:  string(t.Actor),
+			FromStatus: escrow.Status,
+			ToStatus:   escrow.Status,
+			Source:     t.Source,
+			Note:       reference,
+		}).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	if !claimed {
+		return false, ErrMilestoneClaimed
+	}
+
+	var remaining int64
+	db.Model(&model.Milestone{}).
+		Where("escrow_id = ? AND status = ?", escrow.ID, model.MilestonePending).
+		Count(&remaining)
+	final := remaining == 0
+	if final {
+		ft := t
+		ft.Note = "last milestone confirmed"
+		if err := statemachine.Fire(db, escrow, model.TransferPending, ft); err != nil {
+			// Nothing was sent yet: give the milestone back rather than pay
+			// out an escrow whose status moved on, e.g. to Disputed.
+			rollbackMilestone(db, escrow, milestone, false, t.Source)
+			return final, err
+		}
+	}
+
+	if err := PaySeller(db, escrow, account, milestone.Amount, reference, final); err != nil {
+		rollbackMilestone(db, escrow, milestone, final, t.Source)
+		return final, fmt.Errorf("failed to initiate transfer: %v", err)
+	}
+	return final, nil
+}
+
+// PaySeller sends gross to the seller minus the share of the seller's fee
+// it carries, and records that share in the fee ledger. final marks the
+// last payout of the escrow, which takes whatever fee is still owed.
+func PaySeller(db *gorm.DB, escrow *model.Escrow, account *Account, gross money.Amount, reference string, final bool) error {
+	share, err := fee.SellerShare(db, escrow, gross, final)
+	if err != nil {
+		return fmt.Errorf("failed to compute seller fee: %v", err)
+	}
+	if err := Send(escrow.SellerID, account, gross-share, escrow.Currency, reference); err != nil {
+		return err
+	}
+	if err := fee.Record(db, escrow, model.FeePayerSeller, model.FeeCharged, share, reference); err != nil {
+		log.Printf("Failed to record seller fee for escrow %d: %v", escrow.ID, err)
+	}
+	return nil
+}
+
+// rollbackEscrow returns an escrow to Funded after its transfer could not be
+// started.
+func rollbackEscrow(db *gorm.DB, escrow *model.Escrow, source model.EventSource) {
+	err := statemachine.Fire(db, escrow, model.Funded, statemachine.Trigger{
+		Actor:  statemachine.System,
+		Source: source,
+		Note:   "transfer could not be initiated",
+	})
+	if err != nil {
+		log.Printf("Failed to roll back escrow %d to Funded: %v", escrow.ID, err)
+	}
+}
+
+// rollbackMilestone returns a milestone (and, for the final one, the escrow)
+// to its pre-confirmation state after the transfer could not be started.
+func rollbackMilestone(db *gorm.DB, escrow *model.Escrow, milestone *model.Milestone, final bool, source model.EventSource) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Milestone{}).
+			Where("id = ?", milestone.ID).
+			Updates(map[string]any{"status": model.MilestonePending, "transfer_ref": nil}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.EscrowEvent{
+			EscrowID:   escrow.ID,
+			Kind:       model.EventMilestoneReleaseFailed,
+			ActorRole:  string(statemachine.System),
+			FromStatus: escrow.Status,
+			ToStatus:   escrow.Status,
+			Source:     source,
+			Note:       fmt.Sprintf("milestone %d", milestone.ID),
+		}).Error
+	})
+	if err != nil {
+		log.Printf("Failed to roll back milestone %d: %v", milestone.ID, err)
+	}
+
+	if final && escrow.Status == model.TransferPending {
+		rollbackEscrow(db, escrow, source)
+	}
+}
+
+// RefundBuyer moves the escrow to the given terminal status (Cancelled,
+// Declined, Refunded) and sends amount back to the buyer. The status is
+// claimed with a conditional update first and the transfer is only started
+// once the claim succeeded, inside the same transaction: a concurrent refund
+// or release finds the status changed and sends nothing, and a failed
+// transfer rolls the status back. within, if not nil, runs in that
+// transaction too, before the transfer.
+func RefundBuyer(db *gorm.DB, escrow *model.Escrow, amount money.Amount, to model.EscrowStatus, reference string, t statemachine.Trigger, within func(tx *gorm.DB) error) error {
+	if err := statemachine.Check(escrow.Status, to, t.Actor); err != nil {
+		return err
+	}
+
+	account, err := LookupAccount(escrow.BuyerID, "Buyer")
+	if err != nil {
+		return err
+	}
+
+	sent := false
+	err = statemachine.FireWith(db, escrow, to, t, func(tx *gorm.DB) error {
+		if within != nil {
+			if err := within(tx); err != nil {
+				return err
+			}
+		}
+		if err := Send(escrow.BuyerID, account, amount, escrow.Currency, reference); err != nil {
+			return fmt.Errorf("failed to initiate refund: %w", err)
+		}
+		sent = true
+		return nil
+	})
+	if err != nil && sent {
+		// The money is on its way but the status could not be committed.
+		log.Printf("Refund %s of escrow %d was sent but the escrow could not be marked %s: %v", reference, escrow.ID, to, err)
+		return fmt.Errorf("refund %s was sent but escrow %d could not be marked %s: %w", reference, escrow.ID, to, err)
+	}
+	return err
+}
+
+// RefundInFull returns everything the buyer paid, including the buyer's
+// fee, for escrows that end before the seller delivered anything
+// (Cancelled, Declined). The returned fee is reversed in the ledger in the
+// same transaction as the status change; within is passed on to
+// RefundBuyer.
+func RefundInFull(db *gorm.DB, escrow *model.Escrow, to model.EscrowStatus, reference string, t statemachine.Trigger, within func(tx *gorm.DB) error) error {
+	buyerFee, err := fee.Charged(db, escrow.ID, model.FeePayerBuyer)
+	if err != nil {
+		return fmt.Errorf("failed to look up buyer fee: %v", err)
+	}
+	return RefundBuyer(db, escrow, escrow.Amount+buyerFee, to, reference, t, func(tx *gorm.DB) error {
+		if err := fee.Record(tx, escrow, model.FeePayerBuyer, model.FeeRefunded, -buyerFee, reference); err != nil {
+			return fmt.Errorf("failed to record buyer fee refund: %w", err)
+		}
+		if within != nil {
+			return within(tx)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,46 @@
+package payout
+
+import (
+	"escrow_service/internal/model"
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// ReturnPayment sends a payment that could not fund its escrow back to the
+// buyer. The caller claims ret (status Sending) first; it ends up Sent with
+// the transfer reference, or Failed with the reason so the scheduler
+// retries it.
+func ReturnPayment(db *gorm.DB, escrow *model.Escrow, ret *model.PaymentReturn) error {
+	reference := fmt.Sprintf("return-escrow-%d-%d", escrow.ID, ret.ID)
+	err := returnPayment(escrow, ret, reference)
+	if err != nil {
+		ret.Status = model.PaymentReturnFailed
+		ret.FailureReason = err.Error()
+	} else {
+		ret.Status = model.PaymentReturnSent
+		ret.TransferRef = &reference
+		ret.FailureReason = ""
+	}
+	if serr := db.Model(ret).Updates(map[string]any{
+		"status":         ret.Status,
+		"transfer_ref":   ret.TransferRef,
+		"failure_reason": ret.FailureReason,
+	}).Error; serr != nil {
+		// A return left Sending is not retried, so it cannot be sent twice.
+		log.Printf("Failed to record payment return %d as %s: %v", ret.ID, ret.Status, serr)
+	}
+	return err
+}
+
+func returnPayment(escrow *model.Escrow, ret *model.PaymentReturn, reference string) error {
+	account, err := LookupAccount(escrow.BuyerID, "Buyer")
+	if err != nil {
+		return err
+	}
+	if _, err := Transfer(escrow.BuyerID, account, ret.Amount, ret.Currency, reference); err != nil {
+		return fmt.Errorf("failed to return payment %s: %v", ret.TransactionRef, err)
+	}
+	return nil
+}
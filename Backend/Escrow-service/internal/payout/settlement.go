@@ -0,0 +1,151 @@
+package payout
+
+import (
+	"errors"
+	"escrow_service/internal/fee"
+	"escrow_service/internal/model"
+	"escrow_service/internal/rabbitmq"
+	"escrow_service/internal/statemachine"
+	"fmt"
+	"log"
+	"message_broker/rabbitmq/events"
+	"shared/money"
+
+	"gorm.io/gorm"
+)
+
+// PaySettlement makes the transfers of an accepted settlement whose escrow
+// is Settling: the seller's share less their fee, and the buyer's share.
+// Each leg is claimed by storing its reference in the transaction that
+// starts its transfer, so a retry only repeats the legs that are still
+// missing. The escrow moves to Settled
+// once both legs went out; until then the settlement is left Failed with
+// the reason, and the scheduler retries it.
+func PaySettlement(db *gorm.DB, escrow *model.Escrow, settlement *model.Settlement, source model.EventSource) error {
+	var transferErr error
+	if settlement.SellerAmount > 0 && settlement.SellerTransferRef == nil {
+		ref := fmt.Sprintf("settle-escrow-%d-seller", escrow.ID)
+		err := payLeg(db, escrow, settlement, "seller_transfer_ref", escrow.SellerID, "Seller",
+			settlement.SellerAmount-settlement.SellerFee, ref, func(tx *gorm.DB) error {
+				return fee.Record(tx, escrow, model.FeePayerSeller, model.FeeCharged, settlement.SellerFee, ref)
+			})
+		if err != nil {
+			transferErr = fmt.Errorf("seller transfer: %v", err)
+		} else {
+			settlement.SellerTransferRef = &ref
+		}
+	}
+	if settlement.BuyerAmount > 0 && settlement.BuyerTransferRef == nil {
+		ref := fmt.Sprintf("settle-escrow-%d-buyer", escrow.ID)
+		err := payLeg(db, escrow, settlement, "buyer_transfer_ref", escrow.BuyerID, "Buyer",
+			settlement.BuyerAmount, ref, nil)
+		if err != nil {
+			transferErr = errors.Join(transferErr, fmt.Errorf("buyer transfer: %v", err))
+		} else {
+			settlement.BuyerTransferRef = &ref
+		}
+	}
+
+	if transferErr != nil {
+		settlement.Status = model.SettlementFailed
+		settlement.FailureReason = transferErr.Error()
+		err := db.Model(settlement).Updates(map[string]any{
+			"status":         settlement.Status,
+			"failure_reason": settlement.FailureReason,
+		}).Error
+		if err != nil {
+			log.Printf("Failed to mark settlement %d failed: %v", settlement.ID, err)
+		}
+		return transferErr
+	}
+
+	event := &events.EscrowSettledEvent{
+		EscrowID:      uint64(escrow.ID),
+		SettlementID:  uint64(settlement.ID),
+		BuyerID:       uint32(escrow.BuyerID),
+		SellerID:      uint32(escrow.SellerID),
+		SellerPercent: settlement.SellerPercent,
+		SellerAmount:  settlement.SellerAmount,
+		BuyerAmount:   settlement.BuyerAmount,
+		Currency:      string(escrow.Currency),
+	}
+	if settlement.SellerTransferRef != nil {
+		event.SellerTransferRef = *settlement.SellerTransferRef
+	}
+	if settlement.BuyerTransferRef != nil {
+		event.BuyerTransferRef = *settlement.BuyerTransferRef
+	}
+
+	err := statemachine.FireWith(db, escrow, model.Settled, statemachine.Trigger{
+		Actor:  statemachine.System,
+		Source: source,
+		Note:   fmt.Sprintf("settlement %d", settlement.ID),
+	}, func(tx *gorm.DB) error {
+		if err := tx.Model(settlement).Updates(map[string]any{
+			"status":         model.SettlementExecuted,
+			"failure_reason": "",
+		}).Error; err != nil {
+			return err
+		}
+		return rabbitmq.NewProducer(tx).PublishEscrowSettled(event)
+	})
+	if err != nil {
+		// Both transfers are on their way; the scheduler finishes the
+		// transition on its next pass without sending anything again.
+		log.Printf("Settlement %d of escrow %d was paid but the escrow could not be marked %s: %v", settlement.ID, escrow.ID, model.Settled, err)
+		return fmt.Errorf("settlement %d was paid but escrow %d could not be marked %s: %w", settlement.ID, escrow.ID, model.Settled, err)
+	}
+	settlement.Status = model.SettlementExecuted
+	settlement.FailureReason = ""
+	return nil
+}
+
+// payLeg claims one leg of a settlement by storing its transfer reference,
+// and starts the transfer in the same transaction: a leg whose reference
+// cannot be stored is not paid, and a transfer that fails releases the
+// claim so the leg is retried. within, if not nil, runs in that transaction
+// too, before the transfer.
+func payLeg(db *gorm.DB, escrow *model.Escrow, settlement *model.Settlement, column string, recipientID uint, role string, amount money.Amount, ref string, within func(tx *gorm.DB) error) error {
+	account, err := LookupAccount(recipientID, role)
+	if err != nil {
+		return err
+	}
+
+	claim := func(tx *gorm.DB) error {
+		res := tx.Model(&model.Settlement{}).
+			Where("id = ? AND "+column+" IS NULL", settlement.ID).
+			Update(column, ref)
+		if res.Error != nil {
+			return fmt.Errorf("failed to store %s: %w", column, res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("%s of settlement %d was already claimed", column, settlement.ID)
+		}
+		if within != nil {
+			return within(tx)
+		}
+		return nil
+	}
+
+	sent := false
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := claim(tx); err != nil {
+			return err
+		}
+		if _, err := Transfer(recipientID, account, amount, escrow.Currency, ref); err != nil {
+			return err
+		}
+		sent = true
+		return nil
+	})
+	if err != nil && sent {
+		// The money is on its way but the claim could not be committed:
+		// store it again so a retry does not send the leg twice.
+		if cerr := db.Transaction(claim); cerr == nil {
+			return nil
+		}
+		log.Printf("Transfer %s of settlement %d was sent but %s could not be stored: %v", ref, settlement.ID, column, err)
+		return fmt.Errorf("transfer %s was sent but could not be recorded: %w", ref, err)
+	}
+	return err
+}
@@ -4,16 +4,22 @@ import (
 	blockchain "blockchain_adapter"
 	"context"
 	"encoding/json"
+	"escrow_service/internal/fee"
 	"escrow_service/internal/model"
+	"escrow_service/internal/statemachine"
 	"escrow_service/utils"
+	"fmt"
 	"log"
 	"math/big"
 	"message_broker/rabbitmq/events"
+	"shared/money"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/streadway/amqp"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Consumer struct {
@@ -93,23 +99,120 @@ func (c *Consumer) Listen() {
                 var event events.PaymentSuccessEvent
                 json.Unmarshal(msg.Body, &event)
 
-                
-                var escrow model.Escrow
-				//log.Printf("event escrowId:%d",event.EscrowID) //will be commented out
-                if err := c.DB.First(&escrow, event.EscrowID).Error; err != nil {
-                    log.Printf("Escrow not found: %d", event.EscrowID)
-                    continue
-                }
-
-                escrow.Status = model.Funded
-                c.DB.Save(&escrow)
-
-                log.Printf("✅ Escrow %d updated to Funded", escrow.ID)
+                c.fund(&event)
             }
         }
     }()
 }
 
+// fund moves the escrow paid by a payment.success event to Funded. A
+// payment that cannot fund it is queued to be returned to the buyer.
+func (c *Consumer) fund(event *events.PaymentSuccessEvent) {
+	var escrow model.Escrow
+	if err := c.DB.First(&escrow, event.EscrowID).Error; err != nil {
+		log.Printf("Escrow not found: %d", event.EscrowID)
+		return
+	}
+
+	// The checkout must cover the escrow's current terms, which cannot be
+	// amended once it was opened.
+	due := escrow.Amount + escrow.BuyerFee
+	if event.Amount != due || (event.Currency != "" && model.Currency(event.Currency) != escrow.Currency) {
+		log.Printf("Rejected payment.success for escrow %d: paid %s %s, due %s %s",
+			escrow.ID, event.Amount, event.Currency, due, escrow.Currency)
+		c.queueReturn(&escrow, event, fmt.Sprintf("paid %s %s, due %s %s", event.Amount, event.Currency, due, escrow.Currency))
+		return
+	}
+
+	if err := statemachine.Fire(c.DB, &escrow, model.Funded, statemachine.Trigger{
+		Actor:  statemachine.System,
+		Source: model.SourceConsumer,
+		Note:   event.TransactionRef,
+	}); err != nil {
+		log.Printf("Rejected payment.success for escrow %d: %v", escrow.ID, err)
+		c.returnUnapplied(event)
+		return
+	}
+
+	// The checkout included the buyer's share of the fee.
+	if err := fee.Record(c.DB, &escrow, model.FeePayerBuyer, model.FeeCharged, escrow.BuyerFee, event.TransactionRef); err != nil {
+		log.Printf("Failed to record buyer fee for escrow %d: %v", escrow.ID, err)
+	}
+
+	// A seller-initiated escrow was already accepted by the buyer, so its
+	// inspection period starts with the payment.
+	if escrow.Active && escrow.InspectionDeadline == nil {
+		inspectUntil := time.Now().Add(time.Duration(escrow.InspectionPeriodHours) * time.Hour)
+		if err := c.DB.Model(&escrow).Update("inspection_deadline", inspectUntil).Error; err != nil {
+			log.Printf("Failed to set inspection deadline for escrow %d: %v", escrow.ID, err)
+		}
+	}
+
+	log.Printf("✅ Escrow %d updated to Funded", escrow.ID)
+}
+
+// returnUnapplied queues a payment that could not fund its escrow to be sent
+// back to the buyer, unless it already funded the escrow and this is a
+// redelivery. An escrow that is still Pending failed to be funded for
+// another reason and is left for the payment to be redelivered.
+func (c *Consumer) returnUnapplied(event *events.PaymentSuccessEvent) {
+	var escrow model.Escrow
+	if err := c.DB.First(&escrow, event.EscrowID).Error; err != nil {
+		log.Printf("Escrow not found: %d", event.EscrowID)
+		return
+	}
+	if escrow.Status == model.Pending {
+		return
+	}
+	var funded int64
+	if err := c.DB.Model(&model.EscrowEvent{}).
+		Where("escrow_id = ? AND kind = ? AND note = ?", escrow.ID, model.EventFunded, event.TransactionRef).
+		Count(&funded).Error; err != nil {
+		log.Printf("Failed to check how escrow %d was funded: %v", escrow.ID, err)
+		return
+	}
+	if funded > 0 {
+		return
+	}
+	c.queueReturn(&escrow, event, fmt.Sprintf("escrow is %s", escrow.Status))
+}
+
+// queueReturn records a payment.success that did not fund the escrow as a
+// PaymentReturn for the scheduler to send back.
+func (c *Consumer) queueReturn(escrow *model.Escrow, event *events.PaymentSuccessEvent, reason string) {
+	currency := model.Currency(event.Currency)
+	if currency == "" {
+		currency = escrow.Currency
+	}
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.PaymentReturn{
+			EscrowID:       escrow.ID,
+			TransactionRef: event.TransactionRef,
+			Amount:         event.Amount,
+			Currency:       currency,
+			Reason:         reason,
+			Status:         model.PaymentReturnPending,
+		})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return tx.Create(&model.EscrowEvent{
+			EscrowID:   escrow.ID,
+			Kind:       model.EventPaymentReturned,
+			ActorRole:  string(statemachine.System),
+			FromStatus: escrow.Status,
+			ToStatus:   escrow.Status,
+			Source:     model.SourceConsumer,
+			Note:       event.TransactionRef + ": " + reason,
+		}).Error
+	})
+	if err != nil {
+		log.Printf("Failed to queue return of payment %s for escrow %d: %v", event.TransactionRef, escrow.ID, err)
+		return
+	}
+	log.Printf("Payment %s for escrow %d will be returned: %s", event.TransactionRef, escrow.ID, reason)
+}
+
 func (c *Consumer) ListenForTransferEvents() {
 	queue, err := c.Channel.QueueDeclare(
 		"escrow_transfer_queue",
@@ -162,9 +265,27 @@ func (c *Consumer) ListenForTransferEvents() {
 					continue
 				}
 
-				
-				escrow.Status = model.Released
-				c.DB.Save(&escrow)
+				// A milestone payout only releases the escrow once it was
+				// the last outstanding one.
+				if event.MilestoneID != 0 {
+					allReleased, err := c.releaseMilestone(&escrow, event)
+					if err != nil {
+						log.Printf("Failed to release milestone %d: %v", event.MilestoneID, err)
+						continue
+					}
+					if !allReleased {
+						continue
+					}
+				}
+
+				if err := statemachine.Fire(c.DB, &escrow, model.Released, statemachine.Trigger{
+					Actor:  statemachine.System,
+					Source: model.SourceConsumer,
+					Note:   event.TransferID,
+				}); err != nil {
+					log.Printf("Rejected transfer.success for escrow %d: %v", escrow.ID, err)
+					continue
+				}
 				log.Printf("Escrow updated to Released in DB")
                 if err := utils.AddContact(c.DB, escrow.BuyerID, escrow.SellerID, escrow.ID); err != nil {
                         log.Printf("Failed to add contact: %v", err)
@@ -205,6 +326,39 @@ func (c *Consumer) ListenForTransferEvents() {
 	}()
 }
 
+// releaseMilestone marks the milestone paid by a transfer.success event as
+// Released and reports whether every milestone of the escrow is now released.
+func (c *Consumer) releaseMilestone(escrow *model.Escrow, event events.TransferSuccessEvent) (bool, error) {
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.Milestone{}).
+			Where("id = ? AND escrow_id = ? AND status = ?", event.MilestoneID, escrow.ID, model.MilestoneTransferPending).
+			Update("status", model.MilestoneReleased)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return tx.Create(&model.EscrowEvent{
+			EscrowID:   escrow.ID,
+			Kind:       model.EventMilestoneReleased,
+			ActorRole:  string(statemachine.System),
+			FromStatus: escrow.Status,
+			ToStatus:   escrow.Status,
+			Source:     model.SourceConsumer,
+			Note:       event.TransferID,
+		}).Error
+	})
+	if err != nil {
+		return false, err
+	}
+
+	var outstanding int64
+	if err := c.DB.Model(&model.Milestone{}).
+		Where("escrow_id = ? AND status <> ?", escrow.ID, model.MilestoneReleased).
+		Count(&outstanding).Error; err != nil {
+		return false, err
+	}
+	return outstanding == 0, nil
+}
+
 func (c *Consumer) StartEscrowWorker() {
 	queue, err := c.Channel.QueueDeclare(
 		"escrow_worker_queue",
@@ -218,15 +372,17 @@ func (c *Consumer) StartEscrowWorker() {
 		log.Fatalf("Failed to declare escrow_worker_queue: %v", err)
 	}
 
-	err = c.Channel.QueueBind(
-		queue.Name,
-		"escrow.create",
-		"safe_deal_exchange",
-		false,
-		nil,
-	)
-	if err != nil {
-		log.Fatalf("Failed to bind escrow.create: %v", err)
+	for _, key := range []string{"escrow.create", "escrow.amended"} {
+		err = c.Channel.QueueBind(
+			queue.Name,
+			key,
+			"safe_deal_exchange",
+			false,
+			nil,
+		)
+		if err != nil {
+			log.Fatalf("Failed to bind %s: %v", key, err)
+		}
 	}
 
 	msgs, err := c.Channel.Consume(
@@ -244,69 +400,155 @@ func (c *Consumer) StartEscrowWorker() {
 
 	go func() {
 		for msg := range msgs {
-			var event events.CreateEscrowEvent
-			if err := json.Unmarshal(msg.Body, &event); err != nil {
-				log.Printf("Failed to unmarshal CreateEscrowEvent: %v", err)
-				continue
-			}
-
 			if c. blockchainClient == nil {
 				log.Println("Blockchain client not initialized")
 				continue
 			}
 
-			buyerAddr := common.HexToAddress(event.BuyerAddr)
-			sellerAddr := common.HexToAddress(event.SellerAddr)
-			amount := new(big.Int).SetUint64(uint64(event.Amount * 100))
-
-			
-			tx, err := c.blockchainClient.Contract.CreateEscrow(
-				c.blockchainClient.Auth,
-				buyerAddr,
-				sellerAddr,
-				amount,
-			)
-			if err != nil {
-				log.Printf("Failed to create on-chain escrow: %v", err)
+			var base events.BaseEvent
+			if err := json.Unmarshal(msg.Body, &base); err != nil {
+				log.Printf("Failed to unmarshal event: %v", err)
 				continue
 			}
 
-			
-			receipt, err := bind.WaitMined(context.Background(), c.blockchainClient.Client, tx)
-			if err != nil {
-				log.Printf("Mining failed: %v", err)
-				continue
-			}
-
-			
-			var escrowID *big.Int
-			for _, vLog := range receipt.Logs {
-				e, err := c.blockchainClient.Contract.ParseEscrowCreated(*vLog)
-				if err == nil && e != nil {
-					escrowID = e.Id
-					break
+			switch base.Type {
+			case "escrow.create":
+				var event events.CreateEscrowEvent
+				if err := json.Unmarshal(msg.Body, &event); err != nil {
+					log.Printf("Failed to unmarshal CreateEscrowEvent: %v", err)
+					continue
 				}
+				// escrow.create is delivered at least once: a redelivery
+				// of an escrow that is already linked must not create a
+				// second on-chain record.
+				var linked int64
+				if err := c.DB.Model(&model.Escrow{}).
+					Where("id = ? AND blockchain_escrow_id IS NOT NULL", event.ID).
+					Count(&linked).Error; err != nil {
+					log.Printf("Failed to check escrow %d on-chain: %v", event.ID, err)
+					continue
+				}
+				if linked > 0 {
+					log.Printf("Escrow %d is already on-chain, ignoring %s", event.ID, msg.MessageId)
+					continue
+				}
+				c.createOnChain(
+					uint(event.ID),
+					common.HexToAddress(event.BuyerAddr),
+					common.HexToAddress(event.SellerAddr),
+					event.Amount,
+				)
+			case "escrow.amended":
+				var event events.EscrowAmendedEvent
+				if err := json.Unmarshal(msg.Body, &event); err != nil {
+					log.Printf("Failed to unmarshal EscrowAmendedEvent: %v", err)
+					continue
+				}
+				c.amendOnChain(&event)
 			}
+		}
+	}()
+}
 
-			if escrowID == nil {
-				log.Printf("Failed to get escrow ID from logs")
-				continue
-			}
+// createOnChain records the escrow on the contract and links the record to
+// the DB row. If the amount was amended while the transaction was mining,
+// the record is stale: it is not linked and a new one is created instead.
+func (c *Consumer) createOnChain(escrowID uint, buyerAddr, sellerAddr common.Address, amount money.Amount) {
+	tx, err := c.blockchainClient.Contract.CreateEscrow(
+		c.blockchainClient.Auth,
+		buyerAddr,
+		sellerAddr,
+		amount.BigInt(),
+	)
+	if err != nil {
+		log.Printf("Failed to create on-chain escrow: %v", err)
+		return
+	}
 
-			
-			var escrow model.Escrow
-			if err := c.DB.First(&escrow, "id = ?", event.ID).Error; err != nil {
-				log.Printf("Escrow not found: %d", event.ID)
-				continue
-			}
-            txHash := tx.Hash().Hex()
-			id := escrowID.Uint64()
-			escrow.BlockchainEscrowID = &id
-			escrow.BlockchainTxHash = &txHash
-			escrow.Status = model.Pending
-			c.DB.Save(&escrow)
-
-			log.Printf("✅ Escrow created on-chain")
+	receipt, err := bind.WaitMined(context.Background(), c.blockchainClient.Client, tx)
+	if err != nil {
+		log.Printf("Mining failed: %v", err)
+		return
+	}
+
+	var onChainID *big.Int
+	for _, vLog := range receipt.Logs {
+		e, err := c.blockchainClient.Contract.ParseEscrowCreated(*vLog)
+		if err == nil && e != nil {
+			onChainID = e.Id
+			break
 		}
-	}()
-}
\ No newline at end of file
+	}
+	if onChainID == nil {
+		log.Printf("Failed to get escrow ID from logs")
+		return
+	}
+
+	var escrow model.Escrow
+	if err := c.DB.First(&escrow, "id = ?", escrowID).Error; err != nil {
+		log.Printf("Escrow not found: %d", escrowID)
+		return
+	}
+	if escrow.Amount != amount {
+		if escrow.Status == model.Pending {
+			log.Printf("Escrow %d was amended while on-chain record %s was mining, recreating", escrow.ID, onChainID)
+			c.createOnChain(escrowID, buyerAddr, sellerAddr, escrow.Amount)
+		}
+		return
+	}
+
+	txHash := tx.Hash().Hex()
+	id := onChainID.Uint64()
+	// Only link the on-chain record; the status may already have
+	// moved on if payment.success arrived while the tx was mining.
+	err = c.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&escrow).Updates(model.Escrow{
+			BlockchainEscrowID: &id,
+			BlockchainTxHash:   &txHash,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.EscrowEvent{
+			EscrowID:   escrow.ID,
+			Kind:       model.EventLinkedOnChain,
+			ActorRole:  string(statemachine.System),
+			FromStatus: escrow.Status,
+			ToStatus:   escrow.Status,
+			Source:     model.SourceConsumer,
+			Note:       txHash,
+		}).Error
+	})
+	if err != nil {
+		log.Printf("Failed to link escrow %d on-chain: %v", escrow.ID, err)
+		return
+	}
+
+	log.Printf("✅ Escrow created on-chain")
+}
+
+// amendOnChain replaces the on-chain record of an escrow whose amount was
+// amended. The contract cannot change a record's amount, so a new record is
+// created for the same parties and linked in place of the old one, which
+// stays unpaid. Escrows that are not linked yet are reconciled by
+// createOnChain once their first record is mined.
+func (c *Consumer) amendOnChain(event *events.EscrowAmendedEvent) {
+	if !event.AmountChanged {
+		return
+	}
+
+	var escrow model.Escrow
+	if err := c.DB.First(&escrow, "id = ?", event.EscrowID).Error; err != nil {
+		log.Printf("Escrow not found: %d", event.EscrowID)
+		return
+	}
+	if escrow.BlockchainEscrowID == nil {
+		return
+	}
+
+	record, err := c.blockchainClient.Contract.GetEscrow(nil, new(big.Int).SetUint64(*escrow.BlockchainEscrowID))
+	if err != nil {
+		log.Printf("Failed to read on-chain escrow %d: %v", *escrow.BlockchainEscrowID, err)
+		return
+	}
+	c.createOnChain(escrow.ID, record.Buyer, record.Seller, escrow.Amount)
+}
@@ -0,0 +1,123 @@
+package rabbitmq
+
+import (
+	"escrow_service/internal/model"
+	"escrow_service/internal/statemachine"
+	"message_broker/rabbitmq/events"
+	"shared/money"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestConsumer(t *testing.T) *Consumer {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&model.Escrow{}, &model.Milestone{}, &model.EscrowEvent{}, &model.PaymentReturn{}, &model.FeeLedgerEntry{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return &Consumer{DB: db}
+}
+
+func TestReturnUnapplied(t *testing.T) {
+	c := newTestConsumer(t)
+
+	create := func(status model.EscrowStatus) *model.Escrow {
+		escrow := &model.Escrow{BuyerID: 1, SellerID: 2, Amount: 10000, BuyerFee: 250, Currency: model.ETB, Status: status}
+		if err := c.DB.Create(escrow).Error; err != nil {
+			t.Fatalf("create escrow: %v", err)
+		}
+		return escrow
+	}
+	paid := func(escrow *model.Escrow, txRef string) *events.PaymentSuccessEvent {
+		return events.NewPaymentSuccessEvent(txRef, uint32(escrow.ID), uint32(escrow.BuyerID), 10250, "ETB")
+	}
+	returns := func(escrow *model.Escrow) []model.PaymentReturn {
+		var rets []model.PaymentReturn
+		if err := c.DB.Where("escrow_id = ?", escrow.ID).Find(&rets).Error; err != nil {
+			t.Fatalf("load returns: %v", err)
+		}
+		return rets
+	}
+
+	// Cancelled at the funding deadline while the buyer was paying.
+	cancelled := create(model.Cancelled)
+	c.returnUnapplied(paid(cancelled, "tx-cancelled"))
+	c.returnUnapplied(paid(cancelled, "tx-cancelled"))
+	rets := returns(cancelled)
+	if len(rets) != 1 {
+		t.Fatalf("%d returns after a redelivery, want 1", len(rets))
+	}
+	if rets[0].Amount != 10250 || rets[0].Currency != model.ETB || rets[0].Status != model.PaymentReturnPending {
+		t.Errorf("return = %s %s %s, want 102.50 ETB Pending", rets[0].Amount, rets[0].Currency, rets[0].Status)
+	}
+
+	// A redelivery of the payment that funded the escrow.
+	funded := create(model.Pending)
+	if err := statemachine.Fire(c.DB, funded, model.Funded, statemachine.Trigger{
+		Actor: statemachine.System, Source: model.SourceConsumer, Note: "tx-funded",
+	}); err != nil {
+		t.Fatalf("Fire() = %v", err)
+	}
+	c.returnUnapplied(paid(funded, "tx-funded"))
+	if rets := returns(funded); len(rets) != 0 {
+		t.Errorf("%d returns for a redelivered payment, want 0", len(rets))
+	}
+
+	// A second checkout for an escrow another payment already funded.
+	c.returnUnapplied(paid(funded, "tx-second"))
+	if rets := returns(funded); len(rets) != 1 {
+		t.Errorf("%d returns for a second payment, want 1", len(rets))
+	}
+
+	// Still Pending: funding failed for another reason.
+	pending := create(model.Pending)
+	c.returnUnapplied(paid(pending, "tx-pending"))
+	if rets := returns(pending); len(rets) != 0 {
+		t.Errorf("%d returns for a Pending escrow, want 0", len(rets))
+	}
+}
+
+func TestFundChecksAmountPaid(t *testing.T) {
+	tests := []struct {
+		name       string
+		amount     money.Amount
+		currency   string
+		wantStatus model.EscrowStatus
+		wantReturn bool
+	}{
+		{"amount and buyer fee", 10250, "ETB", model.Funded, false},
+		// Paid for the terms before an amendment raised the amount.
+		{"short", 5250, "ETB", model.Pending, true},
+		{"without the buyer fee", 10000, "ETB", model.Pending, true},
+		{"other currency", 10250, "USD", model.Pending, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestConsumer(t)
+			escrow := &model.Escrow{BuyerID: 1, SellerID: 2, Amount: 10000, BuyerFee: 250, Currency: model.ETB, Status: model.Pending}
+			if err := c.DB.Create(escrow).Error; err != nil {
+				t.Fatalf("create escrow: %v", err)
+			}
+
+			c.fund(events.NewPaymentSuccessEvent("tx-1", uint32(escrow.ID), 1, tt.amount, tt.currency))
+
+			c.DB.First(escrow, escrow.ID)
+			if escrow.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", escrow.Status, tt.wantStatus)
+			}
+			var returns int64
+			c.DB.Model(&model.PaymentReturn{}).Where("escrow_id = ?", escrow.ID).Count(&returns)
+			if (returns > 0) != tt.wantReturn {
+				t.Errorf("%d returns queued, want a return %v", returns, tt.wantReturn)
+			}
+		})
+	}
+}
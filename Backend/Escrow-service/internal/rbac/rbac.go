@@ -0,0 +1,49 @@
+package rbac
+
+import "github.com/gofiber/fiber/v3"
+
+// Role is the platform role the API gateway forwards in the X-User-Role
+// header. It is independent of whether the caller is the buyer or the seller
+// of a given escrow.
+type Role string
+
+const (
+	User       Role = "user"
+	Support    Role = "support"
+	Arbitrator Role = "arbitrator"
+	Admin      Role = "admin"
+)
+
+// Header carries the caller's role. The gateway always overwrites it.
+const Header = "X-User-Role"
+
+// RoleOf returns the caller's role, treating a missing or unknown header as
+// an ordinary user.
+func RoleOf(c fiber.Ctx) Role {
+	switch r := Role(c.Get(Header)); r {
+	case Support, Arbitrator, Admin:
+		return r
+	}
+	return User
+}
+
+// IsStaff reports whether the role may look at escrows it is not a party
+// to.
+func (r Role) IsStaff() bool {
+	return r == Support || r == Arbitrator || r == Admin
+}
+
+// Require only lets the request through for the given roles.
+func Require(roles ...Role) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		role := RoleOf(c)
+		for _, r := range roles {
+			if role == r {
+				return c.Next()
+			}
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Insufficient role",
+		})
+	}
+}
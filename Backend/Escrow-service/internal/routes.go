@@ -3,28 +3,97 @@ package internal
 import (
     "github.com/gofiber/fiber/v3"
     "escrow_service/internal/handlers"
+    "escrow_service/internal/rbac"
+    "escrow_service/internal/storage"
+    "shared/idempotency"
     "gorm.io/gorm"
 )
 
-func SetupRoutes(app *fiber.App, db *gorm.DB) {
+func SetupRoutes(app *fiber.App, db *gorm.DB, store storage.Store) {
     app.Use(func(c fiber.Ctx) error {
         c.Locals("db", db)
+        c.Locals("storage", store)
         return c.Next()
     })
+    // Retries of POST/PUT/PATCH/DELETE requests that carry an
+    // Idempotency-Key get the first response replayed.
+    app.Use(idempotency.New(db))
 
    
     api := app.Group("/api/escrows")
     {
     api.Post("/", handlers.CreateEscrow)
+    api.Get("/templates", handlers.ListTemplates)
+    api.Post("/templates", handlers.CreateTemplate)
+    api.Get("/templates/:templateId", handlers.GetTemplate)
+    api.Put("/templates/:templateId", handlers.UpdateTemplate)
+    api.Delete("/templates/:templateId", handlers.DeleteTemplate)
+    api.Post("/templates/:templateId/escrows", handlers.CreateEscrowFromTemplate)
+    api.Get("/invitations", handlers.ListInvitations)
+    api.Post("/invitations/claim", handlers.ClaimInvitation)
+    api.Post("/invitations/:invitationId/cancel", handlers.CancelInvitation)
+    api.Get("/links", handlers.ListPaymentLinks)
+    api.Post("/links", handlers.CreatePaymentLink)
+    api.Get("/links/:code", handlers.GetPaymentLink)
+    api.Post("/links/:code/escrows", handlers.OpenPaymentLink)
+    api.Post("/links/:code/deactivate", handlers.DeactivatePaymentLink)
+    api.Get("/recurring", handlers.ListRecurringEscrows)
+    api.Post("/recurring", handlers.CreateRecurringEscrow)
+    api.Get("/recurring/:recurringId", handlers.GetRecurringEscrow)
+    api.Post("/recurring/:recurringId/pause", handlers.PauseRecurringEscrow)
+    api.Post("/recurring/:recurringId/resume", handlers.ResumeRecurringEscrow)
+    api.Post("/recurring/:recurringId/cancel", handlers.CancelRecurringEscrow)
     api.Get("/my",handlers.GetUserEscrows)
     api.Get("/contacts",handlers.GetContacts)
+    api.Get("/fees/quote", handlers.QuoteFee)
     api.Get("/:id", handlers.GetEscrow)
+    api.Get("/:id/history", handlers.GetEscrowHistory)
     api.Post("/:id/accept",handlers.AcceptEscrow)
+    api.Post("/:id/decline", handlers.DeclineEscrow)
     api.Post("/:id/confirm-receipt", handlers.ConfirmReceipt)
+    api.Post("/:id/deliverables/:deliverableId", handlers.UpdateDeliverable)
+    api.Get("/:id/deliveries", handlers.GetDeliveries)
+    api.Post("/:id/deliveries", handlers.MarkDelivery)
+    api.Get("/:id/deliveries/:deliveryId/proof", handlers.DownloadDeliveryProof)
+    api.Post("/:id/milestones/:milestoneId/confirm", handlers.ConfirmMilestone)
     api.Post("/dispute/:id",handlers.DisputeEscrow)
+    api.Get("/dispute/:id", handlers.GetDisputes)
+    api.Get("/:id/disputes", handlers.GetDisputes)
+    api.Post("/:id/disputes", handlers.DisputeEscrow)
+    api.Post("/:id/disputes/:disputeId/statements", handlers.AddDisputeStatement)
+    api.Post("/:id/disputes/:disputeId/withdraw", handlers.WithdrawDispute)
+    api.Get("/:id/disputes/:disputeId/evidence", handlers.GetEvidence)
+    api.Post("/:id/disputes/:disputeId/evidence", handlers.UploadEvidence)
+    api.Get("/:id/disputes/:disputeId/evidence/:evidenceId", handlers.DownloadEvidence)
     api.Post("/:id/refund", handlers.RefundEscrow)
+    api.Get("/:id/settlements", handlers.GetSettlements)
+    api.Post("/:id/settlements", handlers.ProposeSettlement)
+    api.Post("/:id/settlements/:settlementId/accept", handlers.AcceptSettlement)
+    api.Post("/:id/settlements/:settlementId/reject", handlers.RejectSettlement)
     api.Post("/:id/cancel", handlers.CancelEscrow)
+    api.Get("/:id/amendments", handlers.GetAmendments)
+    api.Post("/:id/amendments", handlers.ProposeAmendment)
+    api.Post("/:id/amendments/:amendmentId/accept", handlers.AcceptAmendment)
+    api.Post("/:id/amendments/:amendmentId/reject", handlers.RejectAmendment)
     
     }
+
+    admin := app.Group("/api/admin/escrows", rbac.Require(rbac.Admin))
+    admin.Get("/", handlers.ListAllEscrows)
+
+    fees := app.Group("/api/admin/fees", rbac.Require(rbac.Admin))
+    fees.Get("/", handlers.ListFeeSchedules)
+    fees.Post("/", handlers.CreateFeeSchedule)
+    fees.Get("/ledger", handlers.GetFeeLedger)
+    fees.Put("/:id", handlers.UpdateFeeSchedule)
+    fees.Delete("/:id", handlers.DeleteFeeSchedule)
+
+    fxRates := app.Group("/api/admin/fx-rates", rbac.Require(rbac.Admin))
+    fxRates.Get("/", handlers.ListFxRates)
+    fxRates.Put("/", handlers.SetFxRate)
+
+    arbitration := app.Group("/api/arbitration", rbac.Require(rbac.Arbitrator, rbac.Admin))
+    arbitration.Get("/queue", handlers.GetArbitrationQueue)
+    arbitration.Post("/:id/decision", handlers.DecideArbitration)
     
 }
\ No newline at end of file
@@ -0,0 +1,23 @@
+package scheduler
+
+import (
+	"escrow_service/internal/model"
+	"log"
+	"time"
+)
+
+// expireInvitations marks invitations nobody claimed before they ran out
+// as expired. No escrow was created for them, so there is nothing else to
+// undo.
+func (s *Scheduler) expireInvitations(now time.Time) {
+	res := s.db.Model(&model.EscrowInvitation{}).
+		Where("status = ? AND expires_at <= ?", model.InvitationPending, now).
+		Update("status", model.InvitationExpired)
+	if res.Error != nil {
+		log.Printf("Failed to expire invitations: %v", res.Error)
+		return
+	}
+	if res.RowsAffected > 0 {
+		log.Printf("Expired %d unclaimed invitations", res.RowsAffected)
+	}
+}
@@ -0,0 +1,55 @@
+package scheduler
+
+import (
+	"escrow_service/internal/deadline"
+	"escrow_service/internal/model"
+	"escrow_service/internal/payout"
+	"log"
+	"time"
+)
+
+// returnPayments sends back payments that arrived for escrows they could
+// not fund. New returns go out on the next pass; failed ones are retried
+// after the settlement retry interval.
+func (s *Scheduler) returnPayments(now time.Time) {
+	cutoff := now.Add(-deadline.SettlementRetryInterval())
+	var returns []model.PaymentReturn
+	err := s.db.
+		Where("status = ? OR (status = ? AND updated_at <= ?)",
+			model.PaymentReturnPending, model.PaymentReturnFailed, cutoff).
+		Order("created_at").
+		Limit(batchSize).
+		Find(&returns).Error
+	if err != nil {
+		log.Printf("Failed to load payments to return: %v", err)
+		return
+	}
+
+	for i := range returns {
+		ret := &returns[i]
+
+		// Claim the return so another replica does not send it too.
+		res := s.db.Model(&model.PaymentReturn{}).
+			Where("id = ? AND status = ?", ret.ID, ret.Status).
+			Update("status", model.PaymentReturnSending)
+		if res.Error != nil {
+			log.Printf("Failed to claim payment return %d: %v", ret.ID, res.Error)
+			continue
+		}
+		if res.RowsAffected == 0 {
+			continue
+		}
+		ret.Status = model.PaymentReturnSending
+
+		var escrow model.Escrow
+		if err := s.db.First(&escrow, ret.EscrowID).Error; err != nil {
+			log.Printf("Failed to load escrow %d of payment return %d: %v", ret.EscrowID, ret.ID, err)
+			continue
+		}
+		if err := payout.ReturnPayment(s.db, &escrow, ret); err != nil {
+			log.Printf("Failed to return payment %s of escrow %d: %v", ret.TransactionRef, escrow.ID, err)
+			continue
+		}
+		log.Printf("Payment %s of escrow %d returned to the buyer", ret.TransactionRef, escrow.ID)
+	}
+}
@@ -0,0 +1,126 @@
+package scheduler
+
+import (
+	"errors"
+	"escrow_service/internal/creation"
+	"escrow_service/internal/model"
+	"escrow_service/internal/statemachine"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// errCycleClaimed is returned when another replica already created the
+// escrow of a cycle.
+var errCycleClaimed = errors.New("cycle was already created")
+
+// spawnRecurring creates the escrows of recurring schedules that are due.
+// Each one is created like any other escrow and queues escrow.create,
+// which tells the buyer to fund it. A schedule whose escrow is refused,
+// e.g. because a party's account was deactivated, is paused with the
+// reason so its owner can fix it and resume.
+func (s *Scheduler) spawnRecurring(now time.Time) {
+	var schedules []model.RecurringEscrow
+	err := s.db.
+		Where("status = ? AND next_run_at IS NOT NULL AND next_run_at <= ?", model.RecurringActive, now).
+		Order("next_run_at").
+		Limit(batchSize).
+		Find(&schedules).Error
+	if err != nil {
+		log.Printf("Failed to load due recurring escrows: %v", err)
+		return
+	}
+
+	for i := range schedules {
+		r := &schedules[i]
+		escrow, err := s.spawn(r, now)
+		var cerr *creation.Error
+		switch {
+		case err == nil:
+			log.Printf("Recurring escrow %d: created escrow %d (cycle %d)", r.ID, escrow.ID, escrow.RecurringCycle)
+		case errors.Is(err, errCycleClaimed):
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			s.pauseRecurring(r, "The template of this recurring escrow no longer exists")
+		case errors.As(err, &cerr) && cerr.Rejected():
+			s.pauseRecurring(r, cerr.Message)
+		default:
+			// Try again on the next pass.
+			log.Printf("Failed to create escrow for recurring escrow %d: %v", r.ID, err)
+		}
+	}
+}
+
+// spawn creates the escrow of the schedule's next cycle from its template.
+func (s *Scheduler) spawn(r *model.RecurringEscrow, now time.Time) (*model.Escrow, error) {
+	var template model.EscrowTemplate
+	if err := s.db.First(&template, r.TemplateID).Error; err != nil {
+		return nil, err
+	}
+
+	escrow := template.Escrow(now)
+	if escrow.InitiatedBy == string(statemachine.Seller) {
+		escrow.BuyerID = r.CounterpartyID
+	} else {
+		escrow.SellerID = r.CounterpartyID
+	}
+	recurringID := r.ID
+	escrow.RecurringEscrowID = &recurringID
+	escrow.RecurringCycle = r.Cycles + 1
+
+	_, err := creation.Create(s.db, creation.Request{
+		Escrow:   escrow,
+		CallerID: r.OwnerID,
+		Source:   model.SourceScheduler,
+		Template: &template,
+		Claim: func(tx *gorm.DB, escrow *model.Escrow) error {
+			return advanceRecurring(tx, r, escrow.ID, now)
+		},
+	})
+	return escrow, err
+}
+
+// advanceRecurring moves the schedule on to its next cycle in the
+// transaction that creates the current cycle's escrow, unless another
+// replica got there first. Periods missed while escrow-service was down are
+// skipped rather than created late.
+func advanceRecurring(tx *gorm.DB, r *model.RecurringEscrow, escrowID uint, now time.Time) error {
+	next := *r
+	next.Cycles++
+	next.NextPeriod = r.PeriodAfter(r.NextPeriod+1, now)
+	nextRun := next.NextRun(next.NextPeriod)
+
+	updates := map[string]any{
+		"cycles":         next.Cycles,
+		"next_period":    next.NextPeriod,
+		"next_run_at":    nextRun,
+		"last_escrow_id": escrowID,
+		"last_error":     "",
+	}
+	if nextRun == nil {
+		updates["status"] = model.RecurringCompleted
+	}
+	res := tx.Model(&model.RecurringEscrow{}).
+		Where("id = ? AND status = ? AND cycles = ? AND next_period = ?", r.ID, model.RecurringActive, r.Cycles, r.NextPeriod).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errCycleClaimed
+	}
+	return nil
+}
+
+// pauseRecurring pauses an active schedule whose escrow could not be
+// created and records why.
+func (s *Scheduler) pauseRecurring(r *model.RecurringEscrow, reason string) {
+	err := s.db.Model(&model.RecurringEscrow{}).
+		Where("id = ? AND status = ?", r.ID, model.RecurringActive).
+		Updates(map[string]any{"status": model.RecurringPaused, "last_error": reason}).Error
+	if err != nil {
+		log.Printf("Failed to pause recurring escrow %d: %v", r.ID, err)
+		return
+	}
+	log.Printf("Recurring escrow %d paused: %s", r.ID, reason)
+}
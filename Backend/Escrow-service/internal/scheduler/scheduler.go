@@ -0,0 +1,152 @@
+package scheduler
+
+import (
+	"errors"
+	"escrow_service/internal/deadline"
+	"escrow_service/internal/model"
+	"escrow_service/internal/payout"
+	"escrow_service/internal/statemachine"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// batchSize caps how many expired escrows a single pass handles.
+const batchSize = 50
+
+// Scheduler enforces escrow deadlines, creates the escrows of recurring
+// schedules, retries unfinished settlement transfers, returns payments that
+// could not fund their escrow and expires unclaimed invitations. Every
+// replica of escrow-service runs one; they do not coordinate. Each action
+// claims the escrow (milestone, recurring cycle, settlement, payment return)
+// with a conditional update first, so when two replicas pick up the same one
+// only one of them gets past the claim and the other skips it.
+type Scheduler struct {
+	db       *gorm.DB
+	interval time.Duration
+}
+
+func NewScheduler(db *gorm.DB) *Scheduler {
+	return &Scheduler{
+		db:       db,
+		interval: deadline.CheckInterval(),
+	}
+}
+
+// Start runs the deadline checks until the process exits.
+func (s *Scheduler) Start() {
+	log.Printf("Deadline scheduler running every %s", s.interval)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(time.Now())
+		<-ticker.C
+	}
+}
+
+func (s *Scheduler) runOnce(now time.Time) {
+	s.cancelUnfunded(now)
+	s.releaseInspected(now)
+	s.spawnRecurring(now)
+	s.retrySettlements(now)
+	s.returnPayments(now)
+	s.expireInvitations(now)
+}
+
+// cancelUnfunded cancels escrows that were not funded before their funding
+// deadline. No money has moved yet, so nothing needs to be refunded; a
+// checkout the buyer completes after the cancellation is returned to them
+// (see returnPayments).
+func (s *Scheduler) cancelUnfunded(now time.Time) {
+	var escrows []model.Escrow
+	err := s.db.
+		Where("status = ? AND funding_deadline IS NOT NULL AND funding_deadline <= ?", model.Pending, now).
+		Order("funding_deadline").
+		Limit(batchSize).
+		Find(&escrows).Error
+	if err != nil {
+		log.Printf("Failed to load unfunded escrows: %v", err)
+		return
+	}
+
+	for i := range escrows {
+		escrow := &escrows[i]
+		err := statemachine.Fire(s.db, escrow, model.Cancelled, statemachine.Trigger{
+			Actor:  statemachine.System,
+			Source: model.SourceScheduler,
+			Note:   "funding deadline passed",
+		})
+		if err != nil {
+			if !errors.Is(err, statemachine.ErrStaleStatus) {
+				log.Printf("Failed to cancel unfunded escrow %d: %v", escrow.ID, err)
+			}
+			continue
+		}
+		log.Printf("Escrow %d cancelled: funding deadline passed", escrow.ID)
+	}
+}
+
+// pastInspection loads the funded, accepted escrows whose inspection
+// deadline passed, oldest first.
+func (s *Scheduler) pastInspection(now time.Time) ([]model.Escrow, error) {
+	var escrows []model.Escrow
+	err := s.db.
+		Where("status = ? AND active = ? AND inspection_deadline IS NOT NULL AND inspection_deadline <= ?", model.Funded, true, now).
+		Order("inspection_deadline").
+		Limit(batchSize).
+		Find(&escrows).Error
+	return escrows, err
+}
+
+// releaseInspected releases funds to the seller once the buyer's inspection
+// period, or the transit window of shipped goods, ran out without a
+// confirmation or dispute. It goes through the same payout path as a manual
+// confirmation.
+func (s *Scheduler) releaseInspected(now time.Time) {
+	escrows, err := s.pastInspection(now)
+	if err != nil {
+		log.Printf("Failed to load escrows past inspection: %v", err)
+		return
+	}
+
+	t := statemachine.Trigger{
+		Actor:  statemachine.System,
+		Source: model.SourceScheduler,
+		Note:   "inspection period expired",
+	}
+	for i := range escrows {
+		escrow := &escrows[i]
+
+		var milestones []model.Milestone
+		if err := s.db.Where("escrow_id = ?", escrow.ID).Order("sequence").Find(&milestones).Error; err != nil {
+			log.Printf("Failed to load milestones of escrow %d: %v", escrow.ID, err)
+			continue
+		}
+		if len(milestones) == 0 {
+			if err := payout.ReleaseEscrow(s.db, escrow, t); err != nil {
+				if !errors.Is(err, statemachine.ErrStaleStatus) {
+					log.Printf("Failed to auto-release escrow %d: %v", escrow.ID, err)
+				}
+				continue
+			}
+			log.Printf("Escrow %d auto-released: inspection period expired", escrow.ID)
+			continue
+		}
+
+		for j := range milestones {
+			milestone := &milestones[j]
+			if milestone.Status != model.MilestonePending {
+				continue
+			}
+			if _, err := payout.ReleaseMilestone(s.db, escrow, milestone, t); err != nil {
+				if !errors.Is(err, payout.ErrMilestoneClaimed) {
+					log.Printf("Failed to auto-release milestone %d of escrow %d: %v", milestone.ID, escrow.ID, err)
+				}
+				break
+			}
+			log.Printf("Milestone %d of escrow %d auto-released: inspection period expired", milestone.ID, escrow.ID)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package scheduler
+
+import (
+	"bytes"
+	"escrow_service/internal/deadline"
+	"escrow_service/internal/handlers"
+	"escrow_service/internal/model"
+	"fmt"
+	"mime/multipart"
+	"net/http/httptest"
+	"shared/outbox"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&model.Escrow{}, &model.Milestone{}, &model.Delivery{}, &model.EscrowEvent{}, &outbox.Message{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// markShipped records a shipment of the escrow through the HTTP handler.
+func markShipped(t *testing.T, db *gorm.DB, escrow *model.Escrow) {
+	t.Helper()
+	app := fiber.New()
+	app.Use(func(c fiber.Ctx) error {
+		c.Locals("db", db)
+		return c.Next()
+	})
+	app.Post("/escrows/:id/deliveries", handlers.MarkDelivery)
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("status", string(model.DeliveryShipped))
+	form.WriteField("carrier", "EMS")
+	form.WriteField("tracking_number", "EE123456789ET")
+	form.Close()
+
+	req := httptest.NewRequest("POST", fmt.Sprintf("/escrows/%d/deliveries", escrow.ID), &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("X-User-ID", fmt.Sprint(escrow.SellerID))
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("mark shipped: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusCreated {
+		t.Fatalf("mark shipped: status %d", resp.StatusCode)
+	}
+}
+
+func TestShippedEscrowStillExpires(t *testing.T) {
+	db := newTestDB(t)
+	accepted := time.Now()
+	inspectUntil := accepted.Add(24 * time.Hour)
+	escrow := model.Escrow{
+		BuyerID:               1,
+		SellerID:              2,
+		Amount:                10000,
+		Currency:              "ETB",
+		Status:                model.Funded,
+		Active:                true,
+		InspectionPeriodHours: 24,
+		InspectionDeadline:    &inspectUntil,
+	}
+	if err := db.Create(&escrow).Error; err != nil {
+		t.Fatalf("create escrow: %v", err)
+	}
+
+	markShipped(t, db, &escrow)
+
+	var shipped model.Escrow
+	if err := db.First(&shipped, escrow.ID).Error; err != nil {
+		t.Fatalf("reload escrow: %v", err)
+	}
+	if shipped.ShippedAt == nil {
+		t.Fatal("shipped_at was not set")
+	}
+	if shipped.InspectionDeadline == nil {
+		t.Fatal("shipping cleared the inspection deadline")
+	}
+
+	s := &Scheduler{db: db}
+	transitEnd := accepted.Add(deadline.TransitWindow())
+	tests := []struct {
+		name string
+		now  time.Time
+		want bool
+	}{
+		{"inspection period passed while in transit", inspectUntil.Add(time.Minute), false},
+		{"transit window passed without delivery", transitEnd.Add(time.Minute), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			escrows, err := s.pastInspection(tt.now)
+			if err != nil {
+				t.Fatalf("pastInspection: %v", err)
+			}
+			got := len(escrows) == 1 && escrows[0].ID == escrow.ID
+			if got != tt.want {
+				t.Errorf("escrow expired at %s = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
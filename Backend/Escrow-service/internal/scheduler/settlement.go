@@ -0,0 +1,57 @@
+package scheduler
+
+import (
+	"escrow_service/internal/deadline"
+	"escrow_service/internal/model"
+	"escrow_service/internal/payout"
+	"log"
+	"time"
+)
+
+// retrySettlements finishes accepted settlements whose escrow is still
+// Settling: a transfer that could not be started, or a status that could
+// not be recorded after both went out. Only the legs without a transfer
+// reference are sent again.
+func (s *Scheduler) retrySettlements(now time.Time) {
+	cutoff := now.Add(-deadline.SettlementRetryInterval())
+	var settlements []model.Settlement
+	err := s.db.
+		Joins("JOIN escrows ON escrows.id = settlements.escrow_id").
+		Where("settlements.status IN ? AND settlements.updated_at <= ? AND escrows.status = ?",
+			[]model.SettlementStatus{model.SettlementPaying, model.SettlementFailed}, cutoff, model.Settling).
+		Order("settlements.updated_at").
+		Limit(batchSize).
+		Find(&settlements).Error
+	if err != nil {
+		log.Printf("Failed to load unfinished settlements: %v", err)
+		return
+	}
+
+	for i := range settlements {
+		settlement := &settlements[i]
+
+		// Claim the settlement so another replica does not retry it too.
+		res := s.db.Model(&model.Settlement{}).
+			Where("id = ? AND status = ? AND updated_at <= ?", settlement.ID, settlement.Status, cutoff).
+			Update("status", model.SettlementPaying)
+		if res.Error != nil {
+			log.Printf("Failed to claim settlement %d: %v", settlement.ID, res.Error)
+			continue
+		}
+		if res.RowsAffected == 0 {
+			continue
+		}
+		settlement.Status = model.SettlementPaying
+
+		var escrow model.Escrow
+		if err := s.db.First(&escrow, settlement.EscrowID).Error; err != nil {
+			log.Printf("Failed to load escrow %d of settlement %d: %v", settlement.EscrowID, settlement.ID, err)
+			continue
+		}
+		if err := payout.PaySettlement(s.db, &escrow, settlement, model.SourceScheduler); err != nil {
+			log.Printf("Failed to retry settlement %d of escrow %d: %v", settlement.ID, escrow.ID, err)
+			continue
+		}
+		log.Printf("Settlement %d of escrow %d paid out on retry", settlement.ID, escrow.ID)
+	}
+}
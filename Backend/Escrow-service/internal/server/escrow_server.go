@@ -3,11 +3,19 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"escrow_service/internal/listing"
 	"escrow_service/internal/model"
+	"escrow_service/internal/statemachine"
 	"fmt"
 	"log"
+	"shared/money"
+	"time"
 
 	"github.com/SafeDeal/proto/escrow/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"gorm.io/gorm"
 )
 
@@ -20,6 +28,9 @@ func NewEscrowServer(db *gorm.DB) *EscrowServer {
     return &EscrowServer{DB: db}
 }
 
+// UpdateStatus lets payment-service mark a Pending escrow Funded. It fires
+// as System, so every other transition is refused: they belong to the
+// parties, the arbitrator or escrow-service itself.
 func (s *EscrowServer) UpdateStatus(ctx context.Context, req *v1.UpdateEscrowStatusRequest) (*v1.UpdateEscrowStatusResponse, error) {
     var escrow model.Escrow
     if err := s.DB.First(&escrow, req.EscrowId).Error; err != nil {
@@ -29,8 +40,37 @@ func (s *EscrowServer) UpdateStatus(ctx context.Context, req *v1.UpdateEscrowSta
         }, nil
     }
 
-    escrow.Status = model.EscrowStatus(req.NewStatus)
-	if err := s.DB.Save(&escrow).Error; err != nil {
+    newStatus, err := statemachine.Parse(req.NewStatus)
+    if err != nil {
+        return &v1.UpdateEscrowStatusResponse{
+            Success: false,
+            Message: err.Error(),
+        }, nil
+    }
+    if newStatus != model.Funded {
+        return &v1.UpdateEscrowStatusResponse{
+            Success: false,
+            Message: fmt.Sprintf("Status %s cannot be set over gRPC", newStatus),
+        }, nil
+    }
+    if escrow.Status != model.Pending {
+        return &v1.UpdateEscrowStatusResponse{
+            Success: false,
+            Message: fmt.Sprintf("Escrow %d is %s, only %s escrows can be funded", req.EscrowId, escrow.Status, model.Pending),
+        }, nil
+    }
+
+    if err := statemachine.Fire(s.DB, &escrow, newStatus, statemachine.Trigger{
+        Actor:  statemachine.System,
+        Source: model.SourceGRPC,
+    }); err != nil {
+        var terr *statemachine.TransitionError
+        if errors.As(err, &terr) {
+            return &v1.UpdateEscrowStatusResponse{
+                Success: false,
+                Message: err.Error(),
+            }, nil
+        }
         return &v1.UpdateEscrowStatusResponse{
             Success: false,
             Message: "Failed to update status",
@@ -52,18 +92,228 @@ func (s *EscrowServer) GetEscrow(ctx context.Context, req *v1.GetEscrowRequest)
     if err := s.DB.First(&escrow, req.EscrowId).Error; err != nil {
         return nil, fmt.Errorf("escrow not found")
     }
+    return escrowResponse(&escrow)
+}
+
+// escrowResponse converts an escrow to its gRPC representation.
+func escrowResponse(escrow *model.Escrow) (*v1.EscrowResponse, error) {
     var blockchainEscrowId uint32
     if escrow.BlockchainEscrowID != nil {
         blockchainEscrowId = uint32(*escrow.BlockchainEscrowID)
     }
+    var conditionsJSON string
+    if escrow.ConditionsDoc != nil {
+        b, err := json.Marshal(escrow.ConditionsDoc)
+        if err != nil {
+            return nil, fmt.Errorf("failed to encode conditions: %v", err)
+        }
+        conditionsJSON = string(b)
+    }
     return &v1.EscrowResponse{
         Id:         uint32(escrow.ID),
         BuyerId:    uint32(escrow.BuyerID),
         SellerId:   uint32(escrow.SellerID),
-        Amount:     float32(escrow.Amount),
+        AmountMinor: escrow.Amount.Minor(),
         Status:     string(escrow.Status),
         Conditions: escrow.Conditions,
         BlockchainEscrowId: blockchainEscrowId,
         Active: escrow.Active,
+        InitiatedBy: escrow.InitiatedBy,
+        AwaitingBuyer: escrow.AwaitingBuyer,
+        ConditionsJson: conditionsJSON,
+        BuyerFeeMinor: escrow.BuyerFee.Minor(),
+        SellerFeeMinor: escrow.SellerFee.Minor(),
+        Currency: string(escrow.Currency),
     }, nil
-}
\ No newline at end of file
+}
+
+// StartPayment records that payment-service is opening a checkout for the
+// escrow, which freezes its terms. The escrow must still be Pending, belong
+// to the buyer and have the amounts the checkout is for; otherwise the
+// checkout must not be opened.
+func (s *EscrowServer) StartPayment(ctx context.Context, req *v1.StartPaymentRequest) (*v1.StartPaymentResponse, error) {
+    res := s.DB.Model(&model.Escrow{}).
+        Where("id = ? AND buyer_id = ? AND status = ? AND amount = ? AND buyer_fee = ?",
+            req.EscrowId, req.BuyerId, model.Pending,
+            money.FromMinor(req.AmountMinor), money.FromMinor(req.BuyerFeeMinor)).
+        Update("payment_initiated_at", gorm.Expr("COALESCE(payment_initiated_at, ?)", time.Now()))
+    if res.Error != nil {
+        log.Printf("Failed to start payment of escrow %d: %v", req.EscrowId, res.Error)
+        return &v1.StartPaymentResponse{Success: false, Error: "Failed to start payment"}, nil
+    }
+    if res.RowsAffected == 0 {
+        return &v1.StartPaymentResponse{
+            Success: false,
+            Error:   "Escrow can no longer be funded with these terms",
+        }, nil
+    }
+    return &v1.StartPaymentResponse{Success: true}, nil
+}
+
+// ListEvidence returns the metadata of every file attached to the escrow's
+// disputes, oldest first.
+func (s *EscrowServer) ListEvidence(ctx context.Context, req *v1.ListEvidenceRequest) (*v1.ListEvidenceResponse, error) {
+    var evidence []model.Evidence
+    if err := s.DB.Where("escrow_id = ?", req.EscrowId).Order("created_at").Find(&evidence).Error; err != nil {
+        return nil, fmt.Errorf("failed to load evidence: %v", err)
+    }
+
+    resp := &v1.ListEvidenceResponse{}
+    for _, e := range evidence {
+        resp.Evidence = append(resp.Evidence, &v1.Evidence{
+            Id:           uint32(e.ID),
+            DisputeId:    uint32(e.DisputeID),
+            UploadedBy:   uint32(e.UploadedBy),
+            UploaderRole: e.UploaderRole,
+            FileName:     e.FileName,
+            ContentType:  e.ContentType,
+            Size:         e.Size,
+            Sha256:       e.SHA256,
+            Description:  e.Description,
+            UploadedAt:   e.CreatedAt.Unix(),
+        })
+    }
+    return resp, nil
+}
+
+// RecordRecommendation stores the AI arbitrator's decision on the escrow's
+// open dispute. A later recommendation replaces an earlier one until an
+// arbitrator has decided the dispute.
+func (s *EscrowServer) RecordRecommendation(ctx context.Context, req *v1.RecordRecommendationRequest) (*v1.RecordRecommendationResponse, error) {
+    var dispute model.Dispute
+    err := s.DB.Where("escrow_id = ? AND status = ?", req.EscrowId, model.DisputeOpen).First(&dispute).Error
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return &v1.RecordRecommendationResponse{Success: false, Error: "Escrow has no open dispute"}, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to load dispute: %v", err)
+    }
+
+    now := time.Now()
+    recorded := false
+    err = s.DB.Transaction(func(tx *gorm.DB) error {
+        arbitration := model.Arbitration{
+            EscrowID:  dispute.EscrowID,
+            DisputeID: dispute.ID,
+            Status:    model.ArbitrationPending,
+        }
+        if err := tx.Where("dispute_id = ?", dispute.ID).FirstOrCreate(&arbitration).Error; err != nil {
+            return err
+        }
+        res := tx.Model(&model.Arbitration{}).
+            Where("id = ? AND status = ?", arbitration.ID, model.ArbitrationPending).
+            Updates(map[string]any{
+                "ai_decision":      req.Decision,
+                "ai_outcome":       model.OutcomeFromAI(req.Decision),
+                "ai_justification": req.Justification,
+                "recommended_at":   now,
+            })
+        if res.Error != nil || res.RowsAffected == 0 {
+            return res.Error
+        }
+        recorded = true
+        return tx.Create(&model.EscrowEvent{
+            EscrowID:   dispute.EscrowID,
+            Kind:       model.EventAIRecommendation,
+            ActorRole:  string(statemachine.System),
+            FromStatus: model.Disputed,
+            ToStatus:   model.Disputed,
+            Source:     model.SourceGRPC,
+            Note:       fmt.Sprintf("dispute %d: %s", dispute.ID, req.Decision),
+        }).Error
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to record recommendation: %v", err)
+    }
+    if !recorded {
+        return &v1.RecordRecommendationResponse{Success: false, Error: "Dispute has already been decided"}, nil
+    }
+    return &v1.RecordRecommendationResponse{Success: true, DisputeId: uint32(dispute.ID)}, nil
+}
+
+// ListEscrows pages through escrows with the same filters, sorting and
+// cursors as GET /api/escrows/my.
+func (s *EscrowServer) ListEscrows(ctx context.Context, req *v1.ListEscrowsRequest) (*v1.ListEscrowsResponse, error) {
+    filter := listing.Filter{
+        UserID:         uint(req.UserId),
+        Role:           req.Role,
+        CounterpartyID: uint(req.CounterpartyId),
+    }
+    for _, raw := range req.Statuses {
+        st, err := statemachine.Parse(raw)
+        if err != nil {
+            return nil, status.Error(codes.InvalidArgument, err.Error())
+        }
+        filter.Statuses = append(filter.Statuses, st)
+    }
+    if req.MinAmountMinor != nil {
+        v := money.FromMinor(req.MinAmountMinor.Value)
+        filter.MinAmount = &v
+    }
+    if req.MaxAmountMinor != nil {
+        v := money.FromMinor(req.MaxAmountMinor.Value)
+        filter.MaxAmount = &v
+    }
+    if req.CreatedFrom != 0 {
+        t := time.Unix(req.CreatedFrom, 0)
+        filter.CreatedFrom = &t
+    }
+    if req.CreatedTo != 0 {
+        t := time.Unix(req.CreatedTo, 0)
+        filter.CreatedTo = &t
+    }
+    if req.Active != nil {
+        v := req.Active.Value
+        filter.Active = &v
+    }
+    if err := filter.Validate(); err != nil {
+        return nil, status.Error(codes.InvalidArgument, err.Error())
+    }
+    sort, err := listing.ParseSort(req.Sort, req.Order)
+    if err != nil {
+        return nil, status.Error(codes.InvalidArgument, err.Error())
+    }
+
+    page, err := listing.List(s.DB, filter, sort, int(req.Limit), req.Cursor)
+    if errors.Is(err, listing.ErrInvalidCursor) {
+        return nil, status.Error(codes.InvalidArgument, err.Error())
+    }
+    if err != nil {
+        return nil, status.Errorf(codes.Internal, "failed to list escrows: %v", err)
+    }
+
+    resp := &v1.ListEscrowsResponse{NextCursor: page.NextCursor}
+    for i := range page.Escrows {
+        e, err := escrowResponse(&page.Escrows[i])
+        if err != nil {
+            return nil, status.Error(codes.Internal, err.Error())
+        }
+        resp.Escrows = append(resp.Escrows, e)
+    }
+
+    if req.IncludeSummary {
+        currency, err := model.ParseCurrency(req.SummaryCurrency)
+        if err != nil {
+            return nil, status.Error(codes.InvalidArgument, err.Error())
+        }
+        summary, err := listing.Summarize(s.DB, filter, currency)
+        if err != nil {
+            return nil, status.Errorf(codes.Internal, "failed to summarize escrows: %v", err)
+        }
+        totals := make(map[string]int64, len(summary.TotalsByCurrency))
+        for c, amount := range summary.TotalsByCurrency {
+            totals[c] = amount.Minor()
+        }
+        resp.Summary = &v1.EscrowSummary{
+            Total:       summary.Total,
+            Active:      summary.Active,
+            Completed:   summary.Completed,
+            Disputed:    summary.Disputed,
+            TotalAmountMinor: summary.TotalAmount.Minor(),
+            Currency:    string(summary.Currency),
+            TotalsByCurrencyMinor: totals,
+            MissingRates: summary.MissingRates,
+        }
+    }
+    return resp, nil
+}
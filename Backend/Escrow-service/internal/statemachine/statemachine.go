@@ -0,0 +1,275 @@
+package statemachine
+
+import (
+	"errors"
+	"escrow_service/internal/model"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Actor identifies who is asking for a status change.
+type Actor string
+
+const (
+	Buyer  Actor = "buyer"
+	Seller Actor = "seller"
+	// System covers the RabbitMQ consumers and internal gRPC callers.
+	System Actor = "system"
+	// Arbitrator is a staff member deciding a dispute.
+	Arbitrator Actor = "arbitrator"
+)
+
+var (
+	ErrUnknownStatus     = errors.New("unknown escrow status")
+	ErrIllegalTransition = errors.New("illegal escrow status transition")
+	ErrActorNotAllowed   = errors.New("actor not allowed to perform transition")
+	ErrStaleStatus       = errors.New("escrow status changed concurrently")
+)
+
+// TransitionError is returned whenever a requested status change is rejected.
+// It wraps one of the sentinel errors above so callers can use errors.Is.
+type TransitionError struct {
+	From  model.EscrowStatus
+	To    model.EscrowStatus
+	Actor Actor
+	Err   error
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("%v: %s -> %s by %s", e.Err, e.From, e.To, e.Actor)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return e.Err
+}
+
+// Statuses lists every status an escrow can be in.
+var Statuses = []model.EscrowStatus{
+	model.Pending,
+	model.Funded,
+	model.TransferPending,
+	model.Released,
+	model.Disputed,
+	model.Refunded,
+	model.Cancelled,
+	model.Settling,
+	model.Settled,
+	model.Declined,
+}
+
+// transitions maps from -> to -> actors allowed to trigger that edge.
+var transitions = map[model.EscrowStatus]map[model.EscrowStatus][]Actor{
+	model.Pending: {
+		model.Funded: {System},
+		// Unfunded escrows expire once their funding deadline passes.
+		model.Cancelled: {System},
+		// The invited party turns the escrow down.
+		model.Declined: {Buyer, Seller},
+	},
+	model.Funded: {
+		// System releases the funds when the inspection period runs out.
+		model.TransferPending: {Buyer, System},
+		model.Disputed:        {Buyer, Seller},
+		model.Cancelled:       {Buyer},
+		// An invited seller may still decline after funding; the buyer is refunded.
+		model.Declined: {Seller},
+	},
+	model.TransferPending: {
+		model.Released: {System},
+		// Rolls a confirmation back when the Chapa transfer could not start.
+		model.Funded: {System},
+	},
+	model.Disputed: {
+		// An arbitrator rules for the seller; released like a confirmed receipt.
+		model.TransferPending: {Arbitrator},
+		model.Refunded:        {Seller, Arbitrator},
+		// A settlement was accepted; both transfers are made next.
+		model.Settling: {Buyer, Seller, Arbitrator},
+		// The party who raised the dispute withdraws it.
+		model.Funded: {Buyer, Seller},
+	},
+	model.Settling: {
+		// Both transfers of the settlement were started.
+		model.Settled: {System},
+	},
+}
+
+// Parse converts a raw status string into a known EscrowStatus.
+func Parse(s string) (model.EscrowStatus, error) {
+	for _, st := range Statuses {
+		if string(st) == s {
+			return st, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownStatus, s)
+}
+
+// IsTerminal reports whether no further transitions leave the status.
+func IsTerminal(s model.EscrowStatus) bool {
+	return len(transitions[s]) == 0
+}
+
+// ActorOf returns the role userID plays on the escrow, or false if the
+// user is not a participant.
+func ActorOf(escrow *model.Escrow, userID uint) (Actor, bool) {
+	switch userID {
+	case escrow.BuyerID:
+		return Buyer, true
+	case escrow.SellerID:
+		return Seller, true
+	}
+	return "", false
+}
+
+// Check validates that actor may move an escrow from -> to without
+// touching the database.
+func Check(from, to model.EscrowStatus, actor Actor) error {
+	for _, st := range []model.EscrowStatus{from, to} {
+		if _, err := Parse(string(st)); err != nil {
+			return &TransitionError{From: from, To: to, Actor: actor, Err: ErrUnknownStatus}
+		}
+	}
+	actors, ok := transitions[from][to]
+	if !ok {
+		return &TransitionError{From: from, To: to, Actor: actor, Err: ErrIllegalTransition}
+	}
+	for _, a := range actors {
+		if a == actor {
+			return nil
+		}
+	}
+	return &TransitionError{From: from, To: to, Actor: actor, Err: ErrActorNotAllowed}
+}
+
+// Trigger describes who asked for a transition and through which entry
+// point, so it can be written to the escrow's history.
+type Trigger struct {
+	Actor  Actor
+	UserID uint
+	Source model.EventSource
+	Note   string
+}
+
+// kindFor names the history event produced by moving from -> to.
+func kindFor(from, to model.EscrowStatus) model.EventKind {
+	switch to {
+	case model.Funded:
+		if from == model.TransferPending {
+			return model.EventReleaseFailed
+		}
+		if from == model.Disputed {
+			return model.EventDisputeWithdrawn
+		}
+		return model.EventFunded
+	case model.TransferPending:
+		return model.EventReleaseRequested
+	case model.Released:
+		return model.EventReleased
+	case model.Disputed:
+		return model.EventDisputed
+	case model.Refunded:
+		return model.EventRefunded
+	case model.Cancelled:
+		return model.EventCancelled
+	case model.Settling:
+		return model.EventSettlementAccepted
+	case model.Settled:
+		return model.EventSettled
+	case model.Declined:
+		return model.EventDeclined
+	}
+	return model.EventKind(strings.ToLower(string(to)))
+}
+
+// Fire checks the transition and persists it with a conditional update, so
+// two concurrent writers cannot both move the escrow out of the same state.
+// The matching history event is written in the same transaction, and leaving
+// Disputed closes the open dispute. On success escrow.Status is updated in
+// place.
+func Fire(db *gorm.DB, escrow *model.Escrow, to model.EscrowStatus, t Trigger) error {
+	return FireWith(db, escrow, to, t, nil)
+}
+
+// FireWith is Fire with within run in the same transaction once the status
+// is claimed. If within fails the transition is rolled back. The escrow row
+// stays locked by the conditional update until within returns, so a
+// concurrent writer waits and then finds the status changed.
+func FireWith(db *gorm.DB, escrow *model.Escrow, to model.EscrowStatus, t Trigger, within func(tx *gorm.DB) error) error {
+	from := escrow.Status
+	if err := Check(from, to, t.Actor); err != nil {
+		return err
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.Escrow{}).
+			Where("id = ? AND status = ?", escrow.ID, from).
+			Update("status", to)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return &TransitionError{From: from, To: to, Actor: t.Actor, Err: ErrStaleStatus}
+		}
+
+		if from == model.Disputed {
+			if err := closeDispute(tx, escrow.ID, to, t); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(&model.EscrowEvent{
+			EscrowID:   escrow.ID,
+			Kind:       kindFor(from, to),
+			ActorID:    t.UserID,
+			ActorRole:  string(t.Actor),
+			FromStatus: from,
+			ToStatus:   to,
+			Source:     t.Source,
+			Note:       t.Note,
+		}).Error; err != nil {
+			return err
+		}
+		if within != nil {
+			return within(tx)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	escrow.Status = to
+	return nil
+}
+
+// closeDispute marks the escrow's open dispute as withdrawn (back to Funded)
+// or resolved with the status the escrow moved to.
+func closeDispute(tx *gorm.DB, escrowID uint, to model.EscrowStatus, t Trigger) error {
+	now := time.Now()
+	updates := map[string]any{
+		"status":          model.DisputeResolved,
+		"resolution":      string(to),
+		"resolution_note": t.Note,
+		"closed_at":       now,
+	}
+	switch to {
+	case model.Funded:
+		updates["status"] = model.DisputeWithdrawn
+		updates["resolution"] = ""
+	case model.TransferPending:
+		// The dispute was decided for the seller; the transfer is on its way.
+		updates["resolution"] = string(model.Released)
+	case model.Settling:
+		// The dispute was settled; the transfers are on their way.
+		updates["resolution"] = string(model.Settled)
+	}
+	if t.UserID != 0 {
+		updates["resolved_by"] = t.UserID
+	}
+	return tx.Model(&model.Dispute{}).
+		Where("escrow_id = ? AND status = ?", escrowID, model.DisputeOpen).
+		Updates(updates).Error
+}
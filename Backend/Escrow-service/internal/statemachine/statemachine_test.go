@@ -0,0 +1,252 @@
+package statemachine
+
+import (
+	"errors"
+	"escrow_service/internal/model"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		from, to model.EscrowStatus
+		actor    Actor
+		want     error
+	}{
+		// Allowed edges.
+		{model.Pending, model.Funded, System, nil},
+		{model.Pending, model.Cancelled, System, nil},
+		{model.Pending, model.Declined, Buyer, nil},
+		{model.Pending, model.Declined, Seller, nil},
+		{model.Funded, model.TransferPending, Buyer, nil},
+		{model.Funded, model.TransferPending, System, nil},
+		{model.Funded, model.Disputed, Buyer, nil},
+		{model.Funded, model.Disputed, Seller, nil},
+		{model.Funded, model.Cancelled, Buyer, nil},
+		{model.Funded, model.Declined, Seller, nil},
+		{model.TransferPending, model.Released, System, nil},
+		{model.TransferPending, model.Funded, System, nil},
+		{model.Disputed, model.TransferPending, Arbitrator, nil},
+		{model.Disputed, model.Refunded, Seller, nil},
+		{model.Disputed, model.Refunded, Arbitrator, nil},
+		{model.Disputed, model.Settling, Buyer, nil},
+		{model.Disputed, model.Settling, Arbitrator, nil},
+		{model.Disputed, model.Funded, Buyer, nil},
+		{model.Settling, model.Settled, System, nil},
+
+		// Edges that exist, but not for this actor.
+		{model.Pending, model.Funded, Buyer, ErrActorNotAllowed},
+		{model.Funded, model.TransferPending, Seller, ErrActorNotAllowed},
+		{model.Funded, model.Cancelled, Seller, ErrActorNotAllowed},
+		{model.Funded, model.Declined, Buyer, ErrActorNotAllowed},
+		{model.TransferPending, model.Released, Buyer, ErrActorNotAllowed},
+		{model.Disputed, model.TransferPending, Buyer, ErrActorNotAllowed},
+		{model.Disputed, model.Refunded, Buyer, ErrActorNotAllowed},
+		{model.Settling, model.Settled, Arbitrator, ErrActorNotAllowed},
+
+		// Edges that do not exist.
+		{model.Pending, model.Released, System, ErrIllegalTransition},
+		{model.Pending, model.Disputed, Buyer, ErrIllegalTransition},
+		{model.Funded, model.Released, System, ErrIllegalTransition},
+		{model.Funded, model.Settled, Buyer, ErrIllegalTransition},
+		{model.Disputed, model.Settled, Buyer, ErrIllegalTransition},
+		{model.Released, model.Funded, System, ErrIllegalTransition},
+		{model.Refunded, model.Funded, System, ErrIllegalTransition},
+		{model.Cancelled, model.Funded, System, ErrIllegalTransition},
+		{model.Settled, model.Disputed, Buyer, ErrIllegalTransition},
+		{model.Declined, model.Funded, System, ErrIllegalTransition},
+		{model.Funded, model.Funded, System, ErrIllegalTransition},
+
+		// Statuses the machine does not know.
+		{"Shipped", model.Funded, System, ErrUnknownStatus},
+		{model.Funded, "Archived", Buyer, ErrUnknownStatus},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to)+" by "+string(tt.actor), func(t *testing.T) {
+			err := Check(tt.from, tt.to, tt.actor)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Check() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Check() = %v, want %v", err, tt.want)
+			}
+			var te *TransitionError
+			if !errors.As(err, &te) || te.From != tt.from || te.To != tt.to || te.Actor != tt.actor {
+				t.Fatalf("Check() = %#v, want a TransitionError for the requested edge", err)
+			}
+		})
+	}
+}
+
+func TestTerminalStatusesHaveNoTransitions(t *testing.T) {
+	for _, from := range Statuses {
+		if !IsTerminal(from) {
+			continue
+		}
+		for _, to := range Statuses {
+			for _, actor := range []Actor{Buyer, Seller, System, Arbitrator} {
+				if err := Check(from, to, actor); err == nil {
+					t.Errorf("terminal %s -> %s allowed for %s", from, to, actor)
+				}
+			}
+		}
+	}
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&model.Escrow{}, &model.EscrowEvent{}, &model.Dispute{}, &model.DisputeStatement{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func createEscrow(t *testing.T, db *gorm.DB, status model.EscrowStatus) *model.Escrow {
+	t.Helper()
+	escrow := &model.Escrow{BuyerID: 1, SellerID: 2, Amount: 10000, Currency: "ETB", Status: status}
+	if err := db.Create(escrow).Error; err != nil {
+		t.Fatalf("create escrow: %v", err)
+	}
+	return escrow
+}
+
+func countEvents(t *testing.T, db *gorm.DB, escrowID uint) int64 {
+	t.Helper()
+	var n int64
+	if err := db.Model(&model.EscrowEvent{}).Where("escrow_id = ?", escrowID).Count(&n).Error; err != nil {
+		t.Fatalf("count events: %v", err)
+	}
+	return n
+}
+
+func TestFire(t *testing.T) {
+	db := newTestDB(t)
+	escrow := createEscrow(t, db, model.Pending)
+
+	err := Fire(db, escrow, model.Funded, Trigger{Actor: System, Source: model.SourceConsumer, Note: "payment received"})
+	if err != nil {
+		t.Fatalf("Fire() = %v", err)
+	}
+	if escrow.Status != model.Funded {
+		t.Errorf("escrow.Status = %s, want %s", escrow.Status, model.Funded)
+	}
+
+	var stored model.Escrow
+	db.First(&stored, escrow.ID)
+	if stored.Status != model.Funded {
+		t.Errorf("stored status = %s, want %s", stored.Status, model.Funded)
+	}
+	var event model.EscrowEvent
+	if err := db.Where("escrow_id = ?", escrow.ID).First(&event).Error; err != nil {
+		t.Fatalf("load event: %v", err)
+	}
+	if event.Kind != model.EventFunded || event.FromStatus != model.Pending || event.ToStatus != model.Funded {
+		t.Errorf("event = %s %s -> %s, want %s %s -> %s",
+			event.Kind, event.FromStatus, event.ToStatus, model.EventFunded, model.Pending, model.Funded)
+	}
+}
+
+func TestFireStaleStatus(t *testing.T) {
+	db := newTestDB(t)
+	escrow := createEscrow(t, db, model.Funded)
+
+	// Two requests loaded the escrow while it was Funded.
+	first, second := *escrow, *escrow
+	if err := Fire(db, &first, model.TransferPending, Trigger{Actor: Buyer, UserID: 1}); err != nil {
+		t.Fatalf("first Fire() = %v", err)
+	}
+
+	err := Fire(db, &second, model.Cancelled, Trigger{Actor: Buyer, UserID: 1})
+	if !errors.Is(err, ErrStaleStatus) {
+		t.Fatalf("second Fire() = %v, want %v", err, ErrStaleStatus)
+	}
+	if second.Status != model.Funded {
+		t.Errorf("escrow.Status = %s after a stale Fire, want it unchanged", second.Status)
+	}
+
+	var stored model.Escrow
+	db.First(&stored, escrow.ID)
+	if stored.Status != model.TransferPending {
+		t.Errorf("stored status = %s, want %s", stored.Status, model.TransferPending)
+	}
+	if n := countEvents(t, db, escrow.ID); n != 1 {
+		t.Errorf("%d history events, want 1", n)
+	}
+}
+
+func TestFireWithRollsBackOnError(t *testing.T) {
+	db := newTestDB(t)
+	escrow := createEscrow(t, db, model.Funded)
+
+	failure := errors.New("transfer failed")
+	err := FireWith(db, escrow, model.Cancelled, Trigger{Actor: Buyer, UserID: 1}, func(tx *gorm.DB) error {
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("FireWith() = %v, want %v", err, failure)
+	}
+
+	var stored model.Escrow
+	db.First(&stored, escrow.ID)
+	if stored.Status != model.Funded || escrow.Status != model.Funded {
+		t.Errorf("status = %s (in memory %s), want it rolled back to %s", stored.Status, escrow.Status, model.Funded)
+	}
+	if n := countEvents(t, db, escrow.ID); n != 0 {
+		t.Errorf("%d history events, want 0", n)
+	}
+}
+
+func TestFireClosesDispute(t *testing.T) {
+	tests := []struct {
+		to             model.EscrowStatus
+		actor          Actor
+		wantStatus     model.DisputeStatus
+		wantResolution string
+	}{
+		{model.Funded, Buyer, model.DisputeWithdrawn, ""},
+		{model.Refunded, Arbitrator, model.DisputeResolved, string(model.Refunded)},
+		{model.TransferPending, Arbitrator, model.DisputeResolved, string(model.Released)},
+		{model.Settling, Seller, model.DisputeResolved, string(model.Settled)},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.to), func(t *testing.T) {
+			db := newTestDB(t)
+			escrow := createEscrow(t, db, model.Disputed)
+			dispute := model.Dispute{
+				EscrowID:     escrow.ID,
+				RaisedBy:     1,
+				RaisedByRole: string(Buyer),
+				Category:     model.DisputeNotDelivered,
+				Reason:       "nothing arrived",
+				Status:       model.DisputeOpen,
+			}
+			if err := db.Create(&dispute).Error; err != nil {
+				t.Fatalf("create dispute: %v", err)
+			}
+
+			if err := Fire(db, escrow, tt.to, Trigger{Actor: tt.actor, UserID: 7}); err != nil {
+				t.Fatalf("Fire() = %v", err)
+			}
+
+			db.First(&dispute, dispute.ID)
+			if dispute.Status != tt.wantStatus || dispute.Resolution != tt.wantResolution {
+				t.Errorf("dispute = %s/%q, want %s/%q", dispute.Status, dispute.Resolution, tt.wantStatus, tt.wantResolution)
+			}
+			if dispute.ClosedAt == nil {
+				t.Error("dispute was not closed")
+			}
+		})
+	}
+}
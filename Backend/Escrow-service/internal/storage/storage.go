@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrNotFound is returned when no object exists under a key.
+var ErrNotFound = errors.New("object not found")
+
+// Store keeps uploaded files. Keys are slash-separated paths chosen by the
+// caller; implementations must not interpret them beyond that, so a backend
+// for an S3-compatible bucket can use them as object keys unchanged.
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewFromEnv builds the store selected by EVIDENCE_STORAGE. Only "local"
+// (the default) is available; it writes below EVIDENCE_DIR.
+func NewFromEnv() (Store, error) {
+	switch backend := os.Getenv("EVIDENCE_STORAGE"); backend {
+	case "", "local":
+		dir := os.Getenv("EVIDENCE_DIR")
+		if dir == "" {
+			dir = "data/evidence"
+		}
+		return NewLocalStore(dir)
+	default:
+		return nil, fmt.Errorf("unsupported EVIDENCE_STORAGE %q", backend)
+	}
+}
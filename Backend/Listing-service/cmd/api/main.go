@@ -0,0 +1,41 @@
+package main
+
+import (
+	"listing_service/internal"
+	"listing_service/internal/consul"
+	"listing_service/internal/db"
+	"listing_service/internal/storage"
+	"log"
+	"shared/idempotency"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func main() {
+	db.ConnectDB()
+	if err := db.RunMigration(db.DB); err != nil {
+		log.Fatalf("%v", err)
+	}
+	db.DB.AutoMigrate(&idempotency.Key{})
+	consul.RegisterService("listing-service", "listing-service", 8087)
+	go idempotency.RunPurger(db.DB, time.Hour)
+
+	imageStore, err := storage.NewFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to initialize image storage: %v", err)
+	}
+
+	// Leave room above the image size limit for multipart overhead.
+	app := fiber.New(fiber.Config{BodyLimit: 6 * 1024 * 1024})
+
+	app.Get("/health", func(c fiber.Ctx) error {
+		return c.SendString("OK")
+	})
+	internal.SetupRoutes(app, db.DB, imageStore)
+
+	log.Println("Listing service is running on :8087")
+	if err := app.Listen(":8087"); err != nil {
+		log.Fatalf("Failed to start listing service: %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package db
+
+import (
+	"fmt"
+	"listing_service/internal/model"
+	"os"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var DB *gorm.DB
+
+// SearchVector is the full-text document of a listing. Queries must use
+// the same expression as the index to hit it.
+const SearchVector = `to_tsvector('english',
+	coalesce(title, '') || ' ' ||
+	coalesce(description, '') || ' ' ||
+	coalesce(category, ''))`
+
+type Config struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	DBName   string
+	SSLMode  string
+}
+
+func LoadConfig() Config {
+	return Config{
+		Host:     os.Getenv("DB_HOST"),
+		Port:     os.Getenv("DB_PORT"),
+		User:     os.Getenv("DB_USER"),
+		Password: os.Getenv("DB_PASSWORD"),
+		DBName:   os.Getenv("DB_NAME"),
+		SSLMode:  os.Getenv("DB_SSLMODE"),
+	}
+}
+
+func ConnectDB() {
+	cfg := LoadConfig()
+
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=UTC",
+		cfg.Host, cfg.User, cfg.Password, cfg.DBName, cfg.Port, cfg.SSLMode)
+
+	var err error
+	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+
+	fmt.Println("Connected to Listing DB")
+}
+
+func RunMigration(db *gorm.DB) error {
+	if err := db.AutoMigrate(&model.Listing{}, &model.ListingImage{}, &model.ListingEscrow{}); err != nil {
+		return fmt.Errorf("failed to migrate: %v", err)
+	}
+	query := `CREATE INDEX IF NOT EXISTS idx_listing_search ON listings USING GIN (` + SearchVector + `)`
+	if err := db.Exec(query).Error; err != nil {
+		return fmt.Errorf("failed to create full-text index: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Package escrow creates escrows through escrow-service's HTTP API, on
+// behalf of the buyer, so they are validated exactly like escrows created
+// directly.
+package escrow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+// NewClientFromEnv talks to ESCROW_SERVICE_URL, escrow-service's address
+// on the internal network by default.
+func NewClientFromEnv() *Client {
+	baseURL := os.Getenv("ESCROW_SERVICE_URL")
+	if baseURL == "" {
+		baseURL = "http://escrow-service:8082"
+	}
+	return &Client{
+		baseURL: baseURL,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Response is escrow-service's answer, passed on to the buyer as is.
+type Response struct {
+	Status int
+	Body   []byte
+}
+
+// EscrowID returns the ID of the escrow escrow-service created, or 0 when
+// it refused to.
+func (r *Response) EscrowID() uint {
+	if r.Status != http.StatusAccepted {
+		return 0
+	}
+	var created struct {
+		ID uint `json:"id"`
+	}
+	if err := json.Unmarshal(r.Body, &created); err != nil {
+		return 0
+	}
+	return created.ID
+}
+
+// CreateEscrow sends the escrow to CreateEscrow as if buyerID had. An
+// idempotency key, if given, is passed along so a retried request does not
+// create a second escrow.
+func (c *Client) CreateEscrow(ctx context.Context, buyerID uint, idempotencyKey string, escrow any) (*Response, error) {
+	body, err := json.Marshal(escrow)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/escrows/", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", fmt.Sprintf("%d", buyerID))
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	return &Response{Status: resp.StatusCode, Body: respBody}, nil
+}
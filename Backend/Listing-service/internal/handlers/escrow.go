@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/json"
+	"listing_service/internal/model"
+	"log"
+	"shared/money"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// startEscrowRequest lets the buyer adjust the terms a listing pre-fills,
+// for instance after agreeing on extras with the seller in chat. The amount
+// can only be raised: a lower price is the seller's to set on the listing.
+type startEscrowRequest struct {
+	Amount                *money.Amount   `json:"amount"`
+	Conditions            *string         `json:"conditions"`
+	ConditionsDoc         json.RawMessage `json:"conditions_doc"`
+	FundingDeadline       *time.Time      `json:"funding_deadline"`
+	InspectionPeriodHours *int            `json:"inspection_period_hours"`
+}
+
+// escrowTerms is the body sent to escrow-service's CreateEscrow.
+type escrowTerms struct {
+	SellerID              uint            `json:"seller_id"`
+	Amount                money.Amount    `json:"amount"`
+	Currency              string          `json:"currency"`
+	Conditions            string          `json:"conditions"`
+	ConditionsDoc         json.RawMessage `json:"conditions_doc,omitempty"`
+	FundingDeadline       *time.Time      `json:"funding_deadline,omitempty"`
+	InspectionPeriodHours int             `json:"inspection_period_hours,omitempty"`
+	InitiatedBy           string          `json:"initiated_by"`
+}
+
+// StartEscrowFromListing opens an escrow between the caller, as buyer, and
+// the listing's seller, with the listing's price and description as the
+// terms. Escrow-service validates and creates it exactly as if the buyer
+// had called CreateEscrow, and its response is passed through unchanged.
+func StartEscrowFromListing(c fiber.Ctx) error {
+	listing, buyerID, status, msg := loadListing(c)
+	if listing == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	if listing.SellerID == buyerID {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "You cannot buy your own listing",
+		})
+	}
+	if listing.Status != model.ListingActive {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Listing is paused",
+		})
+	}
+
+	var req startEscrowRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().Body(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+		}
+	}
+	terms := escrowTerms{
+		SellerID:              listing.SellerID,
+		Amount:                listing.Price,
+		Currency:              listing.Currency,
+		Conditions:            listing.Conditions(),
+		ConditionsDoc:         req.ConditionsDoc,
+		FundingDeadline:       req.FundingDeadline,
+		InspectionPeriodHours: listing.InspectionPeriodHours,
+		InitiatedBy:           "buyer",
+	}
+	if req.Amount != nil {
+		if *req.Amount < listing.Price {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Amount cannot be below the listing price; ask the seller to lower the price",
+			})
+		}
+		terms.Amount = *req.Amount
+	}
+	if req.Conditions != nil {
+		terms.Conditions = *req.Conditions
+	}
+	if req.InspectionPeriodHours != nil {
+		if *req.InspectionPeriodHours < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Inspection period must not be negative",
+			})
+		}
+		terms.InspectionPeriodHours = *req.InspectionPeriodHours
+	}
+	customTerms := terms.Amount != listing.Price ||
+		terms.Conditions != listing.Conditions() ||
+		len(terms.ConditionsDoc) > 0 ||
+		terms.InspectionPeriodHours != listing.InspectionPeriodHours
+
+	resp, err := escrowClient.CreateEscrow(c.Context(), buyerID, c.Get("Idempotency-Key"), terms)
+	if err != nil {
+		log.Printf("Failed to create escrow for listing %d: %v", listing.ID, err)
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"error": "Escrow service is unavailable",
+		})
+	}
+	if escrowID := resp.EscrowID(); escrowID != 0 {
+		record := model.ListingEscrow{
+			ListingID:             listing.ID,
+			BuyerID:               buyerID,
+			EscrowID:              escrowID,
+			Amount:                terms.Amount,
+			InspectionPeriodHours: terms.InspectionPeriodHours,
+			CustomTerms:           customTerms,
+		}
+		db := c.Locals("db").(*gorm.DB)
+		// A replayed response names an escrow that is already recorded.
+		if err := db.Where(model.ListingEscrow{EscrowID: escrowID}).FirstOrCreate(&record).Error; err != nil {
+			log.Printf("Failed to record escrow %d for listing %d: %v", escrowID, listing.ID, err)
+		}
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Status(resp.Status).Send(resp.Body)
+}
+
+// ListListingEscrows returns the escrows buyers started from one of the
+// caller's listings, newest first.
+func ListListingEscrows(c fiber.Ctx) error {
+	listing, status, msg := loadOwnListing(c)
+	if listing == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	db := c.Locals("db").(*gorm.DB)
+	var escrows []model.ListingEscrow
+	if err := db.Where("listing_id = ?", listing.ID).Order("created_at DESC").Find(&escrows).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch escrows",
+		})
+	}
+	return c.JSON(fiber.Map{"escrows": escrows})
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"listing_service/internal/model"
+	"listing_service/internal/storage"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+const (
+	maxImageSize        = 5 << 20
+	maxImagesPerListing = 8
+)
+
+// imageTypes are the content types accepted as listing images, as detected
+// from the file itself rather than trusted from the client.
+var imageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// UploadListingImage adds a picture to one of the caller's listings. The
+// request is multipart with an "image" part.
+func UploadListingImage(c fiber.Ctx) error {
+	listing, status, msg := loadOwnListing(c)
+	if listing == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	if len(listing.Images) >= maxImagesPerListing {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("A listing can have at most %d images", maxImagesPerListing),
+		})
+	}
+
+	header, err := c.FormFile("image")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "image is required",
+		})
+	}
+	if header.Size > maxImageSize {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"error": fmt.Sprintf("Images must be at most %d MB", maxImageSize>>20),
+		})
+	}
+	f, err := header.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to read image",
+		})
+	}
+	defer f.Close()
+	content, err := io.ReadAll(io.LimitReader(f, maxImageSize+1))
+	if err != nil || len(content) == 0 || len(content) > maxImageSize {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("Images must be between 1 byte and %d MB", maxImageSize>>20),
+		})
+	}
+	contentType, _, _ := strings.Cut(http.DetectContentType(content), ";")
+	if !imageTypes[contentType] {
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{
+			"error": "Images must be JPEG, PNG, GIF or WebP",
+		})
+	}
+
+	name := make([]byte, 16)
+	if _, err := rand.Read(name); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to store image",
+		})
+	}
+	key := fmt.Sprintf("listings/%d/%s", listing.ID, hex.EncodeToString(name))
+	store := c.Locals("storage").(storage.Store)
+	if err := store.Put(c.Context(), key, bytes.NewReader(content)); err != nil {
+		log.Printf("Failed to store image for listing %d: %v", listing.ID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to store image",
+		})
+	}
+
+	position := 1
+	if n := len(listing.Images); n > 0 {
+		position = listing.Images[n-1].Position + 1
+	}
+	image := model.ListingImage{
+		ListingID:   listing.ID,
+		Position:    position,
+		ContentType: contentType,
+		Size:        int64(len(content)),
+		StorageKey:  key,
+	}
+	db := c.Locals("db").(*gorm.DB)
+	if err := db.Create(&image).Error; err != nil {
+		store.Delete(c.Context(), key)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save image",
+		})
+	}
+	image.AfterFind(db)
+	return c.Status(fiber.StatusCreated).JSON(image)
+}
+
+// loadImage returns the image named by the :imageId parameter of the
+// listing.
+func loadImage(c fiber.Ctx, listing *model.Listing) *model.ListingImage {
+	imageID, err := strconv.ParseUint(c.Params("imageId"), 10, 32)
+	if err != nil {
+		return nil
+	}
+	for i := range listing.Images {
+		if listing.Images[i].ID == uint(imageID) {
+			return &listing.Images[i]
+		}
+	}
+	return nil
+}
+
+// GetListingImage streams a picture of a listing.
+func GetListingImage(c fiber.Ctx) error {
+	listing, _, status, msg := loadListing(c)
+	if listing == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	image := loadImage(c, listing)
+	if image == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Image not found"})
+	}
+
+	store := c.Locals("storage").(storage.Store)
+	r, err := store.Open(c.Context(), image.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Image file is missing"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to read image",
+		})
+	}
+	defer r.Close()
+	content, err := io.ReadAll(io.LimitReader(r, maxImageSize+1))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to read image",
+		})
+	}
+	c.Set(fiber.HeaderContentType, image.ContentType)
+	c.Set(fiber.HeaderCacheControl, "private, max-age=86400")
+	return c.Send(content)
+}
+
+// DeleteListingImage removes a picture from one of the caller's listings.
+func DeleteListingImage(c fiber.Ctx) error {
+	listing, status, msg := loadOwnListing(c)
+	if listing == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	image := loadImage(c, listing)
+	if image == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Image not found"})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	if err := db.Unscoped().Delete(image).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete image",
+		})
+	}
+	store := c.Locals("storage").(storage.Store)
+	if err := store.Delete(c.Context(), image.StorageKey); err != nil {
+		log.Printf("Failed to delete image file %s: %v", image.StorageKey, err)
+	}
+	return c.JSON(fiber.Map{"message": "Image deleted"})
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"errors"
+	"listing_service/internal/auth"
+	"listing_service/internal/escrow"
+	"listing_service/internal/model"
+	"shared/money"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+const (
+	maxListingsPerUser   = 200
+	maxTitleLength       = 120
+	maxDescriptionLength = 5000
+	maxCategoryLength    = 50
+)
+
+var userServiceClient *auth.UserServiceClient
+var escrowClient = escrow.NewClientFromEnv()
+
+func init() {
+	var err error
+	userServiceClient, err = auth.NewUserServiceClient("user-service:50051")
+	if err != nil {
+		panic("failed to initialize user gRPC client: " + err.Error())
+	}
+}
+
+type listingRequest struct {
+	Title                 string              `json:"title"`
+	Description           string              `json:"description"`
+	Price                 money.Amount        `json:"price"`
+	Currency              string              `json:"currency"`
+	Category              string              `json:"category"`
+	Status                model.ListingStatus `json:"status"`
+	InspectionPeriodHours int                 `json:"inspection_period_hours"`
+}
+
+// apply validates the request and copies it onto the listing.
+func (r *listingRequest) apply(l *model.Listing) error {
+	title := strings.TrimSpace(r.Title)
+	if title == "" || len(title) > maxTitleLength {
+		return errors.New("Title is required and must be at most 120 characters")
+	}
+	description := strings.TrimSpace(r.Description)
+	if description == "" || len(description) > maxDescriptionLength {
+		return errors.New("Description is required and must be at most 5000 characters")
+	}
+	if r.Price <= 0 {
+		return errors.New("Price must be greater than zero")
+	}
+	currency, err := model.ParseCurrency(r.Currency)
+	if err != nil {
+		return err
+	}
+	category := strings.ToLower(strings.TrimSpace(r.Category))
+	if category == "" || len(category) > maxCategoryLength {
+		return errors.New("Category is required and must be at most 50 characters")
+	}
+	status := r.Status
+	switch status {
+	case "":
+		status = model.ListingActive
+	case model.ListingActive, model.ListingPaused:
+	default:
+		return errors.New("status must be active or paused")
+	}
+	if r.InspectionPeriodHours < 0 {
+		return errors.New("Inspection period must not be negative")
+	}
+
+	l.Title = title
+	l.Description = description
+	l.Price = r.Price
+	l.Currency = currency
+	l.Category = category
+	l.Status = status
+	l.InspectionPeriodHours = r.InspectionPeriodHours
+	return nil
+}
+
+// checkSeller makes sure buyers can actually start escrows with the
+// seller: their account is activated and they added bank details and a
+// wallet to be paid out to. On failure it returns the status and message
+// to respond with.
+func checkSeller(sellerID uint) (int, string) {
+	user, err := userServiceClient.GetUser(uint32(sellerID))
+	if err != nil || user == nil {
+		return fiber.StatusInternalServerError, "Failed to fetch seller"
+	}
+	if !user.Activated {
+		return fiber.StatusForbidden, "Your account is not activated"
+	}
+	if user.AccountName == nil || user.AccountName.Value == "" ||
+		user.AccountNumber == nil || user.AccountNumber.Value == "" ||
+		user.BankCode == nil || user.BankCode.Value == 0 {
+		return fiber.StatusBadRequest, "Add your bank account details before publishing listings"
+	}
+	if user.WalletAddress == nil || user.WalletAddress.Value == "" {
+		return fiber.StatusBadRequest, "Create a wallet before publishing listings"
+	}
+	return 0, ""
+}
+
+// CreateListing publishes an offer of the caller's on the marketplace.
+func CreateListing(c fiber.Ctx) error {
+	userID, err := strconv.ParseUint(c.Get("X-User-ID"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Missing X-User-ID",
+		})
+	}
+	var req listingRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	listing := model.Listing{SellerID: uint(userID)}
+	if err := req.apply(&listing); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if status, msg := checkSeller(listing.SellerID); status != 0 {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var count int64
+	if err := db.Model(&model.Listing{}).Where("seller_id = ?", userID).Count(&count).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create listing",
+		})
+	}
+	if count >= maxListingsPerUser {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "You can have at most 200 listings",
+		})
+	}
+	if err := db.Create(&listing).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create listing",
+		})
+	}
+	listing.Images = []model.ListingImage{}
+	return c.Status(fiber.StatusCreated).JSON(listing)
+}
+
+// loadListing returns the listing named by the :id parameter with its
+// images, and the caller's ID. Paused listings are only found by their
+// seller. On failure it returns the status and message to respond with.
+func loadListing(c fiber.Ctx) (*model.Listing, uint, int, string) {
+	userID, err := strconv.ParseUint(c.Get("X-User-ID"), 10, 32)
+	if err != nil {
+		return nil, 0, fiber.StatusForbidden, "Missing X-User-ID"
+	}
+	listingID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return nil, 0, fiber.StatusBadRequest, "Invalid listing ID"
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var listing model.Listing
+	if err := db.Preload("Images", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).First(&listing, listingID).Error; err != nil {
+		return nil, 0, fiber.StatusNotFound, "Listing not found"
+	}
+	if listing.Status != model.ListingActive && listing.SellerID != uint(userID) {
+		return nil, 0, fiber.StatusNotFound, "Listing not found"
+	}
+	return &listing, uint(userID), 0, ""
+}
+
+// loadOwnListing is loadListing for the listing's seller only.
+func loadOwnListing(c fiber.Ctx) (*model.Listing, int, string) {
+	listing, userID, status, msg := loadListing(c)
+	if listing == nil {
+		return nil, status, msg
+	}
+	if listing.SellerID != userID {
+		return nil, fiber.StatusForbidden, "Only the seller can change a listing"
+	}
+	return listing, 0, ""
+}
+
+// GetListing returns a listing with its images.
+func GetListing(c fiber.Ctx) error {
+	listing, _, status, msg := loadListing(c)
+	if listing == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	return c.JSON(listing)
+}
+
+// UpdateListing replaces a listing's terms. Escrows already started from
+// it keep the terms they were created with.
+func UpdateListing(c fiber.Ctx) error {
+	listing, status, msg := loadOwnListing(c)
+	if listing == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	var req listingRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	wasActive := listing.Status == model.ListingActive
+	if err := req.apply(listing); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !wasActive && listing.Status == model.ListingActive {
+		if status, msg := checkSeller(listing.SellerID); status != 0 {
+			return c.Status(status).JSON(fiber.Map{"error": msg})
+		}
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	if err := db.Omit("Images").Save(listing).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update listing",
+		})
+	}
+	return c.JSON(listing)
+}
+
+// DeleteListing removes a listing from the marketplace. Its images are
+// kept with the soft-deleted row, as escrows may still refer to it.
+func DeleteListing(c fiber.Ctx) error {
+	listing, status, msg := loadOwnListing(c)
+	if listing == nil {
+		return c.Status(status).JSON(fiber.Map{"error": msg})
+	}
+	db := c.Locals("db").(*gorm.DB)
+	if err := db.Delete(&model.Listing{}, listing.ID).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete listing",
+		})
+	}
+	return c.JSON(fiber.Map{"message": "Listing deleted"})
+}
+
+// ListMyListings returns the caller's listings, active and paused, newest
+// first.
+func ListMyListings(c fiber.Ctx) error {
+	userID, err := strconv.ParseUint(c.Get("X-User-ID"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Missing X-User-ID",
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	var listings []model.Listing
+	if err := db.Preload("Images", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).Where("seller_id = ?", userID).Order("created_at DESC").Find(&listings).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch listings",
+		})
+	}
+	return c.JSON(fiber.Map{"listings": listings})
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"listing_service/internal/db"
+	"listing_service/internal/model"
+	"shared/money"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// SearchListings lets buyers browse active listings. Query parameters:
+//
+//	q          full-text search over title, description and category
+//	category   exact category
+//	seller_id  one seller's listings
+//	currency   listings priced in this currency
+//	min_price, max_price   price range, in major units
+//	sort       relevance (the default with q), newest (otherwise),
+//	           price_asc or price_desc
+//	limit, offset          paging
+func SearchListings(c fiber.Ctx) error {
+	query := c.Locals("db").(*gorm.DB).Model(&model.Listing{}).
+		Where("status = ?", model.ListingActive)
+
+	q := strings.TrimSpace(c.Query("q"))
+	if q != "" {
+		query = query.Where(db.SearchVector+" @@ plainto_tsquery('english', ?)", q)
+	}
+	if category := strings.ToLower(strings.TrimSpace(c.Query("category"))); category != "" {
+		query = query.Where("category = ?", category)
+	}
+	if raw := c.Query("seller_id"); raw != "" {
+		sellerID, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid seller_id"})
+		}
+		query = query.Where("seller_id = ?", sellerID)
+	}
+	if raw := c.Query("currency"); raw != "" {
+		currency, err := model.ParseCurrency(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		query = query.Where("currency = ?", currency)
+	}
+	for param, op := range map[string]string{"min_price": ">=", "max_price": "<="} {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		price, err := money.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid " + param})
+		}
+		query = query.Where("price "+op+" ?", price)
+	}
+
+	// Counting and fetching both start from the filters above.
+	query = query.Session(&gorm.Session{})
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to search listings",
+		})
+	}
+
+	sort := c.Query("sort")
+	if sort == "" {
+		sort = "newest"
+		if q != "" {
+			sort = "relevance"
+		}
+	}
+	switch sort {
+	case "relevance":
+		if q == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "sort=relevance requires q",
+			})
+		}
+		query = query.Order(gorm.Expr("ts_rank("+db.SearchVector+", plainto_tsquery('english', ?)) DESC, created_at DESC", q))
+	case "newest":
+		query = query.Order("created_at DESC")
+	case "price_asc":
+		query = query.Order("price ASC, created_at DESC")
+	case "price_desc":
+		query = query.Order("price DESC, created_at DESC")
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "sort must be relevance, newest, price_asc or price_desc",
+		})
+	}
+
+	limit := fiber.Query[int](c, "limit", defaultPageSize)
+	if limit <= 0 || limit > maxPageSize {
+		limit = defaultPageSize
+	}
+	offset := fiber.Query[int](c, "offset", 0)
+	if offset < 0 {
+		offset = 0
+	}
+
+	var listings []model.Listing
+	if err := query.Preload("Images", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("position")
+	}).Limit(limit).Offset(offset).Find(&listings).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to search listings",
+		})
+	}
+	return c.JSON(fiber.Map{
+		"listings": listings,
+		"total":    total,
+		"limit":    limit,
+		"offset":   offset,
+	})
+}
+
+// ListCategories returns the categories of active listings with how many
+// listings each has, largest first.
+func ListCategories(c fiber.Ctx) error {
+	var categories []struct {
+		Category string `json:"category"`
+		Count    int64  `json:"count"`
+	}
+	if err := c.Locals("db").(*gorm.DB).Model(&model.Listing{}).
+		Select("category, COUNT(*) AS count").
+		Where("status = ?", model.ListingActive).
+		Group("category").
+		Order("count DESC, category").
+		Scan(&categories).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch categories",
+		})
+	}
+	return c.JSON(fiber.Map{"categories": categories})
+}
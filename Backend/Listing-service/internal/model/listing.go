@@ -0,0 +1,93 @@
+package model
+
+import (
+	"fmt"
+	"shared/money"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type ListingStatus string
+
+const (
+	// ListingActive listings show up when buyers browse and search.
+	ListingActive ListingStatus = "active"
+	// ListingPaused listings are hidden until their seller reactivates
+	// them.
+	ListingPaused ListingStatus = "paused"
+)
+
+// currencies are the currencies escrows can be denominated in; a listing
+// priced in anything else could not be bought.
+var currencies = []string{"ETB", "USD"}
+
+// ParseCurrency normalizes code and checks that escrows support it. An
+// empty code means ETB.
+func ParseCurrency(code string) (string, error) {
+	if code == "" {
+		return "ETB", nil
+	}
+	c := strings.ToUpper(strings.TrimSpace(code))
+	for _, known := range currencies {
+		if c == known {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported currency %q", code)
+}
+
+// Listing is an offer a seller publishes on the marketplace. Buyers start
+// an escrow from it with its price and description as the terms.
+type Listing struct {
+	gorm.Model
+	SellerID    uint          `gorm:"not null;index" json:"seller_id"`
+	Title       string        `gorm:"type:varchar(120);not null" json:"title"`
+	Description string        `gorm:"type:text;not null" json:"description"`
+	Price       money.Amount  `gorm:"not null;index" json:"price"`
+	Currency    string        `gorm:"type:varchar(3);not null;default:ETB" json:"currency"`
+	Category    string        `gorm:"type:varchar(50);not null;index" json:"category"`
+	Status      ListingStatus `gorm:"type:varchar(16);not null;index" json:"status"`
+	// InspectionPeriodHours is offered to buyers as the escrow's inspection
+	// period; 0 means the platform default.
+	InspectionPeriodHours int            `json:"inspection_period_hours,omitempty"`
+	Images                []ListingImage `gorm:"foreignKey:ListingID" json:"images"`
+}
+
+// Conditions returns the escrow conditions a listing pre-fills.
+func (l *Listing) Conditions() string {
+	return fmt.Sprintf("Marketplace listing #%d: %s\n\n%s", l.ID, l.Title, l.Description)
+}
+
+// ListingImage is a picture of a listing. The file lives in the image
+// store under StorageKey.
+type ListingImage struct {
+	gorm.Model
+	ListingID   uint   `gorm:"not null;index" json:"listing_id"`
+	Position    int    `gorm:"not null" json:"position"`
+	ContentType string `gorm:"type:varchar(64);not null" json:"content_type"`
+	Size        int64  `gorm:"not null" json:"size"`
+	StorageKey  string `gorm:"type:varchar(255);not null" json:"-"`
+	// URL is where clients fetch the image through the gateway.
+	URL string `gorm:"-" json:"url"`
+}
+
+func (i *ListingImage) AfterFind(tx *gorm.DB) error {
+	i.URL = fmt.Sprintf("/api/listings/%d/images/%d", i.ListingID, i.ID)
+	return nil
+}
+
+// ListingEscrow records an escrow a buyer started from a listing, with the
+// terms it was started with.
+type ListingEscrow struct {
+	gorm.Model
+	ListingID             uint         `gorm:"not null;index" json:"listing_id"`
+	BuyerID               uint         `gorm:"not null;index" json:"buyer_id"`
+	EscrowID              uint         `gorm:"not null;uniqueIndex" json:"escrow_id"`
+	Amount                money.Amount `gorm:"not null;default:0" json:"amount"`
+	InspectionPeriodHours int          `json:"inspection_period_hours,omitempty"`
+	// CustomTerms is set when the buyer changed the amount, conditions or
+	// inspection period the listing offered, so the seller knows to review
+	// the escrow before accepting it.
+	CustomTerms bool `gorm:"not null;default:false" json:"custom_terms"`
+}
@@ -0,0 +1,35 @@
+package internal
+
+import (
+	"listing_service/internal/handlers"
+	"listing_service/internal/storage"
+	"shared/idempotency"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+func SetupRoutes(app *fiber.App, db *gorm.DB, store storage.Store) {
+	app.Use(func(c fiber.Ctx) error {
+		c.Locals("db", db)
+		c.Locals("storage", store)
+		return c.Next()
+	})
+	// Retries of POST/PUT/PATCH/DELETE requests that carry an
+	// Idempotency-Key get the first response replayed.
+	app.Use(idempotency.New(db))
+
+	api := app.Group("/api/listings")
+	api.Get("/", handlers.SearchListings)
+	api.Post("/", handlers.CreateListing)
+	api.Get("/mine", handlers.ListMyListings)
+	api.Get("/categories", handlers.ListCategories)
+	api.Get("/:id", handlers.GetListing)
+	api.Put("/:id", handlers.UpdateListing)
+	api.Delete("/:id", handlers.DeleteListing)
+	api.Post("/:id/images", handlers.UploadListingImage)
+	api.Get("/:id/images/:imageId", handlers.GetListingImage)
+	api.Delete("/:id/images/:imageId", handlers.DeleteListingImage)
+	api.Get("/:id/escrows", handlers.ListListingEscrows)
+	api.Post("/:id/escrows", handlers.StartEscrowFromListing)
+}
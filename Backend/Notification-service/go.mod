@@ -6,6 +6,7 @@ require (
 	github.com/SafeDeal/proto/escrow v0.0.0-00010101000000-000000000000
 	gorm.io/driver/postgres v1.6.0
 	message_broker v0.0.0-00010101000000-000000000000
+	shared v0.0.0-00010101000000-000000000000
 )
 
 require (
@@ -33,6 +34,8 @@ require (
 
 replace message_broker => ../../message-broker
 
+replace shared => ../../shared
+
 replace github.com/SafeDeal/proto/escrow => ../../Proto/escrow
 
 require (
@@ -49,5 +52,5 @@ require (
 	golang.org/x/sync v0.16.0 // indirect
 	golang.org/x/text v0.28.0 // indirect
 	google.golang.org/grpc v1.75.0
-	gorm.io/gorm v1.25.10
+	gorm.io/gorm v1.30.0
 )
@@ -64,8 +64,18 @@ func (c *Consumer) Listen() {
 		"escrow.create",
 		"payment.success",
 		"escrow.accepted",
+		"escrow.buyer_accepted",
+		"escrow.declined",
+		"escrow.amendment_proposed",
+		"escrow.amended",
 		"escrow.disputed",
+		"escrow.dispute_withdrawn",
 		"transfer.success",
+		"escrow.settlement_proposed",
+		"escrow.settled",
+		"escrow.arbitrated",
+		"escrow.shipped",
+		"escrow.delivered",
 	}
 
 	for _, key := range routingKeys {
@@ -108,10 +118,30 @@ func (c *Consumer) Listen() {
 				c.handleEscrowFunded(msg.Body)
 			case "escrow.accepted":
 				c.handleEscrowAccepted(msg.Body)
+			case "escrow.buyer_accepted":
+				c.handleBuyerAccepted(msg.Body)
+			case "escrow.declined":
+				c.handleEscrowDeclined(msg.Body)
+			case "escrow.amendment_proposed":
+				c.handleAmendmentProposed(msg.Body)
+			case "escrow.amended":
+				c.handleEscrowAmended(msg.Body)
 			case "escrow.disputed":
 				c.handleEscrowDisputed(msg.Body)
+			case "escrow.dispute_withdrawn":
+				c.handleDisputeWithdrawn(msg.Body)
 			case "transfer.success":
 				c.handleTransferSuccess(msg.Body)
+			case "escrow.settlement_proposed":
+				c.handleSettlementProposed(msg.Body)
+			case "escrow.settled":
+				c.handleEscrowSettled(msg.Body)
+			case "escrow.arbitrated":
+				c.handleEscrowArbitrated(msg.Body)
+			case "escrow.shipped":
+				c.handleEscrowShipped(msg.Body)
+			case "escrow.delivered":
+				c.handleEscrowDelivered(msg.Body)
 			}
 		}
 	}()
@@ -8,6 +8,8 @@ import (
 	"notification_service/internal/websockets"
 	"notification_service/internal/escrow"
 	"message_broker/rabbitmq/events"
+	"shared/money"
+	"time"
 )
 var escrowClient *escrow.EscrowServiceClient
 func init() {
@@ -49,6 +51,15 @@ func (c *Consumer) createNotification(userID uint, title, message, notifType str
     })
   }
 
+// formatMoney renders an amount with its currency code. Events published
+// before escrows had a currency carry none and are in ETB.
+func formatMoney(amount money.Amount, currency string) string {
+	if currency == "" {
+		currency = "ETB"
+	}
+	return fmt.Sprintf("%s %s", currency, amount)
+}
+
 func (c *Consumer) handleEscrowCreated(body []byte) {
 	var event events.CreateEscrowEvent
 	if err := json.Unmarshal(body, &event); err != nil {
@@ -56,11 +67,34 @@ func (c *Consumer) handleEscrowCreated(body []byte) {
 		return
 	}
 
+	if event.RecurringID != 0 {
+		c.notifyRecurringCycle(&event, body)
+		return
+	}
+
+	if event.InitiatedBy == "seller" {
+		c.createNotification(
+			uint(event.SellerID),
+			"Escrow Created",
+			fmt.Sprintf("You sent an escrow offer for %s", formatMoney(event.Amount, event.Currency)),
+			"escrow.created",
+			body,
+		)
+		c.createNotification(
+			uint(event.BuyerID),
+			"Escrow Offer",
+			fmt.Sprintf("A seller sent you an escrow offer for %s. Review and accept it before funding", formatMoney(event.Amount, event.Currency)),
+			"escrow.invitation",
+			body,
+		)
+		return
+	}
+
 	// Notify buyer: "You created this escrow"
 	c.createNotification(
 		uint(event.BuyerID),
 		"Escrow Created",
-		fmt.Sprintf("You created an escrow for ETB %.2f", event.Amount),
+		fmt.Sprintf("You created an escrow for %s", formatMoney(event.Amount, event.Currency)),
 		"escrow.created",
 		body,
 	)
@@ -69,12 +103,36 @@ func (c *Consumer) handleEscrowCreated(body []byte) {
 	c.createNotification(
 		uint(event.SellerID),
 		"Escrow Invitation",
-		fmt.Sprintf("You've been invited to an escrow for ETB %.2f", event.Amount),
+		fmt.Sprintf("You've been invited to an escrow for %s", formatMoney(event.Amount, event.Currency)),
 		"escrow.invitation",
 		body,
 	)
 }
 
+// notifyRecurringCycle tells both parties that a recurring escrow created
+// this cycle's escrow, and asks the buyer to fund it.
+func (c *Consumer) notifyRecurringCycle(event *events.CreateEscrowEvent, body []byte) {
+	amount := formatMoney(event.Amount, event.Currency)
+	buyerMessage := fmt.Sprintf("Escrow #%d for %s, cycle %d of a recurring escrow, is ready. Fund it before the funding deadline", event.ID, amount, event.Cycle)
+	if event.InitiatedBy == "seller" {
+		buyerMessage = fmt.Sprintf("Escrow #%d for %s, cycle %d of a recurring escrow, is ready. Accept and fund it before the funding deadline", event.ID, amount, event.Cycle)
+	}
+	c.createNotification(
+		uint(event.BuyerID),
+		"Recurring Escrow Due",
+		buyerMessage,
+		"escrow.recurring_due",
+		body,
+	)
+	c.createNotification(
+		uint(event.SellerID),
+		"Recurring Escrow Created",
+		fmt.Sprintf("Escrow #%d for %s, cycle %d of a recurring escrow, was created", event.ID, amount, event.Cycle),
+		"escrow.created",
+		body,
+	)
+}
+
 func (c *Consumer) handleEscrowFunded(body []byte) {
 	var event events.PaymentSuccessEvent
 	if err := json.Unmarshal(body, &event); err != nil {
@@ -87,11 +145,15 @@ func (c *Consumer) handleEscrowFunded(body []byte) {
 		log.Printf("Failed to get escrow from escrow-service: %v", err)
 		return
 	 }
+	currency := event.Currency
+	if currency == "" {
+		currency = escrow.Currency
+	}
 
 	 c.createNotification(
 		uint(escrow.BuyerId),
 		"Escrow Funded",
-		fmt.Sprintf("You have funded an Escrow #%d with ETB %.2f", event.EscrowID, event.Amount),
+		fmt.Sprintf("You have funded an Escrow #%d with %s", event.EscrowID, formatMoney(event.Amount, currency)),
 		"escrow.funded",
 		body,
 	)
@@ -99,7 +161,7 @@ func (c *Consumer) handleEscrowFunded(body []byte) {
 	c.createNotification(
 		uint(escrow.SellerId),
 		"Escrow Funded",
-		fmt.Sprintf("Escrow #%d has been funded with ETB %.2f", event.EscrowID, event.Amount),
+		fmt.Sprintf("Escrow #%d has been funded with %s", event.EscrowID, formatMoney(event.Amount, currency)),
 		"escrow.funded",
 		body,
 	)
@@ -129,6 +191,95 @@ func (c *Consumer) handleEscrowAccepted(body []byte) {
 )
 }
 
+func (c *Consumer) handleBuyerAccepted(body []byte) {
+	var event events.EscrowAcceptedEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Printf("Failed to unmarshal EscrowAcceptedEvent: %v", err)
+		return
+	}
+
+	escrow, err := escrowClient.GetEscrow(uint32(event.EscrowID))
+	if err != nil {
+		log.Printf("Failed to get escrow from escrow-service: %v", err)
+		return
+	}
+
+	c.createNotification(
+		uint(escrow.SellerId),
+		"Escrow Accepted",
+		fmt.Sprintf("Buyer %d has accepted the escrow you sent #%d", event.UserID, event.EscrowID),
+		"escrow.accepted",
+		body,
+	)
+}
+
+func (c *Consumer) handleEscrowDeclined(body []byte) {
+	var event events.EscrowDeclinedEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Printf("Failed to unmarshal EscrowDeclinedEvent: %v", err)
+		return
+	}
+
+	// Notify whoever did not decline; usually the buyer who invited the seller.
+	recipient, role := event.BuyerID, "Seller"
+	if event.DeclinedBy == event.BuyerID {
+		recipient, role = event.SellerID, "Buyer"
+	}
+
+	message := fmt.Sprintf("%s %d declined escrow #%d", role, event.DeclinedBy, event.EscrowID)
+	if event.Reason != "" {
+		message += fmt.Sprintf(": %s", event.Reason)
+	}
+	if event.Refunded {
+		message += ". Your payment is being refunded"
+	}
+
+	c.createNotification(
+		uint(recipient),
+		"Escrow Declined",
+		message,
+		"escrow.declined",
+		body,
+	)
+}
+
+func (c *Consumer) handleAmendmentProposed(body []byte) {
+	var event events.EscrowAmendmentProposedEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Printf("Failed to unmarshal EscrowAmendmentProposedEvent: %v", err)
+		return
+	}
+
+	message := fmt.Sprintf("User %d proposed new terms for escrow #%d", event.ProposedBy, event.EscrowID)
+	if event.Amount != nil {
+		message += fmt.Sprintf(": amount %s", formatMoney(*event.Amount, event.Currency))
+	}
+
+	c.createNotification(
+		uint(event.RecipientID),
+		"Escrow Amendment Proposed",
+		message,
+		"escrow.amendment_proposed",
+		body,
+	)
+}
+
+func (c *Consumer) handleEscrowAmended(body []byte) {
+	var event events.EscrowAmendedEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Printf("Failed to unmarshal EscrowAmendedEvent: %v", err)
+		return
+	}
+
+	c.createNotification(
+		uint(event.ProposedBy),
+		"Escrow Amendment Accepted",
+		fmt.Sprintf("Your proposed changes to escrow #%d were accepted", event.EscrowID),
+		"escrow.amended",
+		body,
+	)
+}
+
 func (c *Consumer) handleEscrowDisputed(body []byte) {
 	var event events.EscrowDisputedEvent
 	if err := json.Unmarshal(body, &event); err != nil {
@@ -170,10 +321,14 @@ func (c *Consumer) handleEscrowDisputed(body []byte) {
 		recipientRole = "Buyer"
 	}
 
+	message := fmt.Sprintf("A dispute has been raised by %s on escrow #%d", disputerRole, event.EscrowID)
+	if event.Reason != "" {
+		message += fmt.Sprintf(": %s", event.Reason)
+	}
 	c.createNotification(
 		recipientID,
 		"Dispute Received",
-		fmt.Sprintf("A dispute has been raised by %s on escrow #%d", disputerRole, event.EscrowID),
+		message,
 		"escrow.disputed",
 		body,
 	)
@@ -181,6 +336,32 @@ func (c *Consumer) handleEscrowDisputed(body []byte) {
 	log.Printf("Dispute notifications sent: %d raised with role:%s, %d received with role:%s", disputerID, disputerRole,recipientID,recipientRole)
 }
 
+func (c *Consumer) handleDisputeWithdrawn(body []byte) {
+	var event events.DisputeWithdrawnEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Printf("Failed to unmarshal DisputeWithdrawnEvent: %v", err)
+		return
+	}
+
+	resp, err := escrowClient.GetEscrow(uint32(event.EscrowID))
+	if err != nil {
+		log.Printf("Failed to get escrow from escrow-service: %v", err)
+		return
+	}
+
+	recipientID := uint(resp.BuyerId)
+	if uint32(event.UserID) == resp.BuyerId {
+		recipientID = uint(resp.SellerId)
+	}
+	c.createNotification(
+		recipientID,
+		"Dispute Withdrawn",
+		fmt.Sprintf("The dispute on escrow #%d has been withdrawn", event.EscrowID),
+		"escrow.dispute_withdrawn",
+		body,
+	)
+}
+
 func (c *Consumer) handleTransferSuccess(body []byte) {
 	var event events.TransferSuccessEvent
 	if err := json.Unmarshal(body, &event); err != nil {
@@ -192,6 +373,24 @@ func (c *Consumer) handleTransferSuccess(body []byte) {
 		log.Printf("Failed to get escrow from escrow-service: %v", err)
 	 }
 
+	if event.MilestoneID != 0 {
+		c.createNotification(
+			uint(escrow.BuyerId),
+			"Milestone Released",
+			fmt.Sprintf("Funds for milestone #%d of escrow #%d have been released", event.MilestoneID, event.EscrowID),
+			"milestone.released",
+			body,
+		)
+		c.createNotification(
+			uint(escrow.SellerId),
+			"Milestone Released",
+			fmt.Sprintf("Funds for milestone #%d of escrow #%d will be released within a moment for you", event.MilestoneID, event.EscrowID),
+			"milestone.released",
+			body,
+		)
+		return
+	}
+
 	// Notify buyer
 	c.createNotification(
 		uint(escrow.BuyerId),
@@ -209,4 +408,123 @@ func (c *Consumer) handleTransferSuccess(body []byte) {
 		body,
 
 	)
-}
\ No newline at end of file
+}
+
+func (c *Consumer) handleSettlementProposed(body []byte) {
+	var event events.EscrowSettlementProposedEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Printf("Failed to unmarshal EscrowSettlementProposedEvent: %v", err)
+		return
+	}
+
+	escrow, err := escrowClient.GetEscrow(uint32(event.EscrowID))
+	if err != nil {
+		log.Printf("Failed to get escrow from escrow-service: %v", err)
+		return
+	}
+
+	recipientID := uint(escrow.BuyerId)
+	if uint32(event.ProposedBy) == escrow.BuyerId {
+		recipientID = uint(escrow.SellerId)
+	}
+
+	c.createNotification(
+		recipientID,
+		"Settlement Proposed",
+		fmt.Sprintf("A settlement was proposed for escrow #%d: %.0f%% to the seller, the rest back to the buyer", event.EscrowID, event.SellerPercent),
+		"escrow.settlement_proposed",
+		body,
+	)
+}
+
+func (c *Consumer) handleEscrowSettled(body []byte) {
+	var event events.EscrowSettledEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Printf("Failed to unmarshal EscrowSettledEvent: %v", err)
+		return
+	}
+
+	c.createNotification(
+		uint(event.BuyerID),
+		"Dispute Settled",
+		fmt.Sprintf("Escrow #%d was settled. %s will be returned to you", event.EscrowID, formatMoney(event.BuyerAmount, event.Currency)),
+		"escrow.settled",
+		body,
+	)
+	c.createNotification(
+		uint(event.SellerID),
+		"Dispute Settled",
+		fmt.Sprintf("Escrow #%d was settled. %s will be released to you", event.EscrowID, formatMoney(event.SellerAmount, event.Currency)),
+		"escrow.settled",
+		body,
+	)
+}
+
+func (c *Consumer) handleEscrowArbitrated(body []byte) {
+	var event events.EscrowArbitratedEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Printf("Failed to unmarshal EscrowArbitratedEvent: %v", err)
+		return
+	}
+
+	var ruling string
+	switch event.Outcome {
+	case "release":
+		ruling = "the funds are released to the seller"
+	case "refund":
+		ruling = "the funds are refunded to the buyer"
+	default:
+		ruling = fmt.Sprintf("the funds are split, %.2f%% to the seller", event.SellerPercent)
+	}
+	message := fmt.Sprintf("An arbitrator decided the dispute on escrow #%d: %s", event.EscrowID, ruling)
+	if event.Note != "" {
+		message += ". Note: " + event.Note
+	}
+
+	for _, userID := range []uint32{event.BuyerID, event.SellerID} {
+		c.createNotification(
+			uint(userID),
+			"Dispute Decided",
+			message,
+			"escrow.arbitrated",
+			body,
+		)
+	}
+}
+
+func (c *Consumer) handleEscrowShipped(body []byte) {
+	var event events.EscrowDeliveryEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Printf("Failed to unmarshal EscrowDeliveryEvent: %v", err)
+		return
+	}
+
+	c.createNotification(
+		uint(event.BuyerID),
+		"Order Shipped",
+		fmt.Sprintf("The seller shipped escrow #%d via %s, tracking number %s", event.EscrowID, event.Carrier, event.TrackingNumber),
+		"escrow.shipped",
+		body,
+	)
+}
+
+func (c *Consumer) handleEscrowDelivered(body []byte) {
+	var event events.EscrowDeliveryEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		log.Printf("Failed to unmarshal EscrowDeliveryEvent: %v", err)
+		return
+	}
+
+	message := fmt.Sprintf("The seller marked escrow #%d as delivered. Inspect the goods and confirm receipt or raise a dispute", event.EscrowID)
+	if event.InspectionDeadline != 0 {
+		message += fmt.Sprintf(" before %s, when the funds are released automatically",
+			time.Unix(event.InspectionDeadline, 0).UTC().Format("2006-01-02 15:04 MST"))
+	}
+	c.createNotification(
+		uint(event.BuyerID),
+		"Order Delivered",
+		message,
+		"escrow.delivered",
+		body,
+	)
+}
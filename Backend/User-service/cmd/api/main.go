@@ -5,6 +5,7 @@ import (
 	"net"
 	"user_service/internal"
 	"user_service/internal/auth"
+	"user_service/internal/consumer"
 	"user_service/internal/consul"
 	"user_service/internal/db"
 	"user_service/internal/handlers"
@@ -41,6 +42,7 @@ func main() {
 		log.Fatalf("Migration failed: %v", err)
 	}
     go startGRPCServer(db.DB)
+    go consumer.NewConsumer(db.DB).Listen()
 
     consul.RegisterService("user-service", "user-service", 8081)
 
@@ -10,18 +10,21 @@ require (
 	github.com/google/uuid v1.6.0
 	github.com/hashicorp/consul/api v1.32.1
 	github.com/redis/go-redis/v9 v9.11.0
+	github.com/streadway/amqp v1.1.0
 	golang.org/x/crypto v0.39.0
 	google.golang.org/grpc v1.73.0
 	google.golang.org/protobuf v1.36.6
 	gopkg.in/gomail.v2 v2.0.0-20160411212932-81ebce5c23df
 	gorm.io/driver/postgres v1.6.0
 	gorm.io/gorm v1.30.0
+	message_broker v0.0.0-00010101000000-000000000000
 	shared v0.0.0-00010101000000-000000000000
-
 )
 
 replace shared => ../../shared
 
+replace message_broker => ../../message-broker
+
 replace github.com/SafeDeal/proto/auth => ../../Proto/auth
 
 require (
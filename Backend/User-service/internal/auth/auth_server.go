@@ -69,11 +69,20 @@ func (s *AuthServer) VerifyToken(ctx context.Context, req *v0.VerifyTokenRequest
         return &v0.VerifyTokenResponse{Valid: false}, nil
     }
 
+    // The role is read from the database rather than the token so that a
+    // change takes effect on the next request.
+    var user model.User
+    if err := s.DB.Select("id", "role").First(&user, userID).Error; err != nil {
+        log.Printf("Failed to load role of user %d: %v", userID, err)
+        return &v0.VerifyTokenResponse{Valid: false}, nil
+    }
+
     return &v0.VerifyTokenResponse{
         Valid:     true,
         UserId:    userID,
         SessionId: sessionID,
         ExpiresAt: claims.ExpiresAt.Unix(),
+        Role:      string(user.Role),
     }, nil
 }
 
@@ -114,6 +123,7 @@ func (s *AuthServer) GetUser(ctx context.Context, req *v0.GetUserRequest) (*v0.G
             AccountNumber: accountNumberPb,
             BankCode: bankCodePb,
             Profession: user.Profession,
+            Role: string(user.Role),
             
         },
     }, nil
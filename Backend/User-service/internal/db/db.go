@@ -69,5 +69,14 @@ func RunMigration(db *gorm.DB) error {
 	}
 
 	fmt.Println("✅ Full-text search index created or already exists")
+
+	// There is no admin until someone is promoted, so ADMIN_EMAIL names the
+	// account that gets the role on startup.
+	if email := os.Getenv("ADMIN_EMAIL"); email != "" {
+		if err := db.Model(&model.User{}).Where("email = ?", email).
+			Update("role", model.RoleAdmin).Error; err != nil {
+			return fmt.Errorf("failed to grant admin role: %v", err)
+		}
+	}
 	return nil
 }
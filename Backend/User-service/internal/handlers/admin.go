@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"strconv"
+	"user_service/internal/model"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// RequireRole only lets the request through when the gateway marked the
+// caller with one of roles.
+func RequireRole(roles ...model.Role) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		role := model.Role(c.Get("X-User-Role"))
+		for _, r := range roles {
+			if role == r {
+				return c.Next()
+			}
+		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Insufficient role",
+		})
+	}
+}
+
+// ListUsers returns every account with its role, newest first. It can be
+// narrowed with ?role=.
+func ListUsers(c fiber.Ctx) error {
+	page := 1
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p >= 1 {
+		page = p
+	}
+	pageSize := 20
+	if ps, err := strconv.Atoi(c.Query("page_size")); err == nil && ps >= 1 && ps <= 100 {
+		pageSize = ps
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	query := db.Model(&model.User{})
+	if role := model.Role(c.Query("role")); role != "" {
+		if !role.Valid() {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Unknown role",
+			})
+		}
+		query = query.Where("role = ?", role)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to count users",
+		})
+	}
+	var users []model.User
+	if err := query.Order("created_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&users).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch users",
+		})
+	}
+
+	results := make([]fiber.Map, 0, len(users))
+	for _, u := range users {
+		results = append(results, fiber.Map{
+			"id":         u.ID,
+			"first_name": u.FirstName,
+			"last_name":  u.LastName,
+			"email":      u.Email,
+			"activated":  u.Activated,
+			"role":       u.Role,
+			"created_at": u.CreatedAt,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"users":     results,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// UpdateUserRole grants a role to another user. Admins cannot change their
+// own role, which keeps at least one admin around.
+func UpdateUserRole(c fiber.Ctx) error {
+	type Request struct {
+		Role model.Role `json:"role"`
+	}
+
+	targetID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+	if c.Get("X-User-ID") == c.Params("id") {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You cannot change your own role",
+		})
+	}
+
+	var req Request
+	if err := c.Bind().Body(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if !req.Role.Valid() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Role must be user, support, arbitrator or admin",
+		})
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	result := db.Model(&model.User{}).Where("id = ?", uint(targetID)).Update("role", req.Role)
+	if result.Error != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update role",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Role updated",
+		"id":      targetID,
+		"role":    req.Role,
+	})
+}
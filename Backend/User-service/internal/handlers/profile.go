@@ -42,6 +42,8 @@ func Profile(c fiber.Ctx) error {
         "activated":      user.Activated,
         "account_number": user.AccountNumber,
         "wallet_address": user.WalletAddress,
+        "role":           user.Role,
+        "preferred_currency": user.PreferredCurrency,
     })
 }
 
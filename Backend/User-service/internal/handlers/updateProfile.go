@@ -14,6 +14,7 @@ type UpdateProfileRequest struct {
 	FirstName  *string `json:"first_name" validate:"omitempty,chars_only,min=2,max=50"`
 	LastName   *string `json:"last_name" validate:"omitempty,chars_only,min=2,max=50"`
 	Profession *string `json:"profession" validate:"omitempty,min=2,max=100"`
+	PreferredCurrency *string `json:"preferred_currency" validate:"omitempty,oneof=ETB USD"`
 }
 
 func UpdateProfile(c fiber.Ctx) error {
@@ -66,11 +67,14 @@ func UpdateProfile(c fiber.Ctx) error {
 	if req.Profession != nil {
 		updates["profession"] = *req.Profession
 	}
+	if req.PreferredCurrency != nil {
+		updates["preferred_currency"] = *req.PreferredCurrency
+	}
 
 	
 	if len(updates) == 0 {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "No fields to update. Provide at least one of: first_name, last_name, profession",
+			"error": "No fields to update. Provide at least one of: first_name, last_name, profession, preferred_currency",
 		})
 	}
 
@@ -93,6 +97,7 @@ func UpdateProfile(c fiber.Ctx) error {
 			"first_name":    coalesce(req.FirstName, &user.FirstName),
 			"last_name":     coalesce(req.LastName, &user.LastName),
 			"profession":    coalesce(req.Profession, &user.Profession),
+			"preferred_currency": coalesce(req.PreferredCurrency, &user.PreferredCurrency),
 			"activated":     user.Activated,
 			"version":       user.Version,
 		},
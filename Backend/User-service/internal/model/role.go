@@ -0,0 +1,21 @@
+package model
+
+// Role controls what a user may do beyond their own escrows. Everyone
+// registers as RoleUser; the other roles are granted by an admin.
+type Role string
+
+const (
+	RoleUser       Role = "user"
+	RoleSupport    Role = "support"
+	RoleArbitrator Role = "arbitrator"
+	RoleAdmin      Role = "admin"
+)
+
+// Valid reports whether r is one of the known roles.
+func (r Role) Valid() bool {
+	switch r {
+	case RoleUser, RoleSupport, RoleArbitrator, RoleAdmin:
+		return true
+	}
+	return false
+}
@@ -18,5 +18,8 @@ type User struct {
 	AccountNumber   *string `gorm:"unique;type:varchar(50)"`
 	BankCode        *int    `gorm:"type:int"`
     Profession         string `json:"profession" gorm:"type:varchar(100);not null"`
+    Role      Role   `json:"role" gorm:"type:varchar(20);not null;default:user"`
+    // PreferredCurrency is what dashboards report totals in.
+    PreferredCurrency string `json:"preferred_currency" gorm:"type:varchar(3);not null;default:ETB"`
     
 }
\ No newline at end of file
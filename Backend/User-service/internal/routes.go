@@ -2,6 +2,7 @@ package internal
 
 import (
 	"user_service/internal/handlers"
+	"user_service/internal/model"
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/gorm"
 )
@@ -27,6 +28,10 @@ func SetupRoutes(app *fiber.App, db *gorm.DB) {
         api.Put("/profile/bank-details",handlers.UpdateBankDetails)
         api.Post("/wallet",handlers.CreateWallet)
         api.Get("/search", handlers.SearchUser)
+
+        admin := api.Group("/admin", handlers.RequireRole(model.RoleAdmin))
+        admin.Get("/users", handlers.ListUsers)
+        admin.Patch("/users/:id/role", handlers.UpdateUserRole)
         
         
     }
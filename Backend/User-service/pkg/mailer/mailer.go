@@ -3,7 +3,9 @@ package mailer
 import (
 	"crypto/tls"
 	"fmt"
+	"html"
 	"os"
+	"time"
 
 	"gopkg.in/gomail.v2"
 )
@@ -41,6 +43,35 @@ func (m *Mailer) SendActivationEmail(email, token string) error {
         <a href="%s">%s</a>
     `, link, link))
 
+    return m.send(msg)
+}
+
+// SendEscrowInvitationEmail invites a seller to an escrow. Registered
+// tells whether the address already has an account, in which case they
+// only need to log in to claim it.
+func (m *Mailer) SendEscrowInvitationEmail(email, buyerName, amount, token string, expiresAt time.Time, registered bool) error {
+    msg := gomail.NewMessage()
+    msg.SetHeader("From", m.SenderEmail)
+    msg.SetHeader("To", email)
+    msg.SetHeader("Subject", buyerName+" invited you to an escrow on SafeDeal")
+
+    next := "Create your SafeDeal account with this email address, add your bank details and create a wallet, then follow the link below to claim the escrow."
+    if registered {
+        next = "Log in to SafeDeal, make sure you added your bank details and created a wallet, then follow the link below to claim the escrow."
+    }
+    link := fmt.Sprintf("http://localhost:3000/login?invitation=%s", token)
+    msg.SetBody("text/html", fmt.Sprintf(`
+        <h1>You have been invited to an escrow</h1>
+        <p>%s wants to pay you %s through SafeDeal. The funds are held in escrow until you deliver.</p>
+        <p>%s</p>
+        <a href="%s">%s</a>
+        <p>The invitation expires on %s.</p>
+    `, html.EscapeString(buyerName), amount, next, link, link, expiresAt.UTC().Format("January 2, 2006 15:04 MST")))
+
+    return m.send(msg)
+}
+
+func (m *Mailer) send(msg *gomail.Message) error {
     dialer := gomail.NewDialer(
         m.SMTPHost,
         587,
@@ -10,6 +10,7 @@ require (
 	github.com/hashicorp/consul/api v1.32.1
 	gorm.io/gorm v1.30.0
 	message_broker v0.0.0-00010101000000-000000000000
+	shared v0.0.0-00010101000000-000000000000
 )
 
 replace github.com/SafeDeal/proto/escrow => ../../Proto/escrow
@@ -20,6 +21,8 @@ replace github.com/SafeDeal/proto/payment => ../../Proto/payment
 
 replace message_broker => ../../message-broker
 
+replace shared => ../../shared
+
 replace blockchain_adapter => ../blockchain-adapter
 
 replace SafeDeal/contracts => ../../contracts
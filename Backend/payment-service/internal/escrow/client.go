@@ -30,4 +30,16 @@ func (c *EscrowServiceClient) UpdateEscrowStatus(escrowID uint32, newStatus stri
 func (c *EscrowServiceClient) GetEscrow(id uint32) (*v1.EscrowResponse, error) {
     client := v1.NewEscrowServiceClient(c.conn)
     return client.GetEscrow(context.Background(), &v1.GetEscrowRequest{EscrowId: id})
+}
+
+// StartPayment freezes the escrow's terms before a checkout is opened for
+// the given amounts.
+func (c *EscrowServiceClient) StartPayment(escrowID, buyerID uint32, amountMinor, buyerFeeMinor int64) (*v1.StartPaymentResponse, error) {
+    client := v1.NewEscrowServiceClient(c.conn)
+    return client.StartPayment(context.Background(), &v1.StartPaymentRequest{
+        EscrowId:      escrowID,
+        BuyerId:       buyerID,
+        AmountMinor:   amountMinor,
+        BuyerFeeMinor: buyerFeeMinor,
+    })
 }
\ No newline at end of file
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"strconv"
+
+	"payment_service/internal/model"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// ListAllPayments is the admin view of every escrow payment, newest first.
+// It can be narrowed with ?status=, ?escrow_id= and ?buyer_id=.
+func ListAllPayments(c fiber.Ctx) error {
+	page := 1
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p >= 1 {
+		page = p
+	}
+	pageSize := 20
+	if ps, err := strconv.Atoi(c.Query("page_size")); err == nil && ps >= 1 && ps <= 100 {
+		pageSize = ps
+	}
+
+	db := c.Locals("db").(*gorm.DB)
+	query := db.Model(&model.EscrowPayment{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	for _, filter := range []string{"escrow_id", "buyer_id"} {
+		value := c.Query(filter)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid " + filter,
+			})
+		}
+		query = query.Where(filter+" = ?", id)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to count payments",
+		})
+	}
+	var payments []model.EscrowPayment
+	if err := query.Order("created_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&payments).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch payments",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"payments":  payments,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
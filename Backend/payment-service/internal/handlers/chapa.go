@@ -49,22 +49,25 @@ func HandleChapaWebhook(c fiber.Ctx) error {
 			log.Printf("Payment already completed: %s", payload.TxRef)
 		} else {
 			payment.Status = model.Completed
-			db.Save(&payment)
-			
-
-			
-			producer := rabbitmq.NewProducer()
-			err := producer.PublishPaymentSuccess(
-				payload.TxRef,
-				uint32(payment.EscrowID),
-				uint32(payment.BuyerID),
-				payment.Amount,
-			)
+			// payment.success is queued with the status change, so it is
+			// published if and only if the payment is recorded as completed.
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Save(&payment).Error; err != nil {
+					return err
+				}
+				return rabbitmq.NewProducer(tx).PublishPaymentSuccess(
+					payload.TxRef,
+					uint32(payment.EscrowID),
+					uint32(payment.BuyerID),
+					payment.Amount,
+					payment.Currency,
+				)
+			})
 			if err != nil {
-				log.Printf("Failed to publish event: %v", err)
-			} else {
-				log.Println("Published payment.success event to RabbitMQ")
+				log.Printf("Failed to complete payment %s: %v", payload.TxRef, err)
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to record payment"})
 			}
+			log.Println("Queued payment.success event")
 		}
 
 		
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"fmt"
 	"log"
 	"os"
 	"payment_service/internal/auth"
@@ -9,6 +8,7 @@ import (
 	"payment_service/internal/model"
 	"payment_service/pkg/chapa"
 	"payment_service/pkg/utils"
+	"shared/money"
 	"strconv"
 
 	"github.com/gofiber/fiber/v3"
@@ -65,7 +65,7 @@ func InitiateEscrowPayment(c fiber.Ctx) error {
             "error": "Escrow not found",
         })
     }
-    if escrowResp.Amount <= 0 {
+    if escrowResp.AmountMinor <= 0 {
 		 return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
              "error": "Amount must be greater than zero",
 	       })
@@ -75,6 +75,16 @@ func InitiateEscrowPayment(c fiber.Ctx) error {
             "error": "You are not authorized to fund this escrow",
         })
     }
+    if escrowResp.AwaitingBuyer {
+        return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+            "error": "Accept the escrow before funding it",
+        })
+    }
+    if escrowResp.Status != "Pending" {
+        return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+            "error": "Escrow can no longer be funded",
+        })
+    }
     userResp, err := userServiceClient.GetUser(uint32(buyerID))
 	if err != nil || !userResp.Activated {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "User not found or not activated"})
@@ -88,10 +98,36 @@ func InitiateEscrowPayment(c fiber.Ctx) error {
         })
     }
 
+    // The buyer's share of the platform fee is charged on top of the
+    // escrow amount.
+    platformFee := money.FromMinor(escrowResp.BuyerFeeMinor)
+    total := money.FromMinor(escrowResp.AmountMinor) + platformFee
+
+    // Escrows from before currencies were introduced are in ETB.
+    currency := escrowResp.Currency
+    if currency == "" {
+        currency = "ETB"
+    }
+
+    // The escrow can no longer be amended once its checkout is open, so
+    // the buyer pays exactly the terms read above.
+    started, err := escrowClient.StartPayment(uint32(req.EscrowID), uint32(buyerID), escrowResp.AmountMinor, escrowResp.BuyerFeeMinor)
+    if err != nil {
+        log.Printf("Failed to start payment of escrow %d: %v", req.EscrowID, err)
+        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+            "error": "Failed to initiate payment",
+        })
+    }
+    if !started.Success {
+        return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+            "error": started.Error,
+        })
+    }
+
     txRef := utils.GenerateTxRef()
     paymentURL, _, err := chapaClient.InitiatePayment(chapa.ChapaRequest{
-        Amount:           fmt.Sprintf("%.2f", escrowResp.Amount),
-        Currency:          "ETB",
+        Amount:           total.String(),
+        Currency:          currency,
         Email:             userResp.Email,
         FirstName:         userResp.FirstName,
         LastName:          userResp.LastName,
@@ -115,12 +151,19 @@ func InitiateEscrowPayment(c fiber.Ctx) error {
         EscrowID:       req.EscrowID,
         BuyerID:         uint(buyerID),
         TransactionRef: txRef,
-        Amount:         float64(escrowResp.Amount),
-        Currency:       "ETB",
+        Amount:         total,
+        PlatformFee:    platformFee,
+        Currency:       currency,
         Status:         model.Pending,
         PaymentURL:     paymentURL,
     })
 
-    return c.JSON(fiber.Map{"payment_url": paymentURL, "tx_ref": txRef})
+    return c.JSON(fiber.Map{
+        "payment_url":  paymentURL,
+        "tx_ref":       txRef,
+        "amount":       total,
+        "platform_fee": platformFee,
+        "currency":     currency,
+    })
 }
 
@@ -4,6 +4,7 @@ import (
 	"strconv"
 
 	"payment_service/internal/model"
+	"payment_service/internal/rbac"
 
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/gorm"
@@ -24,6 +25,21 @@ func GetTransactionHistory(c fiber.Ctx) error {
 		})
 	}
 
+	// Support staff can look up another user's history with ?user_id=.
+	if other := c.Query("user_id"); other != "" && other != userIDStr {
+		if !rbac.RoleOf(c).IsStaff() {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Insufficient role",
+			})
+		}
+		userID, err = strconv.ParseUint(other, 10, 32)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid user ID",
+			})
+		}
+	}
+
 	db := c.Locals("db").(*gorm.DB)
 	var transactions []model.EscrowPayment
 
@@ -1,6 +1,10 @@
 package model
 
-import "gorm.io/gorm"
+import (
+    "shared/money"
+
+    "gorm.io/gorm"
+)
 
 type TransactionStatus string
 
@@ -16,7 +20,9 @@ type EscrowPayment struct {
     EscrowID       uint      `gorm:"not null" json:"escrow_id"`
     BuyerID         uint      `gorm:"not null" json:"buyer_id"`
     TransactionRef string    `gorm:"unique;not null" json:"transaction_ref"`
-    Amount         float64   `gorm:"type:decimal(16,2);not null" json:"amount"`
+    // Amount is what the buyer was charged, PlatformFee included.
+    Amount         money.Amount `gorm:"type:decimal(16,2);not null" json:"amount"`
+    PlatformFee    money.Amount `gorm:"type:decimal(16,2);not null;default:0" json:"platform_fee"`
     Currency       string    `gorm:"size:3;not null" json:"currency"`
     Status         TransactionStatus `gorm:"not null" json:"status"`
     PaymentURL     string    `gorm:"type:text" json:"payment_url,omitempty"` 
@@ -2,6 +2,8 @@ package internal
 
 import (
 	"payment_service/internal/handlers"
+	"payment_service/internal/rbac"
+	"shared/idempotency"
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/gorm"
 )
@@ -11,6 +13,9 @@ func SetupRoutes(app *fiber.App, db *gorm.DB) {
         c.Locals("db", db)
         return c.Next()
     })
+    // Retries of POST/PUT/PATCH/DELETE requests that carry an
+    // Idempotency-Key get the first response replayed.
+    app.Use(idempotency.New(db))
     
    app.Post("/webhook/chapa",handlers.HandleChapaWebhook)
    
@@ -20,4 +25,7 @@ func SetupRoutes(app *fiber.App, db *gorm.DB) {
         api.Post("/initiate", handlers.InitiateEscrowPayment)
         api.Get("/transactions",handlers.GetTransactionHistory)
     }
+
+    admin := app.Group("/api/admin/payments", rbac.Require(rbac.Admin))
+    admin.Get("/", handlers.ListAllPayments)
 }
\ No newline at end of file
@@ -12,6 +12,24 @@ import (
 	"gorm.io/gorm"
 )
 
+// parseTransferRef splits a transfer reference of the form "escrow-<id>" or
+// "escrow-<id>-milestone-<milestoneID>".
+func parseTransferRef(ref string) (escrowID, milestoneID uint64, err error) {
+	rest := strings.TrimPrefix(ref, "escrow-")
+	escrowPart, milestonePart, hasMilestone := strings.Cut(rest, "-milestone-")
+	escrowID, err = strconv.ParseUint(escrowPart, 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	if hasMilestone {
+		milestoneID, err = strconv.ParseUint(milestonePart, 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	return escrowID, milestoneID, nil
+}
+
 type PaymentServer struct{
   v1.UnimplementedPaymentServiceServer
   DB *gorm.DB
@@ -21,8 +39,7 @@ func NewPaymentServer(db *gorm.DB) *PaymentServer {
 }
 
 func (s *PaymentServer) FinalizeEscrow(ctx context.Context, req *v1.FinalizeEscrowRequest) (*v1.FinalizeEscrowResponse, error) {
-	escrowIDStr := strings.TrimPrefix(req.Data, "escrow-")
-	escrowID, err := strconv.ParseUint(escrowIDStr, 10, 64)
+	escrowID, milestoneID, err := parseTransferRef(req.Data)
 	if err != nil {
 		return &v1.FinalizeEscrowResponse{
 			Success: false,
@@ -51,18 +68,18 @@ func (s *PaymentServer) FinalizeEscrow(ctx context.Context, req *v1.FinalizeEscr
 	}
 
     id:=uint64(resp.BlockchainEscrowId)
-	producer := rabbitmq.NewProducer()
-	err = producer.PublishTransferSuccess(req.Data, escrowID, &id)
+	producer := rabbitmq.NewProducer(s.DB)
+	err = producer.PublishTransferSuccess(req.Data, escrowID, milestoneID, &id)
 
 	if err != nil {
-		log.Printf("❌ Failed to publish transfer.success: %v", err)
+		log.Printf("❌ Failed to queue transfer.success: %v", err)
 		return &v1.FinalizeEscrowResponse{
 			Success: false,
 			Error:   "Failed to publish event",
 		}, nil
 	}
 
-	log.Print("✅ Published transfer.success")
+	log.Print("✅ Queued transfer.success")
 
 	return &v1.FinalizeEscrowResponse{
 		Success: true,
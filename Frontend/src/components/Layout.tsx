@@ -58,7 +58,7 @@ const Layout = ({ children }: LayoutProps) => {
   const adminNavigation = [
     { name: 'Admin Dashboard', href: '/admin', icon: LayoutDashboard },
   ];
- const navigation = user?.id === 2 ? adminNavigation : normalNavigation;
+ const navigation = user?.role === 'admin' ? adminNavigation : normalNavigation;
   const isActive = (path: string) => location.pathname === path;
 
   return (
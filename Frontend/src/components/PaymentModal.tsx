@@ -7,11 +7,13 @@ interface PaymentModalProps {
   isOpen: boolean;
   onClose: () => void;
   amount: number;
+  currency?: string;
+  platformFee?: number;
   paymentUrl?: string;
   onPaymentComplete?: () => void;
 }
 
-const PaymentModal = ({ isOpen, onClose, amount, paymentUrl, onPaymentComplete }: PaymentModalProps) => {
+const PaymentModal = ({ isOpen, onClose, amount, currency = 'ETB', platformFee = 0, paymentUrl, onPaymentComplete }: PaymentModalProps) => {
   const [isProcessing, setIsProcessing] = useState(false);
   const [paymentStatus, setPaymentStatus] = useState<'pending' | 'processing' | 'completed' | 'failed'>('pending');
 
@@ -92,13 +94,19 @@ const PaymentModal = ({ isOpen, onClose, amount, paymentUrl, onPaymentComplete }
               <div className="flex justify-between items-center mb-2">
                 <span className="text-gray-600">Amount:</span>
                 <span className="font-semibold text-lg">
-                  {formatCurrency(amount)}
+                  {formatCurrency(amount, currency)}
                 </span>
               </div>
+              {platformFee > 0 && (
+                <div className="flex justify-between items-center mb-2">
+                  <span className="text-gray-600">Platform Fee:</span>
+                  <span className="font-medium">{formatCurrency(platformFee, currency)}</span>
+                </div>
+              )}
               <div className="flex justify-between items-center border-t pt-2">
                 <span className="text-gray-600">Total Amount:</span>
                 <span className="font-bold text-lg">
-                  {formatCurrency(amount)}
+                  {formatCurrency(amount + platformFee, currency)}
                 </span>
               </div>
             </div>
@@ -7,6 +7,10 @@ import {
   User,
   SearchUser,
   Escrow,
+  EscrowListParams,
+  EscrowListResponse,
+  FeeQuote,
+  Currency,
   CreateEscrowRequest,
   EscrowPayment,
   BankDetails,
@@ -120,13 +124,24 @@ export const userApi = {
 // Escrow API - Based on backend endpoints
 export const escrowApi = {
     // POST Create-escrow
-    create: (data: CreateEscrowRequest): Promise<AxiosResponse<Escrow>> =>
-        api.post('/api/escrows', data),
+    // idempotencyKey makes retries of the same submission replay the first
+    // response instead of creating a second escrow.
+    create: (data: CreateEscrowRequest, idempotencyKey?: string): Promise<AxiosResponse<Escrow>> =>
+        api.post('/api/escrows', data, idempotencyKey ? { headers: { 'Idempotency-Key': idempotencyKey } } : undefined),
 
-    // GET My Escrows - Get user's escrows
-    // Backend returns: { escrows: Escrow[], summary: { total, active, completed } }
-    getMyEscrows: (): Promise<AxiosResponse<{ escrows: Escrow[]; summary: { total: number; active: number; completed: number } }>> =>
-        api.get('/api/escrows/my'),
+    // GET My Escrows - Get user's escrows a page at a time
+    // Backend returns: { escrows: Escrow[], summary, next_cursor, has_more }
+    getMyEscrows: (params?: EscrowListParams): Promise<AxiosResponse<EscrowListResponse>> =>
+        api.get('/api/escrows/my', { params }),
+
+    // GET All escrows - admin only
+    // Backend returns: { escrows: Escrow[], total, page, page_size }
+    getAllEscrows: (page = 1, pageSize = 100): Promise<AxiosResponse<{ escrows: Escrow[]; total: number; page: number; page_size: number }>> =>
+        api.get('/api/admin/escrows', { params: { page, page_size: pageSize } }),
+
+    // GET Fee quote - platform fee for a new escrow of the given amount
+    quoteFee: (amount: number, currency: Currency = 'ETB'): Promise<AxiosResponse<FeeQuote>> =>
+        api.get('/api/escrows/fees/quote', { params: { amount, currency } }),
 
     // GET Fetch-escrow
     getById: (id: number): Promise<AxiosResponse<Escrow>> =>
@@ -190,6 +205,8 @@ export const paymentApi = {
             }
         } catch { }
 
+        // One key per escrow: a retried initiation gets the same checkout
+        // back instead of failing with "already initiated".
         return api.post('/api/payments/initiate', {
             escrow_id: escrowId,
             amount,
@@ -198,7 +215,7 @@ export const paymentApi = {
             first_name,
             last_name,
             phone_number,
-        });
+        }, { headers: { 'Idempotency-Key': `escrow-${escrowId}-payment` } });
     },
 
     // GET Transaction History
@@ -86,8 +86,8 @@ const AdminDashboard = () => {
     setLoading(true);
     setError(null);
     try {
-      // Fetch all escrows (using existing endpoint; for admins this may return all)
-      const escrowResp = await escrowApi.getMyEscrows();
+      // Fetch all escrows (admin-only endpoint)
+      const escrowResp = await escrowApi.getAllEscrows();
       const payload: any = escrowResp.data;
       const list = Array.isArray(payload)
         ? payload
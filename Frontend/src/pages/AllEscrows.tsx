@@ -17,42 +17,60 @@ import Layout from '../components/Layout';
 import { useAuthStore } from '../store/authStore';
 import { escrowApi } from '../lib/api';
 import { formatCurrency, formatRelativeTime, getStatusColor } from '../lib/utils';
-import { Escrow } from '../types';
+import { Escrow, EscrowSummary } from '../types';
 import { toast } from 'react-hot-toast';
 import LoadingSpinner from '../components/LoadingSpinner';
 
 const AllEscrows = () => {
-  const {  } = useAuthStore();
+  const { user } = useAuthStore();
   const [escrows, setEscrows] = useState<Escrow[]>([]);
   const [isLoading, setIsLoading] = useState(true);
   const [error, setError] = useState<string | null>(null);
   const [searchTerm, setSearchTerm] = useState('');
   const [statusFilter, setStatusFilter] = useState<string>('all');
+  const [summary, setSummary] = useState<EscrowSummary | null>(null);
+  const [nextCursor, setNextCursor] = useState<string>('');
+  const [isLoadingMore, setIsLoadingMore] = useState(false);
 
   useEffect(() => {
     fetchEscrows();
-  }, []);
+  }, [statusFilter]);
 
   const fetchEscrows = async () => {
     setIsLoading(true);
     setError(null);
     try {
-      const response = await escrowApi.getMyEscrows();
-      const payload: any = response.data;
-      const list = Array.isArray(payload)
-        ? payload
-        : Array.isArray(payload?.escrows)
-        ? payload.escrows
-        : [];
-      setEscrows(list as Escrow[]);
+      const response = await escrowApi.getMyEscrows({
+        status: statusFilter === 'all' ? undefined : statusFilter,
+        summary_currency: user?.preferred_currency,
+      });
+      setEscrows(response.data.escrows);
+      setSummary(response.data.summary);
+      setNextCursor(response.data.next_cursor);
     } catch (error: any) {
-      setError(error.response?.data?.message || 'Failed to fetch escrows');
+      setError(error.response?.data?.error || 'Failed to fetch escrows');
       toast.error('Failed to load escrows');
     } finally {
       setIsLoading(false);
     }
   };
 
+  const loadMore = async () => {
+    setIsLoadingMore(true);
+    try {
+      const response = await escrowApi.getMyEscrows({
+        status: statusFilter === 'all' ? undefined : statusFilter,
+        cursor: nextCursor,
+      });
+      setEscrows((prev) => [...prev, ...response.data.escrows]);
+      setNextCursor(response.data.next_cursor);
+    } catch (error: any) {
+      toast.error(error.response?.data?.error || 'Failed to load more escrows');
+    } finally {
+      setIsLoadingMore(false);
+    }
+  };
+
   const handleRefresh = async () => {
     await fetchEscrows();
     toast.success('Escrows refreshed');
@@ -85,8 +103,7 @@ const AllEscrows = () => {
           : escrow.id.toString().includes(term) ||
             escrow.amount.toString().includes(term) ||
             (escrow.conditions || '').toLowerCase().includes(term);
-        const matchesStatus = statusFilter === 'all' || escrow.status === statusFilter;
-        return matchesSearch && matchesStatus;
+        return matchesSearch;
       })
     : [];
 
@@ -240,7 +257,7 @@ const AllEscrows = () => {
                   </div>
                   <div className="text-left sm:text-right">
                     <p className="text-xl font-bold text-gray-900">
-                      {formatCurrency(escrow.amount)}
+                      {formatCurrency(escrow.amount, escrow.currency)}
                     </p>
                     <div className="flex items-center space-x-2 mt-2">
                       <span
@@ -262,35 +279,46 @@ const AllEscrows = () => {
                 </div>
               </motion.div>
             ))}
+            {nextCursor && (
+              <div className="flex justify-center">
+                <button
+                  onClick={loadMore}
+                  disabled={isLoadingMore}
+                  className="btn btn-outline btn-md"
+                >
+                  {isLoadingMore ? 'Loading...' : 'Load more'}
+                </button>
+              </div>
+            )}
           </div>
         )}
 
         {/* Stats Summary */}
-        {!isLoading && !error && escrows.length > 0 && (
+        {!isLoading && !error && summary && summary.total > 0 && (
           <div className="card p-6">
             <h3 className="text-lg font-semibold text-gray-900 mb-4">
               Summary
             </h3>
             <div className="grid grid-cols-2 md:grid-cols-4 gap-4">
               <div className="text-center">
-                <p className="text-2xl font-bold text-gray-900">{escrows.length}</p>
+                <p className="text-2xl font-bold text-gray-900">{summary.total}</p>
                 <p className="text-sm text-gray-600">Total Escrows</p>
               </div>
               <div className="text-center">
                 <p className="text-2xl font-bold text-blue-600">
-                  {escrows.filter(e => e.status === 'Pending' || e.status === 'Funded').length}
+                  {summary.active}
                 </p>
                 <p className="text-sm text-gray-600">Active</p>
               </div>
               <div className="text-center">
                 <p className="text-2xl font-bold text-green-600">
-                  {escrows.filter(e => e.status === 'Released').length}
+                  {summary.completed}
                 </p>
                 <p className="text-sm text-gray-600">Completed</p>
               </div>
               <div className="text-center">
                 <p className="text-2xl font-bold text-gray-900">
-                  {formatCurrency(escrows.reduce((sum, e) => sum + e.amount, 0))}
+                  {formatCurrency(summary.total_amount, summary.currency)}
                 </p>
                 <p className="text-sm text-gray-600">Total Volume</p>
               </div>
@@ -1,4 +1,4 @@
-import { useState, useEffect } from 'react';
+import { useState, useEffect, useRef } from 'react';
 import { useForm } from 'react-hook-form';
 import { motion } from 'framer-motion';
 import { ArrowLeft, User as UserIcon, DollarSign, Shield, CheckCircle } from 'lucide-react';
@@ -6,7 +6,7 @@ import { Link, useNavigate, useSearchParams } from 'react-router-dom';
 import Layout from '../components/Layout';
 import { escrowApi } from '../lib/api';
 import { toast } from 'react-hot-toast';
-import { CreateEscrowRequest } from '../types';
+import { CreateEscrowRequest, FeeQuote } from '../types';
 import { formatCurrency } from '../lib/utils';
 import { useAuthStore } from '../store/authStore';
 
@@ -17,6 +17,10 @@ const CreateEscrow = () => {
   const [isLoading, setIsLoading] = useState(false);
   const [step, setStep] = useState(1);
   const [selectedSeller, setSelectedSeller] = useState<{ id: number; name: string } | null>(null);
+  const [feeQuote, setFeeQuote] = useState<FeeQuote | null>(null);
+  // One idempotency key per distinct submission, so a double click or a
+  // retry after a timeout cannot create the escrow twice.
+  const submission = useRef<{ key: string; body: string } | null>(null);
 
   const {
     register,
@@ -41,6 +45,20 @@ const CreateEscrow = () => {
   >();
 
 
+  // Quote the platform fee once the amount is reviewed
+  const watchedAmount = Number(watch('amount') || 0);
+  const watchedCurrency = watch('currency') || 'ETB';
+  useEffect(() => {
+    if (step !== 3 || watchedAmount <= 0) {
+      setFeeQuote(null);
+      return;
+    }
+    escrowApi
+      .quoteFee(watchedAmount, watchedCurrency)
+      .then((res) => setFeeQuote(res.data))
+      .catch(() => setFeeQuote(null));
+  }, [step, watchedAmount, watchedCurrency]);
+
   // Pre-fill seller name/id if coming from search
   useEffect(() => {
     const sellerName = searchParams.get('seller');
@@ -190,6 +208,7 @@ const CreateEscrow = () => {
       const escrowData: CreateEscrowRequest = {
         seller_id: sellerId,
         amount: parseFloat(data.amount.toString()),
+        currency: data.currency || 'ETB',
         conditions: composedConditions,
       };
 
@@ -227,7 +246,11 @@ const CreateEscrow = () => {
         return;
       }
       
-      const response = await escrowApi.create(escrowData);
+      const body = JSON.stringify(escrowData);
+      if (submission.current?.body !== body) {
+        submission.current = { key: crypto.randomUUID(), body };
+      }
+      const response = await escrowApi.create(escrowData, submission.current.key);
       toast.success('Escrow created successfully!');
       navigate(`/escrow/${response.data.id}`);
     } catch (error: any) {
@@ -505,14 +528,14 @@ const CreateEscrow = () => {
 
                 <div>
                   <label className="block text-sm font-medium text-gray-700 mb-2">
-                    Amount (ETB)
+                    Amount
                   </label>
                   <div className="relative">
                     <input
                       {...register('amount', {
                         required: 'Amount is required',
-                        min: { value: 100, message: 'Minimum amount is 100 ETB' },
-                        max: { value: 1000000, message: 'Maximum amount is 1,000,000 ETB' }
+                        min: { value: 100, message: 'Minimum amount is 100' },
+                        max: { value: 1000000, message: 'Maximum amount is 1,000,000' }
                       })}
                       type="number"
                       className="input w-full pl-8"
@@ -527,6 +550,16 @@ const CreateEscrow = () => {
                   )}
                 </div>
 
+                <div>
+                  <label className="block text-sm font-medium text-gray-700 mb-2">
+                    Currency
+                  </label>
+                  <select {...register('currency')} className="input w-full" defaultValue="ETB">
+                    <option value="ETB">ETB - Ethiopian Birr</option>
+                    <option value="USD">USD - US Dollar</option>
+                  </select>
+                </div>
+
                 <div>
                   <label className="block text-sm font-medium text-gray-700 mb-2">
                     Item Description
@@ -677,17 +710,24 @@ const CreateEscrow = () => {
                   )}
                   <div className="flex justify-between">
                     <span className="text-gray-600">Amount:</span>
-                    <span className="font-medium">{formatCurrency(watch('amount') || 0)}</span>
+                    <span className="font-medium">{formatCurrency(watch('amount') || 0, watchedCurrency)}</span>
                   </div>
                   <div className="flex justify-between">
+                    <span className="text-gray-600">Platform Fee (you pay):</span>
                     <span className="font-medium">
-                      {formatCurrency((watch('amount') || 0))}
+                      {formatCurrency(feeQuote?.buyer_fee || 0, watchedCurrency)}
                     </span>
                   </div>
+                  {!!feeQuote?.seller_fee && (
+                    <div className="flex justify-between">
+                      <span className="text-gray-600">Platform Fee (seller pays):</span>
+                      <span className="font-medium">{formatCurrency(feeQuote.seller_fee, watchedCurrency)}</span>
+                    </div>
+                  )}
                   <div className="flex justify-between border-t pt-4">
                     <span className="text-gray-600">Total to Pay:</span>
                     <span className="font-bold text-lg">
-                      {formatCurrency((watch('amount') || 0))}
+                      {formatCurrency(feeQuote?.buyer_pays ?? (watch('amount') || 0), watchedCurrency)}
                     </span>
                   </div>
                   {(() => {
@@ -28,7 +28,7 @@ import LoadingSpinner from "../components/LoadingSpinner";
 import AdminDashboard from "./AdminDashboard";
 const Dashboard = () => {
   const { user } = useAuthStore();
-  if (user?.id === 2) {
+  if (user?.role === 'admin') {
     return <AdminDashboard />;
   }
   const [escrows, setEscrows] = useState<any[]>([]);
@@ -50,7 +50,7 @@ const Dashboard = () => {
       setStatsLoading(true);
       setError(null);
       try {
-        const response = await escrowApi.getMyEscrows();
+        const response = await escrowApi.getMyEscrows({ limit: 5, summary_currency: user?.preferred_currency });
         const payload: any = response.data;
         const list = Array.isArray(payload)
           ? payload
@@ -96,7 +96,7 @@ const Dashboard = () => {
       setIsLoading(true);
       setStatsLoading(true);
       setError(null);
-      const response = await escrowApi.getMyEscrows();
+      const response = await escrowApi.getMyEscrows({ limit: 5, summary_currency: user?.preferred_currency });
       const payload: any = response.data;
       const list = Array.isArray(payload)
         ? payload
@@ -314,7 +314,7 @@ const Dashboard = () => {
                   {statsLoading ? (
                     <Loader2 className="h-6 w-6 animate-spin" />
                   ) : (
-                    formatCurrency(stats?.total_amount || 0)
+                    formatCurrency(stats?.total_amount || 0, user?.preferred_currency)
                   )}
                 </p>
               </div>
@@ -428,7 +428,7 @@ const Dashboard = () => {
                     </div>
                     <div className="text-right">
                       <p className="font-semibold text-gray-900">
-                        {formatCurrency(escrow.amount)}
+                        {formatCurrency(escrow.amount, escrow.currency)}
                       </p>
                       <span
                         className={`inline-flex items-center px-2.5 py-0.5 rounded-full text-xs font-medium ${getStatusColor(
@@ -576,8 +576,14 @@ const EscrowDetails = () => {
                       Amount
                     </label>
                     <p className="text-lg font-semibold text-gray-900">
-                      {formatCurrency(escrow.amount)}
+                      {formatCurrency(escrow.amount, escrow.currency)}
                     </p>
+                    {(escrow.buyer_fee > 0 || escrow.seller_fee > 0) && (
+                      <p className="text-xs text-gray-500 mt-1">
+                        Platform fee: {formatCurrency(escrow.buyer_fee, escrow.currency)} buyer,{' '}
+                        {formatCurrency(escrow.seller_fee, escrow.currency)} seller
+                      </p>
+                    )}
                   </div>
                   <div>
                     <label className="text-sm font-medium text-gray-600">
@@ -943,6 +949,8 @@ const EscrowDetails = () => {
           isOpen={showPayment}
           onClose={() => setShowPayment(false)}
           amount={escrow.amount}
+          currency={escrow.currency}
+          platformFee={escrow.buyer_fee}
           paymentUrl={payment?.payment_url}
           onPaymentComplete={handlePaymentComplete}
         />
@@ -73,6 +73,7 @@ const Profile = () => {
         first_name: user.first_name || "",
         last_name: user.last_name || "",
         profession: user.profession || "",
+        preferred_currency: user.preferred_currency || "ETB",
       });
       // Set selected bank code for dropdown
       setSelectedBankCode(user.bank_code || null);
@@ -301,6 +302,19 @@ const Profile = () => {
                       </p>
                     )}
                   </div>
+
+                  <div>
+                    <label className="block text-sm font-medium text-gray-700 mb-2">
+                      Preferred Currency
+                    </label>
+                    <select {...registerProfile("preferred_currency")} className="input w-full">
+                      <option value="ETB">ETB - Ethiopian Birr</option>
+                      <option value="USD">USD - US Dollar</option>
+                    </select>
+                    <p className="text-xs text-gray-500 mt-1">
+                      Dashboard totals are reported in this currency.
+                    </p>
+                  </div>
                   
                   <div>
                     <label className="block text-sm font-medium text-gray-700 mb-2">
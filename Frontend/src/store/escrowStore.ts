@@ -1,5 +1,5 @@
 import { create } from 'zustand';
-import { Escrow } from '../types';
+import { Escrow, EscrowSummary } from '../types';
 import { escrowApi } from '../lib/api';
 
 interface EscrowStats {
@@ -10,6 +10,14 @@ interface EscrowStats {
     total_amount: number;
 }
 
+const toStats = (summary: EscrowSummary): EscrowStats => ({
+    total_escrows: summary.total,
+    active_escrows: summary.active,
+    completed_escrows: summary.completed,
+    disputed_escrows: summary.disputed,
+    total_amount: summary.total_amount,
+});
+
 interface EscrowState {
     escrows: Escrow[];
     currentEscrow: Escrow | null;
@@ -58,8 +66,8 @@ export const useEscrowStore = create<EscrowState>((set) => ({
     fetchEscrows: async (limit = 5) => {
         set({ isLoading: true, error: null });
         try {
-            const response = await escrowApi.getMyEscrows();
-            const escrows = response.data.escrows.slice(0, limit);
+            const response = await escrowApi.getMyEscrows({ limit });
+            const escrows = response.data.escrows;
             set({ escrows, isLoading: false });
         } catch (error: any) {
             set({
@@ -71,17 +79,8 @@ export const useEscrowStore = create<EscrowState>((set) => ({
     fetchStats: async () => {
         set({ statsLoading: true, error: null });
         try {
-            const response = await escrowApi.getMyEscrows();
-            const escrows = response.data.escrows;
-
-            // Calculate stats from escrows
-            const stats = {
-                total_escrows: escrows.length,
-                active_escrows: escrows.filter(e => e.status === 'Pending' || e.status === 'Funded').length,
-                completed_escrows: escrows.filter(e => e.status === 'Released').length,
-                disputed_escrows: escrows.filter(e => e.status === 'Disputed').length,
-                total_amount: escrows.reduce((sum, e) => sum + e.amount, 0)
-            };
+            const response = await escrowApi.getMyEscrows({ limit: 1 });
+            const stats = toStats(response.data.summary);
 
             set({ stats, statsLoading: false });
         } catch (error: any) {
@@ -107,20 +106,14 @@ export const useEscrowStore = create<EscrowState>((set) => ({
         set({ isLoading: true, statsLoading: true, error: null });
         try {
             const [escrowsResponse] = await Promise.all([
-                escrowApi.getMyEscrows()
+                escrowApi.getMyEscrows({ limit: 5 })
             ]);
 
             const escrows = escrowsResponse.data.escrows;
-            const stats = {
-                total_escrows: escrows.length,
-                active_escrows: escrows.filter(e => e.status === 'Pending' || e.status === 'Funded').length,
-                completed_escrows: escrows.filter(e => e.status === 'Released').length,
-                disputed_escrows: escrows.filter(e => e.status === 'Disputed').length,
-                total_amount: escrows.reduce((sum, e) => sum + e.amount, 0)
-            };
+            const stats = toStats(escrowsResponse.data.summary);
 
             set({
-                escrows: escrows.slice(0, 5), // Show recent 5 on dashboard
+                escrows, // Show recent 5 on dashboard
                 stats,
                 isLoading: false,
                 statsLoading: false
@@ -10,10 +10,14 @@ export interface User {
     account_name?: string;
     account_number?: string;
     bank_code?: number;
+    role?: 'user' | 'support' | 'arbitrator' | 'admin';
+    preferred_currency?: Currency;
     created_at: string;
     updated_at: string;
 }
 
+export type Currency = 'ETB' | 'USD';
+
 export interface SearchUser {
     id: number;
     first_name: string;
@@ -46,6 +50,7 @@ export interface UpdateProfileRequest {
     first_name?: string;
     last_name?: string;
     profession?: string;
+    preferred_currency?: Currency;
 }
 
 export type EscrowStatus = 'Pending' | 'Funded' | 'Released' | 'Disputed';
@@ -55,6 +60,7 @@ export interface Escrow {
     buyer_id: number;
     seller_id: number;
     amount: number;
+    currency: Currency;
     status: EscrowStatus;
     conditions?: string;
     blockchain_tx_hash?: string;
@@ -64,11 +70,59 @@ export interface Escrow {
     updated_at: string;
     buyer?: User;
     seller?: User;
+    fee_schedule_id?: number;
+    buyer_fee: number;
+    seller_fee: number;
+}
+
+export interface FeeQuote {
+    schedule_id?: number;
+    amount: number;
+    fee: number;
+    buyer_fee: number;
+    seller_fee: number;
+    buyer_pays: number;
+    seller_receives: number;
+}
+
+export interface EscrowSummary {
+    total: number;
+    active: number;
+    completed: number;
+    disputed: number;
+    total_amount: number;
+    currency: Currency;
+    totals_by_currency: Record<string, number>;
+    missing_rates?: string[];
+}
+
+export interface EscrowListParams {
+    status?: string;
+    role?: 'buyer' | 'seller';
+    counterparty_id?: number;
+    min_amount?: number;
+    max_amount?: number;
+    created_from?: string;
+    created_to?: string;
+    active?: boolean;
+    sort?: 'created_at' | 'updated_at' | 'amount';
+    order?: 'asc' | 'desc';
+    limit?: number;
+    cursor?: string;
+    summary_currency?: Currency;
+}
+
+export interface EscrowListResponse {
+    escrows: Escrow[];
+    summary: EscrowSummary;
+    next_cursor: string;
+    has_more: boolean;
 }
 
 export interface CreateEscrowRequest {
     seller_id: number;
     amount: number;
+    currency?: Currency;
     conditions?: string;
 }
 
@@ -1,5 +1,10 @@
 module message_broker
 
-go 1.23.4
+go 1.24.5
 
-require github.com/streadway/amqp v1.1.0
+require (
+	github.com/streadway/amqp v1.1.0
+	shared v0.0.0-00010101000000-000000000000
+)
+
+replace shared => ../shared
@@ -20,6 +20,19 @@ func NewEscrowAcceptedEvent(escrowID uint64, userID uint32) *EscrowAcceptedEvent
 	}
 }
 
+// NewEscrowBuyerAcceptedEvent is published when the buyer accepts an escrow
+// the seller created.
+func NewEscrowBuyerAcceptedEvent(escrowID uint64, userID uint32) *EscrowAcceptedEvent {
+	return &EscrowAcceptedEvent{
+		BaseEvent: BaseEvent{
+			Type:      "escrow.buyer_accepted",
+			Timestamp: time.Now().Unix(),
+		},
+		EscrowID: escrowID,
+		UserID:   userID,
+	}
+}
+
 func (e *EscrowAcceptedEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
\ No newline at end of file
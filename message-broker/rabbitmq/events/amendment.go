@@ -0,0 +1,73 @@
+package events
+
+import (
+	"encoding/json"
+	"shared/money"
+	"time"
+)
+
+// EscrowAmendmentProposedEvent is published when a party proposes new terms
+// for an unfunded escrow.
+type EscrowAmendmentProposedEvent struct {
+	BaseEvent
+	EscrowID    uint64        `json:"escrow_id"`
+	AmendmentID uint64        `json:"amendment_id"`
+	ProposedBy  uint32        `json:"proposed_by"`
+	RecipientID uint32        `json:"recipient_id"`
+	Amount      *money.Amount `json:"amount,omitempty"`
+	Currency    string        `json:"currency,omitempty"`
+	Conditions  *string       `json:"conditions,omitempty"`
+}
+
+func NewEscrowAmendmentProposedEvent(escrowID, amendmentID uint64, proposedBy, recipientID uint32, amount *money.Amount, currency string, conditions *string) *EscrowAmendmentProposedEvent {
+	return &EscrowAmendmentProposedEvent{
+		BaseEvent: BaseEvent{
+			Type:      "escrow.amendment_proposed",
+			Timestamp: time.Now().Unix(),
+		},
+		EscrowID:    escrowID,
+		AmendmentID: amendmentID,
+		ProposedBy:  proposedBy,
+		RecipientID: recipientID,
+		Amount:      amount,
+		Currency:    currency,
+		Conditions:  conditions,
+	}
+}
+
+func (e *EscrowAmendmentProposedEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// EscrowAmendedEvent is published when an amendment is accepted. The escrow
+// worker uses it to replace the on-chain record when the amount changed.
+type EscrowAmendedEvent struct {
+	BaseEvent
+	EscrowID      uint64       `json:"escrow_id"`
+	AmendmentID   uint64       `json:"amendment_id"`
+	ProposedBy    uint32       `json:"proposed_by"`
+	AcceptedBy    uint32       `json:"accepted_by"`
+	Amount        money.Amount `json:"amount"`
+	Currency      string       `json:"currency,omitempty"`
+	AmountChanged bool         `json:"amount_changed"`
+}
+
+func NewEscrowAmendedEvent(escrowID, amendmentID uint64, proposedBy, acceptedBy uint32, amount money.Amount, currency string, amountChanged bool) *EscrowAmendedEvent {
+	return &EscrowAmendedEvent{
+		BaseEvent: BaseEvent{
+			Type:      "escrow.amended",
+			Timestamp: time.Now().Unix(),
+		},
+		EscrowID:      escrowID,
+		AmendmentID:   amendmentID,
+		ProposedBy:    proposedBy,
+		AcceptedBy:    acceptedBy,
+		Amount:        amount,
+		Currency:      currency,
+		AmountChanged: amountChanged,
+	}
+}
+
+func (e *EscrowAmendedEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
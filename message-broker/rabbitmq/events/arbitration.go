@@ -0,0 +1,43 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EscrowArbitratedEvent is published once an arbitrator has decided a
+// dispute and the payout for the outcome has been started.
+type EscrowArbitratedEvent struct {
+	BaseEvent
+	EscrowID      uint64  `json:"escrow_id"`
+	DisputeID     uint64  `json:"dispute_id"`
+	BuyerID       uint32  `json:"buyer_id"`
+	SellerID      uint32  `json:"seller_id"`
+	ArbitratorID  uint32  `json:"arbitrator_id"`
+	Outcome       string  `json:"outcome"`
+	SellerPercent float64 `json:"seller_percent,omitempty"`
+	FollowedAI    bool    `json:"followed_ai"`
+	Note          string  `json:"note,omitempty"`
+}
+
+func NewEscrowArbitratedEvent(escrowID, disputeID uint64, buyerID, sellerID, arbitratorID uint32, outcome string, sellerPercent float64, followedAI bool, note string) *EscrowArbitratedEvent {
+	return &EscrowArbitratedEvent{
+		BaseEvent: BaseEvent{
+			Type:      "escrow.arbitrated",
+			Timestamp: time.Now().Unix(),
+		},
+		EscrowID:      escrowID,
+		DisputeID:     disputeID,
+		BuyerID:       buyerID,
+		SellerID:      sellerID,
+		ArbitratorID:  arbitratorID,
+		Outcome:       outcome,
+		SellerPercent: sellerPercent,
+		FollowedAI:    followedAI,
+		Note:          note,
+	}
+}
+
+func (e *EscrowArbitratedEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
@@ -1,15 +1,26 @@
 package events
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"shared/money"
+)
 
 type CreateEscrowEvent struct {
 	BaseEvent
-	ID         uint64  `json:"id"`
-	BuyerID    uint32  `json:"buyer_id"`
-	SellerID   uint32  `json:"seller_id"`
-	Amount     float64 `json:"amount"`
+	ID         uint64       `json:"id"`
+	BuyerID    uint32       `json:"buyer_id"`
+	SellerID   uint32       `json:"seller_id"`
+	Amount     money.Amount `json:"amount"`
+	// Currency is the escrow's ISO 4217 code; empty means ETB.
+	Currency   string  `json:"currency,omitempty"`
 	BuyerAddr  string  `json:"buyer_addr"`
 	SellerAddr string  `json:"seller_addr"`
+	// InitiatedBy is "buyer" or "seller"; empty means buyer.
+	InitiatedBy string `json:"initiated_by,omitempty"`
+	// RecurringID and Cycle are set on escrows spawned by a recurring
+	// schedule.
+	RecurringID uint64 `json:"recurring_id,omitempty"`
+	Cycle       int    `json:"cycle,omitempty"`
 }
 
 func (e *CreateEscrowEvent) ToJSON() ([]byte, error) {
@@ -0,0 +1,37 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EscrowDeclinedEvent is published when the invited party turns an escrow
+// down. Refunded is set when the buyer had already funded it.
+type EscrowDeclinedEvent struct {
+	BaseEvent
+	EscrowID   uint64 `json:"escrow_id"`
+	BuyerID    uint32 `json:"buyer_id"`
+	SellerID   uint32 `json:"seller_id"`
+	DeclinedBy uint32 `json:"declined_by"`
+	Reason     string `json:"reason,omitempty"`
+	Refunded   bool   `json:"refunded"`
+}
+
+func NewEscrowDeclinedEvent(escrowID uint64, buyerID, sellerID, declinedBy uint32, reason string, refunded bool) *EscrowDeclinedEvent {
+	return &EscrowDeclinedEvent{
+		BaseEvent: BaseEvent{
+			Type:      "escrow.declined",
+			Timestamp: time.Now().Unix(),
+		},
+		EscrowID:   escrowID,
+		BuyerID:    buyerID,
+		SellerID:   sellerID,
+		DeclinedBy: declinedBy,
+		Reason:     reason,
+		Refunded:   refunded,
+	}
+}
+
+func (e *EscrowDeclinedEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
@@ -0,0 +1,45 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EscrowDeliveryEvent is published when the seller marks an escrow's goods
+// as shipped ("escrow.shipped") or delivered ("escrow.delivered").
+// InspectionDeadline is when the escrow is released unless the buyer
+// confirms or disputes first: the end of the inspection period on
+// deliveries, the end of the transit window on shipments.
+type EscrowDeliveryEvent struct {
+	BaseEvent
+	EscrowID           uint64 `json:"escrow_id"`
+	DeliveryID         uint64 `json:"delivery_id"`
+	BuyerID            uint32 `json:"buyer_id"`
+	SellerID           uint32 `json:"seller_id"`
+	Carrier            string `json:"carrier,omitempty"`
+	TrackingNumber     string `json:"tracking_number,omitempty"`
+	InspectionDeadline int64  `json:"inspection_deadline,omitempty"`
+}
+
+func NewEscrowDeliveryEvent(eventType string, escrowID, deliveryID uint64, buyerID, sellerID uint32, carrier, trackingNumber string, inspectionDeadline *time.Time) *EscrowDeliveryEvent {
+	event := &EscrowDeliveryEvent{
+		BaseEvent: BaseEvent{
+			Type:      eventType,
+			Timestamp: time.Now().Unix(),
+		},
+		EscrowID:       escrowID,
+		DeliveryID:     deliveryID,
+		BuyerID:        buyerID,
+		SellerID:       sellerID,
+		Carrier:        carrier,
+		TrackingNumber: trackingNumber,
+	}
+	if inspectionDeadline != nil {
+		event.InspectionDeadline = inspectionDeadline.Unix()
+	}
+	return event
+}
+
+func (e *EscrowDeliveryEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
@@ -5,21 +5,52 @@ import "time"
 
 type EscrowDisputedEvent struct {
 	BaseEvent
-	EscrowID uint64 `json:"escrow_id"`
-	UserID   uint32 `json:"user_id"`
+	EscrowID  uint64 `json:"escrow_id"`
+	UserID    uint32 `json:"user_id"`
+	DisputeID uint64 `json:"dispute_id"`
+	Category  string `json:"category,omitempty"`
+	Reason    string `json:"reason,omitempty"`
 }
 
-func NewEscrowDisputedEvent(escrowID uint64, userID uint32) *EscrowDisputedEvent {
+func NewEscrowDisputedEvent(escrowID uint64, userID uint32, disputeID uint64, category, reason string) *EscrowDisputedEvent {
 	return &EscrowDisputedEvent{
 		BaseEvent: BaseEvent{
 			Type:      "escrow.disputed",
 			Timestamp: time.Now().Unix(),
 		},
-		EscrowID: escrowID,
-		UserID:   userID,
+		EscrowID:  escrowID,
+		UserID:    userID,
+		DisputeID: disputeID,
+		Category:  category,
+		Reason:    reason,
 	}
 }
 
 func (e *EscrowDisputedEvent) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
+}
+
+// DisputeWithdrawnEvent is published when the party who raised a dispute
+// withdraws it and the escrow returns to Funded.
+type DisputeWithdrawnEvent struct {
+	BaseEvent
+	EscrowID  uint64 `json:"escrow_id"`
+	UserID    uint32 `json:"user_id"`
+	DisputeID uint64 `json:"dispute_id"`
+}
+
+func NewDisputeWithdrawnEvent(escrowID uint64, userID uint32, disputeID uint64) *DisputeWithdrawnEvent {
+	return &DisputeWithdrawnEvent{
+		BaseEvent: BaseEvent{
+			Type:      "escrow.dispute_withdrawn",
+			Timestamp: time.Now().Unix(),
+		},
+		EscrowID:  escrowID,
+		UserID:    userID,
+		DisputeID: disputeID,
+	}
+}
+
+func (e *DisputeWithdrawnEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
 }
\ No newline at end of file
@@ -2,18 +2,20 @@ package events
 
 import (
 	"encoding/json"
+	"shared/money"
 	"time"
 )
 
 type EscrowFundedEvent struct {
     BaseEvent
-    EscrowID uint32  `json:"escrow_id"`
-    Amount   float64 `json:"amount"`
-    BuyerID  uint32  `json:"buyer_id"`
-    SellerID uint32  `json:"seller_id"`
+    EscrowID uint32       `json:"escrow_id"`
+    Amount   money.Amount `json:"amount"`
+    Currency string       `json:"currency,omitempty"`
+    BuyerID  uint32       `json:"buyer_id"`
+    SellerID uint32       `json:"seller_id"`
 }
 
-func NewEscrowFundedEvent(escrowID, buyerID, sellerID uint32, amount float64) *EscrowFundedEvent {
+func NewEscrowFundedEvent(escrowID, buyerID, sellerID uint32, amount money.Amount, currency string) *EscrowFundedEvent {
     return &EscrowFundedEvent{
         BaseEvent: BaseEvent{
             Type:      "escrow.funded",
@@ -21,6 +23,7 @@ func NewEscrowFundedEvent(escrowID, buyerID, sellerID uint32, amount float64) *E
         },
         EscrowID: escrowID,
         Amount:   amount,
+        Currency: currency,
         BuyerID:  buyerID,
         SellerID: sellerID,
     }
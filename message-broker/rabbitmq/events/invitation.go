@@ -0,0 +1,41 @@
+package events
+
+import (
+	"encoding/json"
+	"shared/money"
+	"time"
+)
+
+// EscrowInvitationEvent is published when a buyer invites a seller who may
+// not have an account yet. User-service mails Token to Email; the escrow is
+// created once the invitee claims it with the token.
+type EscrowInvitationEvent struct {
+	BaseEvent
+	InvitationID uint64       `json:"invitation_id"`
+	BuyerID      uint32       `json:"buyer_id"`
+	Email        string       `json:"email"`
+	Token        string       `json:"token"`
+	Amount       money.Amount `json:"amount"`
+	Currency     string       `json:"currency"`
+	ExpiresAt    int64        `json:"expires_at"`
+}
+
+func NewEscrowInvitationEvent(invitationID uint64, buyerID uint32, email, token string, amount money.Amount, currency string, expiresAt time.Time) *EscrowInvitationEvent {
+	return &EscrowInvitationEvent{
+		BaseEvent: BaseEvent{
+			Type:      "escrow.invitation",
+			Timestamp: time.Now().Unix(),
+		},
+		InvitationID: invitationID,
+		BuyerID:      buyerID,
+		Email:        email,
+		Token:        token,
+		Amount:       amount,
+		Currency:     currency,
+		ExpiresAt:    expiresAt.Unix(),
+	}
+}
+
+func (e *EscrowInvitationEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
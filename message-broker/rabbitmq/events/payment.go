@@ -2,18 +2,20 @@ package events
 
 import (
 	"encoding/json"
+	"shared/money"
 	"time"
 )
 
 type PaymentSuccessEvent struct {
     BaseEvent
-    TransactionRef string  `json:"transaction_ref"`
-    EscrowID       uint32  `json:"escrow_id"`
-    Amount         float64 `json:"amount"`
-    UserID         uint32  `json:"user_id"`
+    TransactionRef string       `json:"transaction_ref"`
+    EscrowID       uint32       `json:"escrow_id"`
+    Amount         money.Amount `json:"amount"`
+    Currency       string       `json:"currency,omitempty"`
+    UserID         uint32       `json:"user_id"`
 }
 
-func NewPaymentSuccessEvent(txRef string, escrowID, userID uint32, amount float64) *PaymentSuccessEvent {
+func NewPaymentSuccessEvent(txRef string, escrowID, userID uint32, amount money.Amount, currency string) *PaymentSuccessEvent {
     return &PaymentSuccessEvent{
         BaseEvent: BaseEvent{
             Type:      "payment.success",
@@ -22,6 +24,7 @@ func NewPaymentSuccessEvent(txRef string, escrowID, userID uint32, amount float6
         TransactionRef: txRef,
         EscrowID:       escrowID,
         Amount:         amount,
+        Currency:       currency,
         UserID:         userID,
     }
 }
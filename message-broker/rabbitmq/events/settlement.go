@@ -0,0 +1,50 @@
+package events
+
+import (
+	"encoding/json"
+	"shared/money"
+	"time"
+)
+
+type EscrowSettlementProposedEvent struct {
+	BaseEvent
+	EscrowID      uint64  `json:"escrow_id"`
+	SettlementID  uint64  `json:"settlement_id"`
+	ProposedBy    uint32  `json:"proposed_by"`
+	SellerPercent float64 `json:"seller_percent"`
+}
+
+func NewEscrowSettlementProposedEvent(escrowID, settlementID uint64, proposedBy uint32, sellerPercent float64) *EscrowSettlementProposedEvent {
+	return &EscrowSettlementProposedEvent{
+		BaseEvent: BaseEvent{
+			Type:      "escrow.settlement_proposed",
+			Timestamp: time.Now().Unix(),
+		},
+		EscrowID:      escrowID,
+		SettlementID:  settlementID,
+		ProposedBy:    proposedBy,
+		SellerPercent: sellerPercent,
+	}
+}
+
+func (e *EscrowSettlementProposedEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+type EscrowSettledEvent struct {
+	BaseEvent
+	EscrowID          uint64       `json:"escrow_id"`
+	SettlementID      uint64       `json:"settlement_id"`
+	BuyerID           uint32       `json:"buyer_id"`
+	SellerID          uint32       `json:"seller_id"`
+	SellerPercent     float64      `json:"seller_percent"`
+	SellerAmount      money.Amount `json:"seller_amount"`
+	BuyerAmount       money.Amount `json:"buyer_amount"`
+	Currency          string       `json:"currency,omitempty"`
+	SellerTransferRef string       `json:"seller_transfer_ref,omitempty"`
+	BuyerTransferRef  string       `json:"buyer_transfer_ref,omitempty"`
+}
+
+func (e *EscrowSettledEvent) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
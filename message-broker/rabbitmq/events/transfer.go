@@ -7,6 +7,8 @@ type TransferSuccessEvent struct {
 	TransferID string `json:"transfer_id"`
 	EscrowID   uint64 `json:"escrow_id"`
 	BlockchainEscrowID uint64 `json:"blockchain_escrow_id"`
+	// MilestoneID is set when the transfer released a single milestone.
+	MilestoneID uint64 `json:"milestone_id,omitempty"`
 }
 
 func (e *TransferSuccessEvent) ToJSON() ([]byte, error) {
@@ -149,14 +149,16 @@ type DecisionRequest struct {
 	EscrowId    string                 `protobuf:"bytes,1,opt,name=escrow_id,json=escrowId,proto3" json:"escrow_id,omitempty"`
 	Description string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
 	Status      string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
-	Amount      float32                `protobuf:"fixed32,4,opt,name=amount,proto3" json:"amount,omitempty"`
 	BuyerId     string                 `protobuf:"bytes,5,opt,name=buyer_id,json=buyerId,proto3" json:"buyer_id,omitempty"`
 	SellerId    string                 `protobuf:"bytes,6,opt,name=seller_id,json=sellerId,proto3" json:"seller_id,omitempty"`
 	Chat        []*ChatMessage         `protobuf:"bytes,7,rep,name=chat,proto3" json:"chat,omitempty"`
 	// disputeConditions are complex and best handled as a JSON string for flexibility
 	DisputeConditionsJson string `protobuf:"bytes,8,opt,name=dispute_conditions_json,json=disputeConditionsJson,proto3" json:"dispute_conditions_json,omitempty"`
-	unknownFields         protoimpl.UnknownFields
-	sizeCache             protoimpl.SizeCache
+	// amount_minor is the escrow amount in minor units (cents, santim)
+	AmountMinor   int64  `protobuf:"varint,9,opt,name=amount_minor,json=amountMinor,proto3" json:"amount_minor,omitempty"`
+	Currency      string `protobuf:"bytes,10,opt,name=currency,proto3" json:"currency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *DecisionRequest) Reset() {
@@ -210,13 +212,6 @@ func (x *DecisionRequest) GetStatus() string {
 	return ""
 }
 
-func (x *DecisionRequest) GetAmount() float32 {
-	if x != nil {
-		return x.Amount
-	}
-	return 0
-}
-
 func (x *DecisionRequest) GetBuyerId() string {
 	if x != nil {
 		return x.BuyerId
@@ -245,6 +240,20 @@ func (x *DecisionRequest) GetDisputeConditionsJson() string {
 	return ""
 }
 
+func (x *DecisionRequest) GetAmountMinor() int64 {
+	if x != nil {
+		return x.AmountMinor
+	}
+	return 0
+}
+
+func (x *DecisionRequest) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
 // Defines the data structure for the AI mediation response
 type MediationResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -347,7 +356,7 @@ var File_ai_arbitrator_v1_arbitrator_proto protoreflect.FileDescriptor
 
 const file_ai_arbitrator_v1_arbitrator_proto_rawDesc = "" +
 	"\n" +
-	"!ai_arbitrator/v1/arbitrator.proto\x12\x10ai_arbitrator.v1\">\n" +
+	"!ai-arbitrator/v1/arbitrator.proto\x12\x10ai_arbitrator.v1\">\n" +
 	"\vChatMessage\x12\x1b\n" +
 	"\tsender_id\x18\x01 \x01(\tR\bsenderId\x12\x12\n" +
 	"\x04text\x18\x02 \x01(\tR\x04text\"\x9c\x01\n" +
@@ -355,16 +364,18 @@ const file_ai_arbitrator_v1_arbitrator_proto_rawDesc = "" +
 	"\tescrow_id\x18\x01 \x01(\tR\bescrowId\x12 \n" +
 	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x16\n" +
 	"\x06status\x18\x03 \x01(\tR\x06status\x121\n" +
-	"\x04chat\x18\x04 \x03(\v2\x1d.ai_arbitrator.v1.ChatMessageR\x04chat\"\xa3\x02\n" +
+	"\x04chat\x18\x04 \x03(\v2\x1d.ai_arbitrator.v1.ChatMessageR\x04chat\"\xd8\x02\n" +
 	"\x0fDecisionRequest\x12\x1b\n" +
 	"\tescrow_id\x18\x01 \x01(\tR\bescrowId\x12 \n" +
 	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x16\n" +
-	"\x06status\x18\x03 \x01(\tR\x06status\x12\x16\n" +
-	"\x06amount\x18\x04 \x01(\x02R\x06amount\x12\x19\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x19\n" +
 	"\bbuyer_id\x18\x05 \x01(\tR\abuyerId\x12\x1b\n" +
 	"\tseller_id\x18\x06 \x01(\tR\bsellerId\x121\n" +
 	"\x04chat\x18\a \x03(\v2\x1d.ai_arbitrator.v1.ChatMessageR\x04chat\x126\n" +
-	"\x17dispute_conditions_json\x18\b \x01(\tR\x15disputeConditionsJson\"-\n" +
+	"\x17dispute_conditions_json\x18\b \x01(\tR\x15disputeConditionsJson\x12!\n" +
+	"\famount_minor\x18\t \x01(\x03R\vamountMinor\x12\x1a\n" +
+	"\bcurrency\x18\n" +
+	" \x01(\tR\bcurrencyJ\x04\b\x04\x10\x05R\x06amount\"-\n" +
 	"\x11MediationResponse\x12\x18\n" +
 	"\amessage\x18\x01 \x01(\tR\amessage\"T\n" +
 	"\x10DecisionResponse\x12\x1a\n" +
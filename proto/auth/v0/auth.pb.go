@@ -72,6 +72,7 @@ type VerifyTokenResponse struct {
 	UserId        uint32                 `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	SessionId     string                 `protobuf:"bytes,3,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
 	ExpiresAt     int64                  `protobuf:"varint,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	Role          string                 `protobuf:"bytes,5,opt,name=role,proto3" json:"role,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -134,6 +135,13 @@ func (x *VerifyTokenResponse) GetExpiresAt() int64 {
 	return 0
 }
 
+func (x *VerifyTokenResponse) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
 type User struct {
 	state         protoimpl.MessageState  `protogen:"open.v1"`
 	Id            uint32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -147,6 +155,7 @@ type User struct {
 	AccountName   *wrapperspb.StringValue `protobuf:"bytes,9,opt,name=account_name,json=accountName,proto3" json:"account_name,omitempty"`
 	AccountNumber *wrapperspb.StringValue `protobuf:"bytes,10,opt,name=account_number,json=accountNumber,proto3" json:"account_number,omitempty"`
 	BankCode      *wrapperspb.Int32Value  `protobuf:"bytes,11,opt,name=bank_code,json=bankCode,proto3" json:"bank_code,omitempty"`
+	Role          string                  `protobuf:"bytes,12,opt,name=role,proto3" json:"role,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -258,6 +267,13 @@ func (x *User) GetBankCode() *wrapperspb.Int32Value {
 	return nil
 }
 
+func (x *User) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
 type GetUserRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        uint32                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
@@ -576,14 +592,15 @@ const file_proto_auth_v0_auth_proto_rawDesc = "" +
 	"\n" +
 	"\x18proto/auth/v0/auth.proto\x12\aauth.v0\x1a\x1egoogle/protobuf/wrappers.proto\"*\n" +
 	"\x12VerifyTokenRequest\x12\x14\n" +
-	"\x05token\x18\x01 \x01(\tR\x05token\"\x82\x01\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\"\x96\x01\n" +
 	"\x13VerifyTokenResponse\x12\x14\n" +
 	"\x05valid\x18\x01 \x01(\bR\x05valid\x12\x17\n" +
 	"\auser_id\x18\x02 \x01(\rR\x06userId\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x03 \x01(\tR\tsessionId\x12\x1d\n" +
 	"\n" +
-	"expires_at\x18\x04 \x01(\x03R\texpiresAt\"\xc5\x03\n" +
+	"expires_at\x18\x04 \x01(\x03R\texpiresAt\x12\x12\n" +
+	"\x04role\x18\x05 \x01(\tR\x04role\"\xd9\x03\n" +
 	"\x04User\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\rR\x02id\x12\x1d\n" +
 	"\n" +
@@ -599,7 +616,8 @@ const file_proto_auth_v0_auth_proto_rawDesc = "" +
 	"\faccount_name\x18\t \x01(\v2\x1c.google.protobuf.StringValueR\vaccountName\x12C\n" +
 	"\x0eaccount_number\x18\n" +
 	" \x01(\v2\x1c.google.protobuf.StringValueR\raccountNumber\x128\n" +
-	"\tbank_code\x18\v \x01(\v2\x1b.google.protobuf.Int32ValueR\bbankCode\")\n" +
+	"\tbank_code\x18\v \x01(\v2\x1b.google.protobuf.Int32ValueR\bbankCode\x12\x12\n" +
+	"\x04role\x18\f \x01(\tR\x04role\")\n" +
 	"\x0eGetUserRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\rR\x06userId\"d\n" +
 	"\x0fGetUserResponse\x12\x18\n" +
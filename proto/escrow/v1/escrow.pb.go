@@ -9,6 +9,7 @@ package v1
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	wrapperspb "google.golang.org/protobuf/types/known/wrapperspb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -24,7 +25,7 @@ const (
 type UpdateEscrowStatusRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	EscrowId      uint32                 `protobuf:"varint,1,opt,name=escrow_id,json=escrowId,proto3" json:"escrow_id,omitempty"`
-	NewStatus     string                 `protobuf:"bytes,2,opt,name=new_status,json=newStatus,proto3" json:"new_status,omitempty"` // e.g., "Funded", "Released"
+	NewStatus     string                 `protobuf:"bytes,2,opt,name=new_status,json=newStatus,proto3" json:"new_status,omitempty"` // only "Funded", for a Pending escrow
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -169,16 +170,23 @@ func (x *GetEscrowRequest) GetEscrowId() uint32 {
 	return 0
 }
 
+// Amounts are exact integers in minor units (cents, santim) of currency.
 type EscrowResponse struct {
 	state              protoimpl.MessageState `protogen:"open.v1"`
 	Id                 uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
 	BuyerId            uint32                 `protobuf:"varint,2,opt,name=buyer_id,json=buyerId,proto3" json:"buyer_id,omitempty"`
 	SellerId           uint32                 `protobuf:"varint,3,opt,name=seller_id,json=sellerId,proto3" json:"seller_id,omitempty"`
-	Amount             float32                `protobuf:"fixed32,4,opt,name=amount,proto3" json:"amount,omitempty"`
 	Status             string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
 	Conditions         string                 `protobuf:"bytes,6,opt,name=conditions,proto3" json:"conditions,omitempty"`
 	BlockchainEscrowId uint32                 `protobuf:"varint,7,opt,name=blockchain_escrow_id,json=blockchainEscrowId,proto3" json:"blockchain_escrow_id,omitempty"`
 	Active             bool                   `protobuf:"varint,8,opt,name=active,proto3" json:"active,omitempty"`
+	InitiatedBy        string                 `protobuf:"bytes,9,opt,name=initiated_by,json=initiatedBy,proto3" json:"initiated_by,omitempty"`           // "buyer" or "seller"
+	AwaitingBuyer      bool                   `protobuf:"varint,10,opt,name=awaiting_buyer,json=awaitingBuyer,proto3" json:"awaiting_buyer,omitempty"`   // seller-initiated and not yet accepted by the buyer
+	ConditionsJson     string                 `protobuf:"bytes,11,opt,name=conditions_json,json=conditionsJson,proto3" json:"conditions_json,omitempty"` // structured conditions document, "" when none
+	Currency           string                 `protobuf:"bytes,14,opt,name=currency,proto3" json:"currency,omitempty"`                                   // ISO 4217 code of amount and fees
+	AmountMinor        int64                  `protobuf:"varint,15,opt,name=amount_minor,json=amountMinor,proto3" json:"amount_minor,omitempty"`
+	BuyerFeeMinor      int64                  `protobuf:"varint,16,opt,name=buyer_fee_minor,json=buyerFeeMinor,proto3" json:"buyer_fee_minor,omitempty"`    // platform fee added to the buyer's checkout
+	SellerFeeMinor     int64                  `protobuf:"varint,17,opt,name=seller_fee_minor,json=sellerFeeMinor,proto3" json:"seller_fee_minor,omitempty"` // platform fee deducted from the seller's payout
 	unknownFields      protoimpl.UnknownFields
 	sizeCache          protoimpl.SizeCache
 }
@@ -234,46 +242,858 @@ func (x *EscrowResponse) GetSellerId() uint32 {
 	return 0
 }
 
-func (x *EscrowResponse) GetAmount() float32 {
+func (x *EscrowResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *EscrowResponse) GetConditions() string {
+	if x != nil {
+		return x.Conditions
+	}
+	return ""
+}
+
+func (x *EscrowResponse) GetBlockchainEscrowId() uint32 {
+	if x != nil {
+		return x.BlockchainEscrowId
+	}
+	return 0
+}
+
+func (x *EscrowResponse) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *EscrowResponse) GetInitiatedBy() string {
+	if x != nil {
+		return x.InitiatedBy
+	}
+	return ""
+}
+
+func (x *EscrowResponse) GetAwaitingBuyer() bool {
+	if x != nil {
+		return x.AwaitingBuyer
+	}
+	return false
+}
+
+func (x *EscrowResponse) GetConditionsJson() string {
+	if x != nil {
+		return x.ConditionsJson
+	}
+	return ""
+}
+
+func (x *EscrowResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *EscrowResponse) GetAmountMinor() int64 {
+	if x != nil {
+		return x.AmountMinor
+	}
+	return 0
+}
+
+func (x *EscrowResponse) GetBuyerFeeMinor() int64 {
+	if x != nil {
+		return x.BuyerFeeMinor
+	}
+	return 0
+}
+
+func (x *EscrowResponse) GetSellerFeeMinor() int64 {
+	if x != nil {
+		return x.SellerFeeMinor
+	}
+	return 0
+}
+
+type ListEvidenceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EscrowId      uint32                 `protobuf:"varint,1,opt,name=escrow_id,json=escrowId,proto3" json:"escrow_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEvidenceRequest) Reset() {
+	*x = ListEvidenceRequest{}
+	mi := &file_proto_escrow_v1_escrow_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEvidenceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEvidenceRequest) ProtoMessage() {}
+
+func (x *ListEvidenceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_escrow_v1_escrow_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEvidenceRequest.ProtoReflect.Descriptor instead.
+func (*ListEvidenceRequest) Descriptor() ([]byte, []int) {
+	return file_proto_escrow_v1_escrow_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListEvidenceRequest) GetEscrowId() uint32 {
+	if x != nil {
+		return x.EscrowId
+	}
+	return 0
+}
+
+// Evidence is the metadata of a file attached to a dispute; the content
+// itself is only served over HTTP.
+type Evidence struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint32                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	DisputeId     uint32                 `protobuf:"varint,2,opt,name=dispute_id,json=disputeId,proto3" json:"dispute_id,omitempty"`
+	UploadedBy    uint32                 `protobuf:"varint,3,opt,name=uploaded_by,json=uploadedBy,proto3" json:"uploaded_by,omitempty"`
+	UploaderRole  string                 `protobuf:"bytes,4,opt,name=uploader_role,json=uploaderRole,proto3" json:"uploader_role,omitempty"` // "buyer" or "seller"
+	FileName      string                 `protobuf:"bytes,5,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	ContentType   string                 `protobuf:"bytes,6,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Size          int64                  `protobuf:"varint,7,opt,name=size,proto3" json:"size,omitempty"`
+	Sha256        string                 `protobuf:"bytes,8,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	Description   string                 `protobuf:"bytes,9,opt,name=description,proto3" json:"description,omitempty"`
+	UploadedAt    int64                  `protobuf:"varint,10,opt,name=uploaded_at,json=uploadedAt,proto3" json:"uploaded_at,omitempty"` // unix seconds
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Evidence) Reset() {
+	*x = Evidence{}
+	mi := &file_proto_escrow_v1_escrow_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Evidence) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Evidence) ProtoMessage() {}
+
+func (x *Evidence) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_escrow_v1_escrow_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Evidence.ProtoReflect.Descriptor instead.
+func (*Evidence) Descriptor() ([]byte, []int) {
+	return file_proto_escrow_v1_escrow_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Evidence) GetId() uint32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Evidence) GetDisputeId() uint32 {
+	if x != nil {
+		return x.DisputeId
+	}
+	return 0
+}
+
+func (x *Evidence) GetUploadedBy() uint32 {
+	if x != nil {
+		return x.UploadedBy
+	}
+	return 0
+}
+
+func (x *Evidence) GetUploaderRole() string {
+	if x != nil {
+		return x.UploaderRole
+	}
+	return ""
+}
+
+func (x *Evidence) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *Evidence) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *Evidence) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Evidence) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *Evidence) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Evidence) GetUploadedAt() int64 {
+	if x != nil {
+		return x.UploadedAt
+	}
+	return 0
+}
+
+type ListEvidenceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Evidence      []*Evidence            `protobuf:"bytes,1,rep,name=evidence,proto3" json:"evidence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEvidenceResponse) Reset() {
+	*x = ListEvidenceResponse{}
+	mi := &file_proto_escrow_v1_escrow_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEvidenceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEvidenceResponse) ProtoMessage() {}
+
+func (x *ListEvidenceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_escrow_v1_escrow_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEvidenceResponse.ProtoReflect.Descriptor instead.
+func (*ListEvidenceResponse) Descriptor() ([]byte, []int) {
+	return file_proto_escrow_v1_escrow_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListEvidenceResponse) GetEvidence() []*Evidence {
+	if x != nil {
+		return x.Evidence
+	}
+	return nil
+}
+
+// RecordRecommendationRequest carries the AI arbitrator's decision on the
+// escrow's open dispute so an arbitrator can approve or override it.
+type RecordRecommendationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EscrowId      uint32                 `protobuf:"varint,1,opt,name=escrow_id,json=escrowId,proto3" json:"escrow_id,omitempty"`
+	Decision      string                 `protobuf:"bytes,2,opt,name=decision,proto3" json:"decision,omitempty"` // "RELEASE_TO_SELLER", "REFUND_TO_BUYER" or "UNDECIDED"
+	Justification string                 `protobuf:"bytes,3,opt,name=justification,proto3" json:"justification,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecordRecommendationRequest) Reset() {
+	*x = RecordRecommendationRequest{}
+	mi := &file_proto_escrow_v1_escrow_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecordRecommendationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordRecommendationRequest) ProtoMessage() {}
+
+func (x *RecordRecommendationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_escrow_v1_escrow_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordRecommendationRequest.ProtoReflect.Descriptor instead.
+func (*RecordRecommendationRequest) Descriptor() ([]byte, []int) {
+	return file_proto_escrow_v1_escrow_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RecordRecommendationRequest) GetEscrowId() uint32 {
+	if x != nil {
+		return x.EscrowId
+	}
+	return 0
+}
+
+func (x *RecordRecommendationRequest) GetDecision() string {
+	if x != nil {
+		return x.Decision
+	}
+	return ""
+}
+
+func (x *RecordRecommendationRequest) GetJustification() string {
+	if x != nil {
+		return x.Justification
+	}
+	return ""
+}
+
+type RecordRecommendationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	DisputeId     uint32                 `protobuf:"varint,3,opt,name=dispute_id,json=disputeId,proto3" json:"dispute_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecordRecommendationResponse) Reset() {
+	*x = RecordRecommendationResponse{}
+	mi := &file_proto_escrow_v1_escrow_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecordRecommendationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordRecommendationResponse) ProtoMessage() {}
+
+func (x *RecordRecommendationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_escrow_v1_escrow_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordRecommendationResponse.ProtoReflect.Descriptor instead.
+func (*RecordRecommendationResponse) Descriptor() ([]byte, []int) {
+	return file_proto_escrow_v1_escrow_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RecordRecommendationResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *RecordRecommendationResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *RecordRecommendationResponse) GetDisputeId() uint32 {
+	if x != nil {
+		return x.DisputeId
+	}
+	return 0
+}
+
+// ListEscrowsRequest pages through escrows with the same filters as
+// GET /api/escrows/my. Unset fields do not restrict the listing.
+type ListEscrowsRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	UserId          uint32                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // 0 lists every user's escrows
+	Role            string                 `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`                    // "buyer" or "seller", requires user_id
+	Statuses        []string               `protobuf:"bytes,3,rep,name=statuses,proto3" json:"statuses,omitempty"`
+	CounterpartyId  uint32                 `protobuf:"varint,4,opt,name=counterparty_id,json=counterpartyId,proto3" json:"counterparty_id,omitempty"` // requires user_id
+	CreatedFrom     int64                  `protobuf:"varint,7,opt,name=created_from,json=createdFrom,proto3" json:"created_from,omitempty"`          // unix seconds, 0 for no bound
+	CreatedTo       int64                  `protobuf:"varint,8,opt,name=created_to,json=createdTo,proto3" json:"created_to,omitempty"`                // unix seconds, 0 for no bound
+	Active          *wrapperspb.BoolValue  `protobuf:"bytes,9,opt,name=active,proto3" json:"active,omitempty"`
+	Sort            string                 `protobuf:"bytes,10,opt,name=sort,proto3" json:"sort,omitempty"`   // "created_at" (default), "updated_at" or "amount"
+	Order           string                 `protobuf:"bytes,11,opt,name=order,proto3" json:"order,omitempty"` // "desc" (default) or "asc"
+	Limit           uint32                 `protobuf:"varint,12,opt,name=limit,proto3" json:"limit,omitempty"`
+	Cursor          string                 `protobuf:"bytes,13,opt,name=cursor,proto3" json:"cursor,omitempty"` // next_cursor of the previous page
+	IncludeSummary  bool                   `protobuf:"varint,14,opt,name=include_summary,json=includeSummary,proto3" json:"include_summary,omitempty"`
+	SummaryCurrency string                 `protobuf:"bytes,15,opt,name=summary_currency,json=summaryCurrency,proto3" json:"summary_currency,omitempty"` // currency the summary total is reported in, ETB by default
+	MinAmountMinor  *wrapperspb.Int64Value `protobuf:"bytes,16,opt,name=min_amount_minor,json=minAmountMinor,proto3" json:"min_amount_minor,omitempty"`
+	MaxAmountMinor  *wrapperspb.Int64Value `protobuf:"bytes,17,opt,name=max_amount_minor,json=maxAmountMinor,proto3" json:"max_amount_minor,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ListEscrowsRequest) Reset() {
+	*x = ListEscrowsRequest{}
+	mi := &file_proto_escrow_v1_escrow_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEscrowsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEscrowsRequest) ProtoMessage() {}
+
+func (x *ListEscrowsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_escrow_v1_escrow_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEscrowsRequest.ProtoReflect.Descriptor instead.
+func (*ListEscrowsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_escrow_v1_escrow_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListEscrowsRequest) GetUserId() uint32 {
 	if x != nil {
-		return x.Amount
+		return x.UserId
 	}
 	return 0
 }
 
-func (x *EscrowResponse) GetStatus() string {
+func (x *ListEscrowsRequest) GetRole() string {
 	if x != nil {
-		return x.Status
+		return x.Role
 	}
 	return ""
 }
 
-func (x *EscrowResponse) GetConditions() string {
+func (x *ListEscrowsRequest) GetStatuses() []string {
 	if x != nil {
-		return x.Conditions
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *ListEscrowsRequest) GetCounterpartyId() uint32 {
+	if x != nil {
+		return x.CounterpartyId
+	}
+	return 0
+}
+
+func (x *ListEscrowsRequest) GetCreatedFrom() int64 {
+	if x != nil {
+		return x.CreatedFrom
+	}
+	return 0
+}
+
+func (x *ListEscrowsRequest) GetCreatedTo() int64 {
+	if x != nil {
+		return x.CreatedTo
+	}
+	return 0
+}
+
+func (x *ListEscrowsRequest) GetActive() *wrapperspb.BoolValue {
+	if x != nil {
+		return x.Active
+	}
+	return nil
+}
+
+func (x *ListEscrowsRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
 	}
 	return ""
 }
 
-func (x *EscrowResponse) GetBlockchainEscrowId() uint32 {
+func (x *ListEscrowsRequest) GetOrder() string {
 	if x != nil {
-		return x.BlockchainEscrowId
+		return x.Order
+	}
+	return ""
+}
+
+func (x *ListEscrowsRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
 	}
 	return 0
 }
 
-func (x *EscrowResponse) GetActive() bool {
+func (x *ListEscrowsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListEscrowsRequest) GetIncludeSummary() bool {
+	if x != nil {
+		return x.IncludeSummary
+	}
+	return false
+}
+
+func (x *ListEscrowsRequest) GetSummaryCurrency() string {
+	if x != nil {
+		return x.SummaryCurrency
+	}
+	return ""
+}
+
+func (x *ListEscrowsRequest) GetMinAmountMinor() *wrapperspb.Int64Value {
+	if x != nil {
+		return x.MinAmountMinor
+	}
+	return nil
+}
+
+func (x *ListEscrowsRequest) GetMaxAmountMinor() *wrapperspb.Int64Value {
+	if x != nil {
+		return x.MaxAmountMinor
+	}
+	return nil
+}
+
+type EscrowSummary struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Total                 int64                  `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	Active                int64                  `protobuf:"varint,2,opt,name=active,proto3" json:"active,omitempty"`
+	Completed             int64                  `protobuf:"varint,3,opt,name=completed,proto3" json:"completed,omitempty"`
+	Disputed              int64                  `protobuf:"varint,4,opt,name=disputed,proto3" json:"disputed,omitempty"`
+	Currency              string                 `protobuf:"bytes,6,opt,name=currency,proto3" json:"currency,omitempty"`
+	MissingRates          []string               `protobuf:"bytes,8,rep,name=missing_rates,json=missingRates,proto3" json:"missing_rates,omitempty"`                                                                                                            // currencies left out of total_amount_minor for lack of a rate
+	TotalAmountMinor      int64                  `protobuf:"varint,9,opt,name=total_amount_minor,json=totalAmountMinor,proto3" json:"total_amount_minor,omitempty"`                                                                                             // in currency, converted with the FX rate table
+	TotalsByCurrencyMinor map[string]int64       `protobuf:"bytes,10,rep,name=totals_by_currency_minor,json=totalsByCurrencyMinor,proto3" json:"totals_by_currency_minor,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // unconverted totals
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *EscrowSummary) Reset() {
+	*x = EscrowSummary{}
+	mi := &file_proto_escrow_v1_escrow_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EscrowSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EscrowSummary) ProtoMessage() {}
+
+func (x *EscrowSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_escrow_v1_escrow_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EscrowSummary.ProtoReflect.Descriptor instead.
+func (*EscrowSummary) Descriptor() ([]byte, []int) {
+	return file_proto_escrow_v1_escrow_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *EscrowSummary) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *EscrowSummary) GetActive() int64 {
 	if x != nil {
 		return x.Active
 	}
+	return 0
+}
+
+func (x *EscrowSummary) GetCompleted() int64 {
+	if x != nil {
+		return x.Completed
+	}
+	return 0
+}
+
+func (x *EscrowSummary) GetDisputed() int64 {
+	if x != nil {
+		return x.Disputed
+	}
+	return 0
+}
+
+func (x *EscrowSummary) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *EscrowSummary) GetMissingRates() []string {
+	if x != nil {
+		return x.MissingRates
+	}
+	return nil
+}
+
+func (x *EscrowSummary) GetTotalAmountMinor() int64 {
+	if x != nil {
+		return x.TotalAmountMinor
+	}
+	return 0
+}
+
+func (x *EscrowSummary) GetTotalsByCurrencyMinor() map[string]int64 {
+	if x != nil {
+		return x.TotalsByCurrencyMinor
+	}
+	return nil
+}
+
+type ListEscrowsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Escrows       []*EscrowResponse      `protobuf:"bytes,1,rep,name=escrows,proto3" json:"escrows,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"` // "" on the last page
+	Summary       *EscrowSummary         `protobuf:"bytes,3,opt,name=summary,proto3" json:"summary,omitempty"`                         // only set when include_summary was requested
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEscrowsResponse) Reset() {
+	*x = ListEscrowsResponse{}
+	mi := &file_proto_escrow_v1_escrow_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEscrowsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEscrowsResponse) ProtoMessage() {}
+
+func (x *ListEscrowsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_escrow_v1_escrow_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEscrowsResponse.ProtoReflect.Descriptor instead.
+func (*ListEscrowsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_escrow_v1_escrow_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListEscrowsResponse) GetEscrows() []*EscrowResponse {
+	if x != nil {
+		return x.Escrows
+	}
+	return nil
+}
+
+func (x *ListEscrowsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+func (x *ListEscrowsResponse) GetSummary() *EscrowSummary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+// StartPaymentRequest is sent by payment-service before it opens a checkout
+// for the amounts it read from GetEscrow. From then on the escrow can no
+// longer be amended. It fails when the escrow is no longer Pending or its
+// amounts changed in the meantime.
+type StartPaymentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EscrowId      uint32                 `protobuf:"varint,1,opt,name=escrow_id,json=escrowId,proto3" json:"escrow_id,omitempty"`
+	BuyerId       uint32                 `protobuf:"varint,2,opt,name=buyer_id,json=buyerId,proto3" json:"buyer_id,omitempty"`
+	AmountMinor   int64                  `protobuf:"varint,5,opt,name=amount_minor,json=amountMinor,proto3" json:"amount_minor,omitempty"`
+	BuyerFeeMinor int64                  `protobuf:"varint,6,opt,name=buyer_fee_minor,json=buyerFeeMinor,proto3" json:"buyer_fee_minor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartPaymentRequest) Reset() {
+	*x = StartPaymentRequest{}
+	mi := &file_proto_escrow_v1_escrow_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartPaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartPaymentRequest) ProtoMessage() {}
+
+func (x *StartPaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_escrow_v1_escrow_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartPaymentRequest.ProtoReflect.Descriptor instead.
+func (*StartPaymentRequest) Descriptor() ([]byte, []int) {
+	return file_proto_escrow_v1_escrow_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *StartPaymentRequest) GetEscrowId() uint32 {
+	if x != nil {
+		return x.EscrowId
+	}
+	return 0
+}
+
+func (x *StartPaymentRequest) GetBuyerId() uint32 {
+	if x != nil {
+		return x.BuyerId
+	}
+	return 0
+}
+
+func (x *StartPaymentRequest) GetAmountMinor() int64 {
+	if x != nil {
+		return x.AmountMinor
+	}
+	return 0
+}
+
+func (x *StartPaymentRequest) GetBuyerFeeMinor() int64 {
+	if x != nil {
+		return x.BuyerFeeMinor
+	}
+	return 0
+}
+
+type StartPaymentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartPaymentResponse) Reset() {
+	*x = StartPaymentResponse{}
+	mi := &file_proto_escrow_v1_escrow_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartPaymentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartPaymentResponse) ProtoMessage() {}
+
+func (x *StartPaymentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_escrow_v1_escrow_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartPaymentResponse.ProtoReflect.Descriptor instead.
+func (*StartPaymentResponse) Descriptor() ([]byte, []int) {
+	return file_proto_escrow_v1_escrow_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *StartPaymentResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
 	return false
 }
 
+func (x *StartPaymentResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
 var File_proto_escrow_v1_escrow_proto protoreflect.FileDescriptor
 
 const file_proto_escrow_v1_escrow_proto_rawDesc = "" +
 	"\n" +
-	"\x1cproto/escrow/v1/escrow.proto\x12\tescrow.v1\"W\n" +
+	"\x1cproto/escrow/v1/escrow.proto\x12\tescrow.v1\x1a\x1egoogle/protobuf/wrappers.proto\"W\n" +
 	"\x19UpdateEscrowStatusRequest\x12\x1b\n" +
 	"\tescrow_id\x18\x01 \x01(\rR\bescrowId\x12\x1d\n" +
 	"\n" +
@@ -282,21 +1102,107 @@ const file_proto_escrow_v1_escrow_proto_rawDesc = "" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\"/\n" +
 	"\x10GetEscrowRequest\x12\x1b\n" +
-	"\tescrow_id\x18\x01 \x01(\rR\bescrowId\"\xf2\x01\n" +
+	"\tescrow_id\x18\x01 \x01(\rR\bescrowId\"\x8f\x04\n" +
 	"\x0eEscrowResponse\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\rR\x02id\x12\x19\n" +
 	"\bbuyer_id\x18\x02 \x01(\rR\abuyerId\x12\x1b\n" +
 	"\tseller_id\x18\x03 \x01(\rR\bsellerId\x12\x16\n" +
-	"\x06amount\x18\x04 \x01(\x02R\x06amount\x12\x16\n" +
 	"\x06status\x18\x05 \x01(\tR\x06status\x12\x1e\n" +
 	"\n" +
 	"conditions\x18\x06 \x01(\tR\n" +
 	"conditions\x120\n" +
 	"\x14blockchain_escrow_id\x18\a \x01(\rR\x12blockchainEscrowId\x12\x16\n" +
-	"\x06active\x18\b \x01(\bR\x06active2\xb1\x01\n" +
+	"\x06active\x18\b \x01(\bR\x06active\x12!\n" +
+	"\finitiated_by\x18\t \x01(\tR\vinitiatedBy\x12%\n" +
+	"\x0eawaiting_buyer\x18\n" +
+	" \x01(\bR\rawaitingBuyer\x12'\n" +
+	"\x0fconditions_json\x18\v \x01(\tR\x0econditionsJson\x12\x1a\n" +
+	"\bcurrency\x18\x0e \x01(\tR\bcurrency\x12!\n" +
+	"\famount_minor\x18\x0f \x01(\x03R\vamountMinor\x12&\n" +
+	"\x0fbuyer_fee_minor\x18\x10 \x01(\x03R\rbuyerFeeMinor\x12(\n" +
+	"\x10seller_fee_minor\x18\x11 \x01(\x03R\x0esellerFeeMinorJ\x04\b\x04\x10\x05J\x04\b\f\x10\rJ\x04\b\r\x10\x0eR\x06amountR\tbuyer_feeR\n" +
+	"seller_fee\"2\n" +
+	"\x13ListEvidenceRequest\x12\x1b\n" +
+	"\tescrow_id\x18\x01 \x01(\rR\bescrowId\"\xae\x02\n" +
+	"\bEvidence\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\rR\x02id\x12\x1d\n" +
+	"\n" +
+	"dispute_id\x18\x02 \x01(\rR\tdisputeId\x12\x1f\n" +
+	"\vuploaded_by\x18\x03 \x01(\rR\n" +
+	"uploadedBy\x12#\n" +
+	"\ruploader_role\x18\x04 \x01(\tR\fuploaderRole\x12\x1b\n" +
+	"\tfile_name\x18\x05 \x01(\tR\bfileName\x12!\n" +
+	"\fcontent_type\x18\x06 \x01(\tR\vcontentType\x12\x12\n" +
+	"\x04size\x18\a \x01(\x03R\x04size\x12\x16\n" +
+	"\x06sha256\x18\b \x01(\tR\x06sha256\x12 \n" +
+	"\vdescription\x18\t \x01(\tR\vdescription\x12\x1f\n" +
+	"\vuploaded_at\x18\n" +
+	" \x01(\x03R\n" +
+	"uploadedAt\"G\n" +
+	"\x14ListEvidenceResponse\x12/\n" +
+	"\bevidence\x18\x01 \x03(\v2\x13.escrow.v1.EvidenceR\bevidence\"|\n" +
+	"\x1bRecordRecommendationRequest\x12\x1b\n" +
+	"\tescrow_id\x18\x01 \x01(\rR\bescrowId\x12\x1a\n" +
+	"\bdecision\x18\x02 \x01(\tR\bdecision\x12$\n" +
+	"\rjustification\x18\x03 \x01(\tR\rjustification\"m\n" +
+	"\x1cRecordRecommendationResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"dispute_id\x18\x03 \x01(\rR\tdisputeId\"\xda\x04\n" +
+	"\x12ListEscrowsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\rR\x06userId\x12\x12\n" +
+	"\x04role\x18\x02 \x01(\tR\x04role\x12\x1a\n" +
+	"\bstatuses\x18\x03 \x03(\tR\bstatuses\x12'\n" +
+	"\x0fcounterparty_id\x18\x04 \x01(\rR\x0ecounterpartyId\x12!\n" +
+	"\fcreated_from\x18\a \x01(\x03R\vcreatedFrom\x12\x1d\n" +
+	"\n" +
+	"created_to\x18\b \x01(\x03R\tcreatedTo\x122\n" +
+	"\x06active\x18\t \x01(\v2\x1a.google.protobuf.BoolValueR\x06active\x12\x12\n" +
+	"\x04sort\x18\n" +
+	" \x01(\tR\x04sort\x12\x14\n" +
+	"\x05order\x18\v \x01(\tR\x05order\x12\x14\n" +
+	"\x05limit\x18\f \x01(\rR\x05limit\x12\x16\n" +
+	"\x06cursor\x18\r \x01(\tR\x06cursor\x12'\n" +
+	"\x0finclude_summary\x18\x0e \x01(\bR\x0eincludeSummary\x12)\n" +
+	"\x10summary_currency\x18\x0f \x01(\tR\x0fsummaryCurrency\x12E\n" +
+	"\x10min_amount_minor\x18\x10 \x01(\v2\x1b.google.protobuf.Int64ValueR\x0eminAmountMinor\x12E\n" +
+	"\x10max_amount_minor\x18\x11 \x01(\v2\x1b.google.protobuf.Int64ValueR\x0emaxAmountMinorJ\x04\b\x05\x10\x06J\x04\b\x06\x10\aR\n" +
+	"min_amountR\n" +
+	"max_amount\"\xcc\x03\n" +
+	"\rEscrowSummary\x12\x14\n" +
+	"\x05total\x18\x01 \x01(\x03R\x05total\x12\x16\n" +
+	"\x06active\x18\x02 \x01(\x03R\x06active\x12\x1c\n" +
+	"\tcompleted\x18\x03 \x01(\x03R\tcompleted\x12\x1a\n" +
+	"\bdisputed\x18\x04 \x01(\x03R\bdisputed\x12\x1a\n" +
+	"\bcurrency\x18\x06 \x01(\tR\bcurrency\x12#\n" +
+	"\rmissing_rates\x18\b \x03(\tR\fmissingRates\x12,\n" +
+	"\x12total_amount_minor\x18\t \x01(\x03R\x10totalAmountMinor\x12l\n" +
+	"\x18totals_by_currency_minor\x18\n" +
+	" \x03(\v23.escrow.v1.EscrowSummary.TotalsByCurrencyMinorEntryR\x15totalsByCurrencyMinor\x1aH\n" +
+	"\x1aTotalsByCurrencyMinorEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01J\x04\b\x05\x10\x06J\x04\b\a\x10\bR\ftotal_amountR\x12totals_by_currency\"\x9f\x01\n" +
+	"\x13ListEscrowsResponse\x123\n" +
+	"\aescrows\x18\x01 \x03(\v2\x19.escrow.v1.EscrowResponseR\aescrows\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\x122\n" +
+	"\asummary\x18\x03 \x01(\v2\x18.escrow.v1.EscrowSummaryR\asummary\"\xb7\x01\n" +
+	"\x13StartPaymentRequest\x12\x1b\n" +
+	"\tescrow_id\x18\x01 \x01(\rR\bescrowId\x12\x19\n" +
+	"\bbuyer_id\x18\x02 \x01(\rR\abuyerId\x12!\n" +
+	"\famount_minor\x18\x05 \x01(\x03R\vamountMinor\x12&\n" +
+	"\x0fbuyer_fee_minor\x18\x06 \x01(\x03R\rbuyerFeeMinorJ\x04\b\x03\x10\x04J\x04\b\x04\x10\x05R\x06amountR\tbuyer_fee\"F\n" +
+	"\x14StartPaymentResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error2\x8a\x04\n" +
 	"\rEscrowService\x12[\n" +
 	"\fUpdateStatus\x12$.escrow.v1.UpdateEscrowStatusRequest\x1a%.escrow.v1.UpdateEscrowStatusResponse\x12C\n" +
-	"\tGetEscrow\x12\x1b.escrow.v1.GetEscrowRequest\x1a\x19.escrow.v1.EscrowResponseB\x13Z\x11./proto/escrow/v1b\x06proto3"
+	"\tGetEscrow\x12\x1b.escrow.v1.GetEscrowRequest\x1a\x19.escrow.v1.EscrowResponse\x12O\n" +
+	"\fListEvidence\x12\x1e.escrow.v1.ListEvidenceRequest\x1a\x1f.escrow.v1.ListEvidenceResponse\x12g\n" +
+	"\x14RecordRecommendation\x12&.escrow.v1.RecordRecommendationRequest\x1a'.escrow.v1.RecordRecommendationResponse\x12L\n" +
+	"\vListEscrows\x12\x1d.escrow.v1.ListEscrowsRequest\x1a\x1e.escrow.v1.ListEscrowsResponse\x12O\n" +
+	"\fStartPayment\x12\x1e.escrow.v1.StartPaymentRequest\x1a\x1f.escrow.v1.StartPaymentResponseB\x13Z\x11./proto/escrow/v1b\x06proto3"
 
 var (
 	file_proto_escrow_v1_escrow_proto_rawDescOnce sync.Once
@@ -310,23 +1216,51 @@ func file_proto_escrow_v1_escrow_proto_rawDescGZIP() []byte {
 	return file_proto_escrow_v1_escrow_proto_rawDescData
 }
 
-var file_proto_escrow_v1_escrow_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_proto_escrow_v1_escrow_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
 var file_proto_escrow_v1_escrow_proto_goTypes = []any{
-	(*UpdateEscrowStatusRequest)(nil),  // 0: escrow.v1.UpdateEscrowStatusRequest
-	(*UpdateEscrowStatusResponse)(nil), // 1: escrow.v1.UpdateEscrowStatusResponse
-	(*GetEscrowRequest)(nil),           // 2: escrow.v1.GetEscrowRequest
-	(*EscrowResponse)(nil),             // 3: escrow.v1.EscrowResponse
+	(*UpdateEscrowStatusRequest)(nil),    // 0: escrow.v1.UpdateEscrowStatusRequest
+	(*UpdateEscrowStatusResponse)(nil),   // 1: escrow.v1.UpdateEscrowStatusResponse
+	(*GetEscrowRequest)(nil),             // 2: escrow.v1.GetEscrowRequest
+	(*EscrowResponse)(nil),               // 3: escrow.v1.EscrowResponse
+	(*ListEvidenceRequest)(nil),          // 4: escrow.v1.ListEvidenceRequest
+	(*Evidence)(nil),                     // 5: escrow.v1.Evidence
+	(*ListEvidenceResponse)(nil),         // 6: escrow.v1.ListEvidenceResponse
+	(*RecordRecommendationRequest)(nil),  // 7: escrow.v1.RecordRecommendationRequest
+	(*RecordRecommendationResponse)(nil), // 8: escrow.v1.RecordRecommendationResponse
+	(*ListEscrowsRequest)(nil),           // 9: escrow.v1.ListEscrowsRequest
+	(*EscrowSummary)(nil),                // 10: escrow.v1.EscrowSummary
+	(*ListEscrowsResponse)(nil),          // 11: escrow.v1.ListEscrowsResponse
+	(*StartPaymentRequest)(nil),          // 12: escrow.v1.StartPaymentRequest
+	(*StartPaymentResponse)(nil),         // 13: escrow.v1.StartPaymentResponse
+	nil,                                  // 14: escrow.v1.EscrowSummary.TotalsByCurrencyMinorEntry
+	(*wrapperspb.BoolValue)(nil),         // 15: google.protobuf.BoolValue
+	(*wrapperspb.Int64Value)(nil),        // 16: google.protobuf.Int64Value
 }
 var file_proto_escrow_v1_escrow_proto_depIdxs = []int32{
-	0, // 0: escrow.v1.EscrowService.UpdateStatus:input_type -> escrow.v1.UpdateEscrowStatusRequest
-	2, // 1: escrow.v1.EscrowService.GetEscrow:input_type -> escrow.v1.GetEscrowRequest
-	1, // 2: escrow.v1.EscrowService.UpdateStatus:output_type -> escrow.v1.UpdateEscrowStatusResponse
-	3, // 3: escrow.v1.EscrowService.GetEscrow:output_type -> escrow.v1.EscrowResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	5,  // 0: escrow.v1.ListEvidenceResponse.evidence:type_name -> escrow.v1.Evidence
+	15, // 1: escrow.v1.ListEscrowsRequest.active:type_name -> google.protobuf.BoolValue
+	16, // 2: escrow.v1.ListEscrowsRequest.min_amount_minor:type_name -> google.protobuf.Int64Value
+	16, // 3: escrow.v1.ListEscrowsRequest.max_amount_minor:type_name -> google.protobuf.Int64Value
+	14, // 4: escrow.v1.EscrowSummary.totals_by_currency_minor:type_name -> escrow.v1.EscrowSummary.TotalsByCurrencyMinorEntry
+	3,  // 5: escrow.v1.ListEscrowsResponse.escrows:type_name -> escrow.v1.EscrowResponse
+	10, // 6: escrow.v1.ListEscrowsResponse.summary:type_name -> escrow.v1.EscrowSummary
+	0,  // 7: escrow.v1.EscrowService.UpdateStatus:input_type -> escrow.v1.UpdateEscrowStatusRequest
+	2,  // 8: escrow.v1.EscrowService.GetEscrow:input_type -> escrow.v1.GetEscrowRequest
+	4,  // 9: escrow.v1.EscrowService.ListEvidence:input_type -> escrow.v1.ListEvidenceRequest
+	7,  // 10: escrow.v1.EscrowService.RecordRecommendation:input_type -> escrow.v1.RecordRecommendationRequest
+	9,  // 11: escrow.v1.EscrowService.ListEscrows:input_type -> escrow.v1.ListEscrowsRequest
+	12, // 12: escrow.v1.EscrowService.StartPayment:input_type -> escrow.v1.StartPaymentRequest
+	1,  // 13: escrow.v1.EscrowService.UpdateStatus:output_type -> escrow.v1.UpdateEscrowStatusResponse
+	3,  // 14: escrow.v1.EscrowService.GetEscrow:output_type -> escrow.v1.EscrowResponse
+	6,  // 15: escrow.v1.EscrowService.ListEvidence:output_type -> escrow.v1.ListEvidenceResponse
+	8,  // 16: escrow.v1.EscrowService.RecordRecommendation:output_type -> escrow.v1.RecordRecommendationResponse
+	11, // 17: escrow.v1.EscrowService.ListEscrows:output_type -> escrow.v1.ListEscrowsResponse
+	13, // 18: escrow.v1.EscrowService.StartPayment:output_type -> escrow.v1.StartPaymentResponse
+	13, // [13:19] is the sub-list for method output_type
+	7,  // [7:13] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_proto_escrow_v1_escrow_proto_init() }
@@ -340,7 +1274,7 @@ func file_proto_escrow_v1_escrow_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_escrow_v1_escrow_proto_rawDesc), len(file_proto_escrow_v1_escrow_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   15,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
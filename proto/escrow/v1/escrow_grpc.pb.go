@@ -19,8 +19,12 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	EscrowService_UpdateStatus_FullMethodName = "/escrow.v1.EscrowService/UpdateStatus"
-	EscrowService_GetEscrow_FullMethodName    = "/escrow.v1.EscrowService/GetEscrow"
+	EscrowService_UpdateStatus_FullMethodName         = "/escrow.v1.EscrowService/UpdateStatus"
+	EscrowService_GetEscrow_FullMethodName            = "/escrow.v1.EscrowService/GetEscrow"
+	EscrowService_ListEvidence_FullMethodName         = "/escrow.v1.EscrowService/ListEvidence"
+	EscrowService_RecordRecommendation_FullMethodName = "/escrow.v1.EscrowService/RecordRecommendation"
+	EscrowService_ListEscrows_FullMethodName          = "/escrow.v1.EscrowService/ListEscrows"
+	EscrowService_StartPayment_FullMethodName         = "/escrow.v1.EscrowService/StartPayment"
 )
 
 // EscrowServiceClient is the client API for EscrowService service.
@@ -29,6 +33,10 @@ const (
 type EscrowServiceClient interface {
 	UpdateStatus(ctx context.Context, in *UpdateEscrowStatusRequest, opts ...grpc.CallOption) (*UpdateEscrowStatusResponse, error)
 	GetEscrow(ctx context.Context, in *GetEscrowRequest, opts ...grpc.CallOption) (*EscrowResponse, error)
+	ListEvidence(ctx context.Context, in *ListEvidenceRequest, opts ...grpc.CallOption) (*ListEvidenceResponse, error)
+	RecordRecommendation(ctx context.Context, in *RecordRecommendationRequest, opts ...grpc.CallOption) (*RecordRecommendationResponse, error)
+	ListEscrows(ctx context.Context, in *ListEscrowsRequest, opts ...grpc.CallOption) (*ListEscrowsResponse, error)
+	StartPayment(ctx context.Context, in *StartPaymentRequest, opts ...grpc.CallOption) (*StartPaymentResponse, error)
 }
 
 type escrowServiceClient struct {
@@ -59,12 +67,56 @@ func (c *escrowServiceClient) GetEscrow(ctx context.Context, in *GetEscrowReques
 	return out, nil
 }
 
+func (c *escrowServiceClient) ListEvidence(ctx context.Context, in *ListEvidenceRequest, opts ...grpc.CallOption) (*ListEvidenceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListEvidenceResponse)
+	err := c.cc.Invoke(ctx, EscrowService_ListEvidence_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *escrowServiceClient) RecordRecommendation(ctx context.Context, in *RecordRecommendationRequest, opts ...grpc.CallOption) (*RecordRecommendationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RecordRecommendationResponse)
+	err := c.cc.Invoke(ctx, EscrowService_RecordRecommendation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *escrowServiceClient) ListEscrows(ctx context.Context, in *ListEscrowsRequest, opts ...grpc.CallOption) (*ListEscrowsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListEscrowsResponse)
+	err := c.cc.Invoke(ctx, EscrowService_ListEscrows_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *escrowServiceClient) StartPayment(ctx context.Context, in *StartPaymentRequest, opts ...grpc.CallOption) (*StartPaymentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StartPaymentResponse)
+	err := c.cc.Invoke(ctx, EscrowService_StartPayment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // EscrowServiceServer is the server API for EscrowService service.
 // All implementations must embed UnimplementedEscrowServiceServer
 // for forward compatibility.
 type EscrowServiceServer interface {
 	UpdateStatus(context.Context, *UpdateEscrowStatusRequest) (*UpdateEscrowStatusResponse, error)
 	GetEscrow(context.Context, *GetEscrowRequest) (*EscrowResponse, error)
+	ListEvidence(context.Context, *ListEvidenceRequest) (*ListEvidenceResponse, error)
+	RecordRecommendation(context.Context, *RecordRecommendationRequest) (*RecordRecommendationResponse, error)
+	ListEscrows(context.Context, *ListEscrowsRequest) (*ListEscrowsResponse, error)
+	StartPayment(context.Context, *StartPaymentRequest) (*StartPaymentResponse, error)
 	mustEmbedUnimplementedEscrowServiceServer()
 }
 
@@ -81,6 +133,18 @@ func (UnimplementedEscrowServiceServer) UpdateStatus(context.Context, *UpdateEsc
 func (UnimplementedEscrowServiceServer) GetEscrow(context.Context, *GetEscrowRequest) (*EscrowResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetEscrow not implemented")
 }
+func (UnimplementedEscrowServiceServer) ListEvidence(context.Context, *ListEvidenceRequest) (*ListEvidenceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEvidence not implemented")
+}
+func (UnimplementedEscrowServiceServer) RecordRecommendation(context.Context, *RecordRecommendationRequest) (*RecordRecommendationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecordRecommendation not implemented")
+}
+func (UnimplementedEscrowServiceServer) ListEscrows(context.Context, *ListEscrowsRequest) (*ListEscrowsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEscrows not implemented")
+}
+func (UnimplementedEscrowServiceServer) StartPayment(context.Context, *StartPaymentRequest) (*StartPaymentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartPayment not implemented")
+}
 func (UnimplementedEscrowServiceServer) mustEmbedUnimplementedEscrowServiceServer() {}
 func (UnimplementedEscrowServiceServer) testEmbeddedByValue()                       {}
 
@@ -138,6 +202,78 @@ func _EscrowService_GetEscrow_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EscrowService_ListEvidence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEvidenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EscrowServiceServer).ListEvidence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EscrowService_ListEvidence_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EscrowServiceServer).ListEvidence(ctx, req.(*ListEvidenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EscrowService_RecordRecommendation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecordRecommendationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EscrowServiceServer).RecordRecommendation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EscrowService_RecordRecommendation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EscrowServiceServer).RecordRecommendation(ctx, req.(*RecordRecommendationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EscrowService_ListEscrows_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEscrowsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EscrowServiceServer).ListEscrows(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EscrowService_ListEscrows_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EscrowServiceServer).ListEscrows(ctx, req.(*ListEscrowsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EscrowService_StartPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartPaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EscrowServiceServer).StartPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EscrowService_StartPayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EscrowServiceServer).StartPayment(ctx, req.(*StartPaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // EscrowService_ServiceDesc is the grpc.ServiceDesc for EscrowService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -153,6 +289,22 @@ var EscrowService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetEscrow",
 			Handler:    _EscrowService_GetEscrow_Handler,
 		},
+		{
+			MethodName: "ListEvidence",
+			Handler:    _EscrowService_ListEvidence_Handler,
+		},
+		{
+			MethodName: "RecordRecommendation",
+			Handler:    _EscrowService_RecordRecommendation_Handler,
+		},
+		{
+			MethodName: "ListEscrows",
+			Handler:    _EscrowService_ListEscrows_Handler,
+		},
+		{
+			MethodName: "StartPayment",
+			Handler:    _EscrowService_StartPayment_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/escrow/v1/escrow.proto",
@@ -24,9 +24,9 @@ const (
 type InitiateEscrowPaymentRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	EscrowId      uint32                 `protobuf:"varint,1,opt,name=escrow_id,json=escrowId,proto3" json:"escrow_id,omitempty"`
-	Amount        float32                `protobuf:"fixed32,2,opt,name=amount,proto3" json:"amount,omitempty"`
 	Currency      string                 `protobuf:"bytes,3,opt,name=currency,proto3" json:"currency,omitempty"`
 	Email         string                 `protobuf:"bytes,4,opt,name=email,proto3" json:"email,omitempty"`
+	AmountMinor   int64                  `protobuf:"varint,5,opt,name=amount_minor,json=amountMinor,proto3" json:"amount_minor,omitempty"` // in minor units of currency
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -68,13 +68,6 @@ func (x *InitiateEscrowPaymentRequest) GetEscrowId() uint32 {
 	return 0
 }
 
-func (x *InitiateEscrowPaymentRequest) GetAmount() float32 {
-	if x != nil {
-		return x.Amount
-	}
-	return 0
-}
-
 func (x *InitiateEscrowPaymentRequest) GetCurrency() string {
 	if x != nil {
 		return x.Currency
@@ -89,6 +82,13 @@ func (x *InitiateEscrowPaymentRequest) GetEmail() string {
 	return ""
 }
 
+func (x *InitiateEscrowPaymentRequest) GetAmountMinor() int64 {
+	if x != nil {
+		return x.AmountMinor
+	}
+	return 0
+}
+
 type InitiateEscrowPaymentResponse struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
 	PaymentUrl     string                 `protobuf:"bytes,1,opt,name=payment_url,json=paymentUrl,proto3" json:"payment_url,omitempty"`
@@ -330,12 +330,12 @@ var File_proto_payment_v1_payment_proto protoreflect.FileDescriptor
 const file_proto_payment_v1_payment_proto_rawDesc = "" +
 	"\n" +
 	"\x1eproto/payment/v1/payment.proto\x12\n" +
-	"payment.v1\"\x85\x01\n" +
+	"payment.v1\"\x9e\x01\n" +
 	"\x1cInitiateEscrowPaymentRequest\x12\x1b\n" +
-	"\tescrow_id\x18\x01 \x01(\rR\bescrowId\x12\x16\n" +
-	"\x06amount\x18\x02 \x01(\x02R\x06amount\x12\x1a\n" +
+	"\tescrow_id\x18\x01 \x01(\rR\bescrowId\x12\x1a\n" +
 	"\bcurrency\x18\x03 \x01(\tR\bcurrency\x12\x14\n" +
-	"\x05email\x18\x04 \x01(\tR\x05email\"i\n" +
+	"\x05email\x18\x04 \x01(\tR\x05email\x12!\n" +
+	"\famount_minor\x18\x05 \x01(\x03R\vamountMinorJ\x04\b\x02\x10\x03R\x06amount\"i\n" +
 	"\x1dInitiateEscrowPaymentResponse\x12\x1f\n" +
 	"\vpayment_url\x18\x01 \x01(\tR\n" +
 	"paymentUrl\x12'\n" +
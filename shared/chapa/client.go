@@ -8,6 +8,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"shared/money"
 )
 
 type Client struct {
@@ -45,7 +46,8 @@ type TransferResponse struct {
 
 func (c *Client) TransferToSeller(
 	sellerID uint,
-	amount float64,
+	amount money.Amount,
+	currency string,
 	reference string,
 	accountName, accountNumber string,
 	bankCode int,
@@ -54,13 +56,13 @@ func (c *Client) TransferToSeller(
 	method := "POST"
 
 	
-	amountStr := fmt.Sprintf("%.2f", amount)
+	amountStr := amount.String()
 
 	payload := TransferRequest{
 		AccountName:   accountName,
 		AccountNumber: accountNumber,
 		Amount:        amountStr,
-		Currency:      "ETB",
+		Currency:      currency,
 		Reference:     reference,
 		BankCode:      bankCode,
 		CallbackURL:   "https://evolved-bonefish-hardly.ngrok-free.app/webhook/transfer",
@@ -0,0 +1,232 @@
+// Package idempotency lets clients retry state-changing requests safely.
+// A request carrying an Idempotency-Key header is executed once; retries
+// with the same key and the same request get the stored response back, and
+// reusing the key for a different request is rejected with 409 Conflict.
+// Only successful responses are stored: a rejected request changed nothing,
+// so a retry runs it again and may succeed once the conflict is resolved.
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// Header carries the client-chosen key, typically a UUID.
+	Header = "Idempotency-Key"
+	// ReplayedHeader is set on responses served from the store.
+	ReplayedHeader = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+	// TTL is how long a stored response is replayed.
+	TTL = 24 * time.Hour
+	// lockTimeout is how long a request may hold a key before a retry is
+	// allowed to take it over, e.g. after the replica handling it crashed.
+	lockTimeout = time.Minute
+)
+
+// Key is a stored request and, once it finished, its response. Keys are
+// scoped to the caller, so two users can pick the same key.
+type Key struct {
+	ID          uint      `gorm:"primarykey"`
+	CreatedAt   time.Time `gorm:"not null"`
+	UserID      string    `gorm:"type:varchar(32);not null;uniqueIndex:idx_idempotency_user_key"`
+	Key         string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotency_user_key"`
+	Fingerprint string    `gorm:"type:varchar(64);not null"`
+	// StatusCode is 0 while the request is still being handled.
+	StatusCode  int    `gorm:"not null;default:0"`
+	ContentType string `gorm:"type:varchar(128)"`
+	Body        []byte
+	ExpiresAt   time.Time `gorm:"not null;index"`
+}
+
+func (Key) TableName() string {
+	return "idempotency_keys"
+}
+
+// New returns the middleware. Requests without the header, and safe
+// methods, pass through untouched.
+func New(db *gorm.DB) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		key := c.Get(Header)
+		if key == "" || !changesState(c.Method()) {
+			return c.Next()
+		}
+		if len(key) > maxKeyLength {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Idempotency-Key must be at most 255 characters",
+			})
+		}
+
+		fp := fingerprint(c)
+		record, owned, err := claim(db, c.Get("X-User-ID"), key, fp)
+		if err != nil {
+			log.Printf("Idempotency key lookup failed: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to check Idempotency-Key",
+			})
+		}
+		if !owned {
+			return replay(c, record, fp)
+		}
+		return run(c, db, record)
+	}
+}
+
+func changesState(method string) bool {
+	switch method {
+	case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// fingerprint identifies the request a key was first used with: the same
+// key sent to another endpoint or with another body is a different request.
+func fingerprint(c fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(c.Method()))
+	h.Write([]byte{0})
+	h.Write([]byte(c.OriginalURL()))
+	h.Write([]byte{0})
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// claim stores the key for this request. owned reports whether the caller
+// now handles the request; otherwise record is the earlier request with
+// the same key.
+func claim(db *gorm.DB, userID, key, fp string) (record *Key, owned bool, err error) {
+	// A key released by a failed request between our insert and our read
+	// is simply claimed again.
+	for attempt := 0; attempt < 3; attempt++ {
+		record, owned, err = tryClaim(db, userID, key, fp)
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return record, owned, err
+		}
+	}
+	return nil, false, err
+}
+
+func tryClaim(db *gorm.DB, userID, key, fp string) (*Key, bool, error) {
+	now := time.Now()
+	record := &Key{
+		CreatedAt:   now,
+		UserID:      userID,
+		Key:         key,
+		Fingerprint: fp,
+		ExpiresAt:   now.Add(TTL),
+	}
+	res := db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if res.Error != nil {
+		return nil, false, res.Error
+	}
+	if res.RowsAffected == 1 {
+		return record, true, nil
+	}
+
+	var existing Key
+	if err := db.Where("user_id = ? AND key = ?", userID, key).First(&existing).Error; err != nil {
+		return nil, false, err
+	}
+
+	switch {
+	case existing.ExpiresAt.Before(now):
+		// The old response is no longer replayed: the key starts over.
+		res = db.Model(&Key{}).
+			Where("id = ? AND expires_at < ?", existing.ID, now).
+			Updates(map[string]any{
+				"created_at":   now,
+				"fingerprint":  fp,
+				"status_code":  0,
+				"content_type": "",
+				"body":         nil,
+				"expires_at":   record.ExpiresAt,
+			})
+	case existing.StatusCode == 0 && existing.Fingerprint == fp &&
+		existing.CreatedAt.Before(now.Add(-lockTimeout)):
+		// The request that claimed the key never finished.
+		res = db.Model(&Key{}).
+			Where("id = ? AND status_code = 0 AND created_at = ?", existing.ID, existing.CreatedAt).
+			Update("created_at", now)
+	default:
+		return &existing, false, nil
+	}
+	if res.Error != nil {
+		return nil, false, res.Error
+	}
+	if res.RowsAffected == 0 {
+		// Another retry took the key over first.
+		return &existing, false, nil
+	}
+	existing.CreatedAt = now
+	existing.Fingerprint = fp
+	return &existing, true, nil
+}
+
+// replay answers a retry with the stored response.
+func replay(c fiber.Ctx, record *Key, fp string) error {
+	if record.Fingerprint != fp {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Idempotency-Key was already used for a different request",
+		})
+	}
+	if record.StatusCode == 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "A request with this Idempotency-Key is still being processed",
+		})
+	}
+	c.Set(ReplayedHeader, "true")
+	if record.ContentType != "" {
+		c.Set(fiber.HeaderContentType, record.ContentType)
+	}
+	return c.Status(record.StatusCode).Send(record.Body)
+}
+
+// run handles the request and stores its response. Error responses are not
+// stored: the key is released so the client can retry, e.g. a payment that
+// was refused with 409 while the escrow still awaited the buyer.
+func run(c fiber.Ctx, db *gorm.DB, record *Key) error {
+	err := c.Next()
+	status := c.Response().StatusCode()
+	if err != nil || status >= fiber.StatusBadRequest {
+		if dbErr := db.Delete(&Key{}, record.ID).Error; dbErr != nil {
+			log.Printf("Failed to release idempotency key %d: %v", record.ID, dbErr)
+		}
+		return err
+	}
+
+	body := append([]byte(nil), c.Response().Body()...)
+	if dbErr := db.Model(&Key{}).Where("id = ?", record.ID).Updates(map[string]any{
+		"status_code":  status,
+		"content_type": string(c.Response().Header.ContentType()),
+		"body":         body,
+	}).Error; dbErr != nil {
+		log.Printf("Failed to store response for idempotency key %d: %v", record.ID, dbErr)
+	}
+	return nil
+}
+
+// Purge deletes expired keys.
+func Purge(db *gorm.DB) (int64, error) {
+	res := db.Where("expires_at < ?", time.Now()).Delete(&Key{})
+	return res.RowsAffected, res.Error
+}
+
+// RunPurger purges expired keys every interval until the process exits.
+func RunPurger(db *gorm.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if _, err := Purge(db); err != nil {
+			log.Printf("Failed to purge idempotency keys: %v", err)
+		}
+	}
+}
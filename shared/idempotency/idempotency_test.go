@@ -0,0 +1,183 @@
+package idempotency
+
+import (
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testApp serves POST /orders behind the middleware. The handler counts
+// its calls and answers with status, or 201 when status is 0.
+type testApp struct {
+	app    *fiber.App
+	db     *gorm.DB
+	calls  int
+	status int
+}
+
+func newTestApp(t *testing.T) *testApp {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&Key{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	ta := &testApp{app: fiber.New(), db: db}
+	ta.app.Use(New(db))
+	ta.app.Post("/orders", func(c fiber.Ctx) error {
+		ta.calls++
+		status := ta.status
+		if status == 0 {
+			status = fiber.StatusCreated
+		}
+		return c.Status(status).JSON(fiber.Map{"order": ta.calls})
+	})
+	return ta
+}
+
+type response struct {
+	status   int
+	body     string
+	replayed bool
+}
+
+func (ta *testApp) post(t *testing.T, key, body string) response {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	req.Header.Set("X-User-ID", "7")
+	if key != "" {
+		req.Header.Set(Header, key)
+	}
+	resp, err := ta.app.Test(req)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	b, _ := io.ReadAll(resp.Body)
+	return response{
+		status:   resp.StatusCode,
+		body:     string(b),
+		replayed: resp.Header.Get(ReplayedHeader) == "true",
+	}
+}
+
+func TestReplay(t *testing.T) {
+	ta := newTestApp(t)
+
+	first := ta.post(t, "k1", `{"amount":"10.00"}`)
+	if first.status != fiber.StatusCreated || first.replayed {
+		t.Fatalf("first request = %d (replayed %v), want 201", first.status, first.replayed)
+	}
+
+	retry := ta.post(t, "k1", `{"amount":"10.00"}`)
+	if retry.status != first.status || retry.body != first.body {
+		t.Errorf("retry = %d %s, want the stored %d %s", retry.status, retry.body, first.status, first.body)
+	}
+	if !retry.replayed {
+		t.Errorf("retry is missing the %s header", ReplayedHeader)
+	}
+	if ta.calls != 1 {
+		t.Errorf("handler ran %d times, want 1", ta.calls)
+	}
+
+	// Without a key, or with another one, the request runs again.
+	ta.post(t, "", `{"amount":"10.00"}`)
+	ta.post(t, "k2", `{"amount":"10.00"}`)
+	if ta.calls != 3 {
+		t.Errorf("handler ran %d times, want 3", ta.calls)
+	}
+}
+
+func TestKeyReusedForDifferentBody(t *testing.T) {
+	ta := newTestApp(t)
+
+	ta.post(t, "k1", `{"amount":"10.00"}`)
+	resp := ta.post(t, "k1", `{"amount":"99.00"}`)
+	if resp.status != fiber.StatusConflict {
+		t.Errorf("reused key = %d, want 409", resp.status)
+	}
+	if resp.replayed {
+		t.Error("a different request got the stored response")
+	}
+	if ta.calls != 1 {
+		t.Errorf("handler ran %d times, want 1", ta.calls)
+	}
+}
+
+func TestInFlightConflict(t *testing.T) {
+	ta := newTestApp(t)
+	body := `{"amount":"10.00"}`
+
+	// Claim the key as a request that is still being handled would.
+	record, owned, err := claim(ta.db, "7", "k1", fingerprintOf(t, body))
+	if err != nil || !owned {
+		t.Fatalf("claim() = %v, %v", owned, err)
+	}
+
+	resp := ta.post(t, "k1", body)
+	if resp.status != fiber.StatusConflict {
+		t.Errorf("retry while in flight = %d, want 409", resp.status)
+	}
+	if ta.calls != 0 {
+		t.Errorf("handler ran %d times, want 0", ta.calls)
+	}
+
+	// Once the lock times out, e.g. because the replica crashed, a retry
+	// takes the key over and runs the request.
+	ta.db.Model(&Key{}).Where("id = ?", record.ID).Update("created_at", time.Now().Add(-2*lockTimeout))
+	resp = ta.post(t, "k1", body)
+	if resp.status != fiber.StatusCreated || ta.calls != 1 {
+		t.Errorf("retry after the lock timeout = %d with %d calls, want 201 with 1", resp.status, ta.calls)
+	}
+}
+
+func TestErrorReleasesKey(t *testing.T) {
+	for _, status := range []int{fiber.StatusBadRequest, fiber.StatusConflict, fiber.StatusInternalServerError} {
+		t.Run(strconv.Itoa(status), func(t *testing.T) {
+			ta := newTestApp(t)
+
+			ta.status = status
+			if resp := ta.post(t, "k1", `{}`); resp.status != status {
+				t.Fatalf("first request = %d, want %d", resp.status, status)
+			}
+
+			ta.status = 0
+			resp := ta.post(t, "k1", `{}`)
+			if resp.status != fiber.StatusCreated || resp.replayed {
+				t.Errorf("retry after a %d = %d (replayed %v), want a fresh 201", status, resp.status, resp.replayed)
+			}
+			if ta.calls != 2 {
+				t.Errorf("handler ran %d times, want 2", ta.calls)
+			}
+		})
+	}
+}
+
+// fingerprintOf returns the fingerprint the middleware computes for a POST
+// of body to /orders.
+func fingerprintOf(t *testing.T, body string) string {
+	t.Helper()
+	var fp string
+	app := fiber.New()
+	app.Post("/orders", func(c fiber.Ctx) error {
+		fp = fingerprint(c)
+		return nil
+	})
+	if _, err := app.Test(httptest.NewRequest(fiber.MethodPost, "/orders", strings.NewReader(body))); err != nil {
+		t.Fatalf("fingerprint request: %v", err)
+	}
+	return fp
+}
@@ -0,0 +1,231 @@
+// Package money represents amounts of money exactly, as an integer number
+// of minor units (cents, santim). Every service stores, sends and computes
+// with Amount instead of floats so amounts never pick up rounding errors.
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of minor units in one major unit. Every currency the
+// platform supports (ETB, USD) has two decimal places.
+const Scale = 100
+
+// Amount is an amount of money in minor units of its currency. The
+// currency travels separately.
+type Amount int64
+
+var (
+	ErrInvalid   = errors.New("invalid amount")
+	ErrPrecision = errors.New("amount has more than two decimal places")
+	ErrOverflow  = errors.New("amount is out of range")
+)
+
+// FromMinor returns the amount of m minor units.
+func FromMinor(m int64) Amount {
+	return Amount(m)
+}
+
+// FromFloat rounds f to the nearest minor unit. It is only meant for
+// legacy inputs that are still floats; new code should Parse text.
+func FromFloat(f float64) Amount {
+	return Amount(math.Round(f * Scale))
+}
+
+// Parse reads a decimal amount such as "1250", "1250.5" or "-0.25"
+// exactly. More than two decimal places is an error rather than being
+// rounded away.
+func Parse(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	neg := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		neg, s = true, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" {
+		return 0, ErrInvalid
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	for _, r := range whole + frac {
+		if r < '0' || r > '9' {
+			return 0, ErrInvalid
+		}
+	}
+	if len(frac) > 2 {
+		if strings.TrimRight(frac[2:], "0") != "" {
+			return 0, ErrPrecision
+		}
+		frac = frac[:2]
+	}
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || units > math.MaxInt64/Scale-1 {
+		return 0, ErrOverflow
+	}
+	cents, _ := strconv.ParseInt(frac, 10, 64)
+	m := units*Scale + cents
+	if neg {
+		m = -m
+	}
+	return Amount(m), nil
+}
+
+// Minor returns the amount in minor units, the representation used on the
+// wire in protos and on chain.
+func (a Amount) Minor() int64 {
+	return int64(a)
+}
+
+// BigInt returns the amount in minor units for contract calls.
+func (a Amount) BigInt() *big.Int {
+	return big.NewInt(int64(a))
+}
+
+// Float64 converts the amount for display and FX estimates only.
+func (a Amount) Float64() float64 {
+	return float64(a) / Scale
+}
+
+// String formats the amount with exactly two decimals, e.g. "1250.50".
+func (a Amount) String() string {
+	m := int64(a)
+	sign := ""
+	if m < 0 {
+		sign, m = "-", -m
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, m/Scale, m%Scale)
+}
+
+func (a Amount) IsZero() bool     { return a == 0 }
+func (a Amount) IsPositive() bool { return a > 0 }
+func (a Amount) IsNegative() bool { return a < 0 }
+
+// MulDiv returns a*num/den rounded half away from zero. It is exact for
+// any amount, which makes it the building block for pro rata shares.
+func (a Amount) MulDiv(num, den int64) Amount {
+	if den == 0 {
+		panic("money: division by zero")
+	}
+	return roundRat(new(big.Rat).SetFrac(
+		new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(num)),
+		big.NewInt(den),
+	))
+}
+
+// Percent returns p percent of the amount, rounded half away from zero to
+// the minor unit.
+func (a Amount) Percent(p float64) Amount {
+	return a.mul(p, 100)
+}
+
+// Convert multiplies the amount by an FX rate, rounded to the minor unit.
+func (a Amount) Convert(rate float64) Amount {
+	return a.mul(rate, 1)
+}
+
+// mul returns a*f/den. f is taken at its exact binary value, so the only
+// rounding is the final one to the minor unit.
+func (a Amount) mul(f float64, den int64) Amount {
+	r := new(big.Rat).SetFloat64(f)
+	if r == nil {
+		return 0
+	}
+	r.Mul(r, new(big.Rat).SetInt64(int64(a)))
+	return roundRat(r.Quo(r, big.NewRat(den, 1)))
+}
+
+func roundRat(r *big.Rat) Amount {
+	num := new(big.Int).Abs(r.Num())
+	q, rem := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	if rem.Mul(rem, big.NewInt(2)).Cmp(r.Denom()) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		q.Neg(q)
+	}
+	return Amount(q.Int64())
+}
+
+// MarshalJSON writes the amount as a JSON number with two decimals, so
+// clients that read amounts as numbers keep working.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a quoted decimal string and reads
+// its text exactly.
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// Value stores the amount as a decimal string for numeric columns.
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Scan reads numeric columns (and, for rows not migrated yet, floats).
+func (a *Amount) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*a = 0
+	case []byte:
+		return a.scanText(string(v))
+	case string:
+		return a.scanText(v)
+	case int64:
+		*a = Amount(v * Scale)
+	case float64:
+		*a = FromFloat(v)
+	default:
+		return fmt.Errorf("money: cannot scan %T", src)
+	}
+	return nil
+}
+
+// scanText reads a numeric value. Aggregates such as AVG can carry more
+// decimals than an amount; they are rounded rather than rejected.
+func (a *Amount) scanText(s string) error {
+	v, err := Parse(s)
+	if errors.Is(err, ErrPrecision) {
+		r, ok := new(big.Rat).SetString(s)
+		if !ok {
+			return ErrInvalid
+		}
+		v, err = roundRat(r.Mul(r, big.NewRat(Scale, 1))), nil
+	}
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// GormDataType makes gorm create Amount columns as exact numerics.
+func (Amount) GormDataType() string {
+	return "numeric(16,2)"
+}
@@ -0,0 +1,165 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	maxUnits := strconv.FormatInt(math.MaxInt64/Scale-1, 10)
+	tests := []struct {
+		in      string
+		want    Amount
+		wantErr error
+	}{
+		{"1250", 125000, nil},
+		{"1250.5", 125050, nil},
+		{"1250.50", 125050, nil},
+		{"0.01", 1, nil},
+		{".25", 25, nil},
+		{"7.", 700, nil},
+		{"  42.10 ", 4210, nil},
+		{"+3.5", 350, nil},
+		{"0", 0, nil},
+		// Trailing zeros past the second decimal carry no value.
+		{"12.3400", 1234, nil},
+
+		{"-0.25", -25, nil},
+		{"-1250.5", -125050, nil},
+
+		{"0.001", 0, ErrPrecision},
+		{"12.345", 0, ErrPrecision},
+		{"-1.999", 0, ErrPrecision},
+
+		{"", 0, ErrInvalid},
+		{".", 0, ErrInvalid},
+		{"-", 0, ErrInvalid},
+		{"abc", 0, ErrInvalid},
+		{"1e5", 0, ErrInvalid},
+		{"1,000", 0, ErrInvalid},
+		{"--1", 0, ErrInvalid},
+		{"1.2.3", 0, ErrInvalid},
+		{"12.-5", 0, ErrInvalid},
+
+		{maxUnits + ".99", Amount((math.MaxInt64/Scale-1)*Scale + 99), nil},
+		{strconv.FormatInt(math.MaxInt64/Scale, 10), 0, ErrOverflow},
+		{"99999999999999999999", 0, ErrOverflow},
+		{"-99999999999999999999.99", 0, ErrOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Parse(%q) = %v, %v; want error %v", tt.in, got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("Parse(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		in   Amount
+		want string
+	}{
+		{0, "0.00"},
+		{1, "0.01"},
+		{125050, "1250.50"},
+		{-25, "-0.25"},
+		{-125000, "-1250.00"},
+	}
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("Amount(%d).String() = %q, want %q", int64(tt.in), got, tt.want)
+		}
+	}
+}
+
+func TestPercentRounding(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		p      float64
+		want   Amount
+	}{
+		{10000, 2.5, 250},
+		// 2.5% of 0.99 is 0.02475: rounded to the nearest minor unit.
+		{99, 2.5, 2},
+		// 50% of 0.01 is exactly half a minor unit: rounded away from zero.
+		{1, 50, 1},
+		{-1, 50, -1},
+		{33333, 100, 33333},
+	}
+	for _, tt := range tests {
+		if got := tt.amount.Percent(tt.p); got != tt.want {
+			t.Errorf("Amount(%d).Percent(%v) = %d, want %d", int64(tt.amount), tt.p, int64(got), int64(tt.want))
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	type payload struct {
+		Amount Amount  `json:"amount"`
+		Fee    *Amount `json:"fee,omitempty"`
+	}
+	fee := Amount(-5)
+	for _, in := range []payload{
+		{Amount: 0},
+		{Amount: 1},
+		{Amount: 125050},
+		{Amount: Amount(math.MaxInt64/Scale-1) * Scale, Fee: &fee},
+	} {
+		b, err := json.Marshal(in)
+		if err != nil {
+			t.Fatalf("Marshal(%v) = %v", in.Amount, err)
+		}
+		var out payload
+		if err := json.Unmarshal(b, &out); err != nil {
+			t.Fatalf("Unmarshal(%s) = %v", b, err)
+		}
+		if out.Amount != in.Amount || (in.Fee != nil) != (out.Fee != nil) || (in.Fee != nil && *out.Fee != *in.Fee) {
+			t.Errorf("round trip of %s = %+v, want %+v", b, out, in)
+		}
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Amount
+		wantErr error
+	}{
+		{`1250.5`, 125050, nil},
+		{`"1250.50"`, 125050, nil},
+		{`0.1`, 10, nil},
+		{`"-0.25"`, -25, nil},
+		{`null`, 0, nil},
+		{`12.345`, 0, ErrPrecision},
+		{`"12.345"`, 0, ErrPrecision},
+		{`1e3`, 0, ErrInvalid},
+		{`"abc"`, 0, ErrInvalid},
+		{`"99999999999999999999"`, 0, ErrOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var got Amount
+			err := json.Unmarshal([]byte(tt.in), &got)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Unmarshal(%s) = %v, %v; want error %v", tt.in, got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("Unmarshal(%s) = %v, %v; want %v", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+// Package outbox implements the transactional outbox for domain events.
+// Services write events with Enqueue in the same DB transaction as the
+// state change they announce, so an event exists if and only if the change
+// was committed. A Relay then publishes them to RabbitMQ in the order they
+// were enqueued, retrying until the broker accepts them. Delivery is at
+// least once: consumers must tolerate duplicates.
+package outbox
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/streadway/amqp"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Exchange is the topic exchange every SafeDeal event is published to.
+const Exchange = "safe_deal_exchange"
+
+// Message is an event waiting to be published, or already published when
+// SentAt is set.
+type Message struct {
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"not null"`
+	// RoutingKey is the event type, e.g. "escrow.create".
+	RoutingKey    string     `gorm:"type:varchar(64);not null"`
+	Payload       []byte     `gorm:"not null"`
+	Attempts      int        `gorm:"not null;default:0"`
+	LastError     string     `gorm:"type:text"`
+	NextAttemptAt time.Time  `gorm:"not null;index:idx_outbox_pending,priority:2"`
+	SentAt        *time.Time `gorm:"index:idx_outbox_pending,priority:1"`
+}
+
+func (Message) TableName() string {
+	return "outbox_messages"
+}
+
+// Event is any broker event; they all serialize themselves.
+type Event interface {
+	ToJSON() ([]byte, error)
+}
+
+// Enqueue adds event to the outbox. tx should be the transaction that
+// makes the change the event announces.
+func Enqueue(tx *gorm.DB, routingKey string, event Event) error {
+	payload, err := event.ToJSON()
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %v", routingKey, err)
+	}
+	now := time.Now()
+	return tx.Create(&Message{
+		CreatedAt:     now,
+		RoutingKey:    routingKey,
+		Payload:       payload,
+		NextAttemptAt: now,
+	}).Error
+}
+
+const (
+	batchSize      = 100
+	pollInterval   = time.Second
+	confirmTimeout = 10 * time.Second
+	maxBackoff     = 5 * time.Minute
+	// retention is how long published messages are kept for inspection.
+	retention = 7 * 24 * time.Hour
+)
+
+// Relay publishes outbox messages in order: a message that cannot be
+// published holds back every message after it, so a consumer never sees an
+// escrow's events out of order. Every replica of a service can run one;
+// they take turns, as each locks the head of the outbox while publishing.
+type Relay struct {
+	db  *gorm.DB
+	url string
+	// send publishes a message; it is r.publish outside of tests.
+	send func(m *Message) error
+
+	conn     *amqp.Connection
+	ch       *amqp.Channel
+	confirms chan amqp.Confirmation
+}
+
+// NewRelay returns a relay publishing the outbox of db to the broker at url.
+func NewRelay(db *gorm.DB, url string) *Relay {
+	r := &Relay{db: db, url: url}
+	r.send = r.publish
+	return r
+}
+
+// Start publishes pending messages until the process exits.
+func (r *Relay) Start() {
+	log.Printf("Outbox relay publishing to %s", Exchange)
+	poll := time.NewTicker(pollInterval)
+	defer poll.Stop()
+	purge := time.NewTicker(time.Hour)
+	defer purge.Stop()
+
+	for {
+		select {
+		case <-poll.C:
+			// Drain the backlog before waiting for the next tick.
+			for {
+				n, err := r.runOnce(time.Now())
+				if err != nil {
+					log.Printf("Outbox relay: %v", err)
+				}
+				if err != nil || n < batchSize {
+					break
+				}
+			}
+		case <-purge.C:
+			r.purge(time.Now())
+		}
+	}
+}
+
+// runOnce publishes the next batch of messages and returns how many it
+// handled. It stops at the first message that is waiting to be retried or
+// fails to publish.
+func (r *Relay) runOnce(now time.Time) (int, error) {
+	handled := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var messages []Message
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("sent_at IS NULL").
+			Order("id").
+			Limit(batchSize).
+			Find(&messages).Error
+		if err != nil {
+			return err
+		}
+
+		for i := range messages {
+			m := &messages[i]
+			if m.NextAttemptAt.After(now) {
+				break
+			}
+			handled++
+			if err := r.send(m); err != nil {
+				// Leave the broker connection to be re-established and
+				// retry this message later with a backoff.
+				r.close()
+				m.Attempts++
+				if err := tx.Model(m).Updates(map[string]any{
+					"attempts":        m.Attempts,
+					"last_error":      err.Error(),
+					"next_attempt_at": now.Add(backoff(m.Attempts)),
+				}).Error; err != nil {
+					return err
+				}
+				log.Printf("Outbox relay: failed to publish %s message %d (attempt %d): %v",
+					m.RoutingKey, m.ID, m.Attempts, err)
+				// The messages after it wait until it went out.
+				return nil
+			}
+			if err := tx.Model(m).Update("sent_at", time.Now()).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return handled, err
+}
+
+// backoff doubles the delay with every failed attempt, starting at one
+// second.
+func backoff(attempts int) time.Duration {
+	if attempts > 16 {
+		return maxBackoff
+	}
+	return min(time.Second<<(attempts-1), maxBackoff)
+}
+
+// publish sends one message and waits for the broker to confirm it.
+func (r *Relay) publish(m *Message) error {
+	if err := r.connect(); err != nil {
+		return err
+	}
+	err := r.ch.Publish(Exchange, m.RoutingKey, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		MessageId:    fmt.Sprintf("outbox-%d", m.ID),
+		Timestamp:    m.CreatedAt,
+		Body:         m.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case confirm, ok := <-r.confirms:
+		if !ok {
+			return errors.New("broker channel closed")
+		}
+		if !confirm.Ack {
+			return errors.New("broker rejected the message")
+		}
+		return nil
+	case <-time.After(confirmTimeout):
+		return errors.New("timed out waiting for broker confirmation")
+	}
+}
+
+// connect opens a confirming channel and declares the exchange, unless the
+// relay is already connected.
+func (r *Relay) connect() error {
+	if r.ch != nil {
+		return nil
+	}
+	conn, err := amqp.Dial(r.url)
+	if err != nil {
+		return fmt.Errorf("failed to connect to RabbitMQ: %v", err)
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to open a channel: %v", err)
+	}
+	if err := ch.ExchangeDeclare(Exchange, "topic", true, false, false, false, nil); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to declare exchange: %v", err)
+	}
+	if err := ch.Confirm(false); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to enable publisher confirms: %v", err)
+	}
+	r.conn, r.ch = conn, ch
+	r.confirms = ch.NotifyPublish(make(chan amqp.Confirmation, 1))
+	return nil
+}
+
+func (r *Relay) close() {
+	if r.conn != nil {
+		r.conn.Close()
+	}
+	r.conn, r.ch, r.confirms = nil, nil, nil
+}
+
+// purge deletes messages published longer ago than the retention period.
+func (r *Relay) purge(now time.Time) {
+	err := r.db.Where("sent_at < ?", now.Add(-retention)).Delete(&Message{}).Error
+	if err != nil {
+		log.Printf("Outbox relay: failed to purge sent messages: %v", err)
+	}
+}
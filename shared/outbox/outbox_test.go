@@ -0,0 +1,78 @@
+package outbox
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type testEvent string
+
+func (e testEvent) ToJSON() ([]byte, error) {
+	return []byte(`"` + e + `"`), nil
+}
+
+func TestRelayKeepsOrderAfterFailure(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&Message{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	for _, e := range []testEvent{"created", "funded", "released"} {
+		if err := Enqueue(db, "escrow."+string(e), e); err != nil {
+			t.Fatalf("Enqueue() = %v", err)
+		}
+	}
+
+	var published []string
+	failing := "escrow.funded"
+	r := NewRelay(db, "")
+	r.send = func(m *Message) error {
+		if m.RoutingKey == failing {
+			return errors.New("broker unavailable")
+		}
+		published = append(published, m.RoutingKey)
+		return nil
+	}
+
+	now := time.Now()
+	if _, err := r.runOnce(now); err != nil {
+		t.Fatalf("runOnce() = %v", err)
+	}
+	if len(published) != 1 || published[0] != "escrow.created" {
+		t.Fatalf("published %v, want only the message before the failure", published)
+	}
+
+	// The failed message waits for its backoff, and so does the one after.
+	failing = ""
+	if n, err := r.runOnce(now); err != nil || n != 0 {
+		t.Fatalf("runOnce() during the backoff = %d, %v; want nothing handled", n, err)
+	}
+
+	if _, err := r.runOnce(now.Add(maxBackoff)); err != nil {
+		t.Fatalf("runOnce() = %v", err)
+	}
+	want := []string{"escrow.created", "escrow.funded", "escrow.released"}
+	if len(published) != len(want) {
+		t.Fatalf("published %v, want %v", published, want)
+	}
+	for i := range want {
+		if published[i] != want[i] {
+			t.Fatalf("published %v, want %v", published, want)
+		}
+	}
+
+	var m Message
+	db.Where("routing_key = ?", "escrow.funded").First(&m)
+	if m.Attempts != 1 || m.SentAt == nil {
+		t.Errorf("retried message = %d attempts, sent %v; want 1 attempt and sent", m.Attempts, m.SentAt)
+	}
+}